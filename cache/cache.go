@@ -0,0 +1,58 @@
+package cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// KeyPrefix namespaces every cache entry so flushing the cache never
+// touches the auth tokens that share the same Redis database.
+const KeyPrefix = "cache:"
+
+// Flush deletes every key matching pattern (KeyPrefix + "*" when empty)
+// and returns how many keys were removed. Keys are found with SCAN so a
+// large cache does not block Redis, and every master is scanned when
+// running against a cluster.
+func Flush(ctx context.Context, rdb redis.UniversalClient, pattern string) (int64, error) {
+	if pattern == "" {
+		pattern = KeyPrefix + "*"
+	}
+
+	if cluster, ok := rdb.(*redis.ClusterClient); ok {
+		var total int64
+		var mu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			n, err := flushNode(ctx, node, pattern)
+			mu.Lock()
+			total += n
+			mu.Unlock()
+			return err
+		})
+		return total, err
+	}
+	return flushNode(ctx, rdb, pattern)
+}
+
+func flushNode(ctx context.Context, rdb redis.Cmdable, pattern string) (int64, error) {
+	var total int64
+	var cursor uint64
+	for {
+		keys, next, err := rdb.Scan(ctx, cursor, pattern, 500).Result()
+		if err != nil {
+			return total, err
+		}
+		if len(keys) > 0 {
+			n, err := rdb.Unlink(ctx, keys...).Result()
+			if err != nil {
+				return total, err
+			}
+			total += n
+		}
+		cursor = next
+		if cursor == 0 {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Namespaces group cached reads that are invalidated together.
+const (
+	MenuNamespace     = "menu"
+	CategoryNamespace = "categories"
+)
+
+// Redis calls made on behalf of a cached read get a short deadline of
+// their own, a slow Redis must not make the read slower than MySQL.
+const redisTimeout = 500 * time.Millisecond
+
+func versionKey(namespace string) string {
+	return KeyPrefix + namespace + ":version"
+}
+
+// Version returns the current version of a namespace, 0 when it was never
+// bumped.
+func Version(ctx context.Context, rdb redis.UniversalClient, namespace string) (int64, error) {
+	version, err := rdb.Get(ctx, versionKey(namespace)).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return version, err
+}
+
+// Invalidate bumps the version of a namespace. Keys of the old version
+// are never read again and expire on their own TTL, so invalidation is a
+// single INCR however many entries were cached.
+func Invalidate(ctx context.Context, rdb redis.UniversalClient, namespace string) error {
+	ctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+	return rdb.Incr(ctx, versionKey(namespace)).Err()
+}
+
+// ReadThrough returns the cached value of name in namespace, or calls
+// load and caches its result for ttl. The cache is fail-open: when Redis
+// is unavailable the value is loaded and returned without caching.
+//
+// The version is read before loading, so a write that lands while the
+// value is loaded bumps the version and the stale value is stored under
+// a key nobody reads any more.
+func ReadThrough[T any](ctx context.Context, rdb redis.UniversalClient, namespace, name string, ttl time.Duration, load func() (T, error)) (T, error) {
+	rctx, cancel := context.WithTimeout(ctx, redisTimeout)
+	defer cancel()
+
+	version, err := Version(rctx, rdb, namespace)
+	if err != nil {
+		log.Default().Println("Cache unavailable, reading " + namespace + " from the database: " + err.Error())
+		return load()
+	}
+	key := KeyPrefix + namespace + ":v" + strconv.FormatInt(version, 10) + ":" + name
+
+	cached, err := rdb.Get(rctx, key).Bytes()
+	if err == nil {
+		var value T
+		if err := json.Unmarshal(cached, &value); err == nil {
+			return value, nil
+		}
+	} else if !errors.Is(err, redis.Nil) {
+		log.Default().Println("Cache unavailable, reading " + namespace + " from the database: " + err.Error())
+		return load()
+	}
+
+	value, err := load()
+	if err != nil {
+		return value, err
+	}
+	if data, err := json.Marshal(value); err == nil {
+		wctx, cancel := context.WithTimeout(ctx, redisTimeout)
+		defer cancel()
+		if err := rdb.Set(wctx, key, data, ttl).Err(); err != nil {
+			log.Default().Println("Failed to cache " + key + ": " + err.Error())
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"coffee_shop/config"
+	"context"
+	"errors"
+
+	"coffee_shop/storage"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// App carries the configuration and the shared connections for a single
+// command run. Connections are opened on first use so commands such as
+// `migrate` do not need Redis to be reachable.
+type App struct {
+	Cfg *config.Config
+
+	db    *gorm.DB
+	rdb   redis.UniversalClient
+	store storage.BlobStore
+}
+
+func (a *App) DB() (*gorm.DB, error) {
+	if a.db == nil {
+		db, err := config.InitDB(a.Cfg.DB)
+		if err != nil {
+			return nil, errors.New("failed to connect to database: " + err.Error())
+		}
+		a.db = db
+	}
+	return a.db, nil
+}
+
+func (a *App) Redis() redis.UniversalClient {
+	if a.rdb == nil {
+		a.rdb = config.NewRedisClient(a.Cfg.Redis)
+	}
+	return a.rdb
+}
+
+func (a *App) Store() (storage.BlobStore, error) {
+	if a.store == nil {
+		store, err := storage.New(a.Cfg.Storage)
+		if err != nil {
+			return nil, err
+		}
+		a.store = store
+	}
+	return a.store, nil
+}
+
+func (a *App) load() error {
+	if a.Cfg != nil {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return errors.New("Error loading configuration: " + err.Error())
+	}
+	a.Cfg = cfg
+	return nil
+}
+
+// CloseDB closes the database connection if one was opened.
+func (a *App) CloseDB(ctx context.Context) error {
+	if a.db == nil {
+		return nil
+	}
+	sqlDB, err := a.db.DB()
+	a.db = nil
+	if err != nil {
+		return err
+	}
+	return sqlDB.Close()
+}
+
+// CloseRedis closes the Redis client if one was created.
+func (a *App) CloseRedis(ctx context.Context) error {
+	if a.rdb == nil {
+		return nil
+	}
+	err := a.rdb.Close()
+	a.rdb = nil
+	return err
+}
+
+// Close releases every connection opened by a command.
+func (a *App) Close(ctx context.Context) error {
+	return errors.Join(a.CloseDB(ctx), a.CloseRedis(ctx))
+}
+
+// Root returns the full command tree of the coffee_shop binary.
+func Root() *Command {
+	return &Command{
+		Name:  "coffee_shop",
+		Short: "Coffee Shop API server and administration tools",
+		Subcommands: []*Command{
+			serveCommand(),
+			migrateCommand(),
+			seedCommand(),
+			userCommand(),
+			menuCommand(),
+			imageCommand(),
+			cacheCommand(),
+			loyaltyCommand(),
+			reportCommand(),
+		},
+	}
+}
+
+// Execute runs the command named by args. With no arguments the server is
+// started, so existing deployments that run the bare binary keep working.
+func Execute(args []string) error {
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	app := &App{}
+	defer app.Close(context.Background())
+
+	return Root().execute(app, nil, args)
+}
@@ -0,0 +1,40 @@
+package cli
+
+import (
+	"coffee_shop/cache"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+)
+
+func cacheCommand() *Command {
+	var pattern string
+
+	return &Command{
+		Name:  "cache",
+		Short: "manage the Redis cache",
+		Subcommands: []*Command{
+			{
+				Name:  "flush",
+				Usage: "[--pattern PATTERN]",
+				Short: "delete cached entries, auth tokens are never touched",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&pattern, "pattern", cache.KeyPrefix+"*", "key pattern to delete, must start with "+cache.KeyPrefix)
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if !strings.HasPrefix(pattern, cache.KeyPrefix) {
+						return usageError(fs, "--pattern must start with "+cache.KeyPrefix)
+					}
+					n, err := cache.Flush(context.Background(), app.Redis(), pattern)
+					if err != nil {
+						return errors.New("failed to flush cache: " + err.Error())
+					}
+					fmt.Printf("%d cache entries deleted\n", n)
+					return nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrUsage is returned when a command is called with missing or invalid
+// arguments. The help text has already been printed when it is returned.
+var ErrUsage = errors.New("invalid usage")
+
+// Command is a node in the command tree. A command either has Subcommands
+// or a Run function. Flags are declared in SetFlags and parsed before Run.
+type Command struct {
+	Name        string
+	Usage       string
+	Short       string
+	Subcommands []*Command
+	SetFlags    func(fs *flag.FlagSet)
+	Run         func(app *App, fs *flag.FlagSet) error
+}
+
+func (c *Command) find(name string) *Command {
+	for _, sub := range c.Subcommands {
+		if sub.Name == name {
+			return sub
+		}
+	}
+	return nil
+}
+
+func (c *Command) execute(app *App, path []string, args []string) error {
+	path = append(path, c.Name)
+
+	if len(c.Subcommands) > 0 {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+			c.printHelp(os.Stdout, path)
+			if len(args) == 0 {
+				return ErrUsage
+			}
+			return nil
+		}
+		sub := c.find(args[0])
+		if sub == nil {
+			fmt.Fprintf(os.Stderr, "unknown command %q\n\n", strings.Join(append(path, args[0]), " "))
+			c.printHelp(os.Stderr, path)
+			return ErrUsage
+		}
+		return sub.execute(app, path, args[1:])
+	}
+
+	fs := flag.NewFlagSet(strings.Join(path, " "), flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: %s %s\n", strings.Join(path, " "), c.Usage)
+		if c.Short != "" {
+			fmt.Fprintf(fs.Output(), "\n%s\n", c.Short)
+		}
+		fmt.Fprintln(fs.Output())
+		fs.PrintDefaults()
+	}
+	if c.SetFlags != nil {
+		c.SetFlags(fs)
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return nil
+		}
+		return ErrUsage
+	}
+	if err := app.load(); err != nil {
+		return err
+	}
+	return c.Run(app, fs)
+}
+
+func (c *Command) printHelp(w io.Writer, path []string) {
+	fmt.Fprintf(w, "usage: %s <command> [flags]\n", strings.Join(path, " "))
+	if c.Short != "" {
+		fmt.Fprintf(w, "\n%s\n", c.Short)
+	}
+	fmt.Fprintln(w, "\ncommands:")
+	for _, sub := range c.Subcommands {
+		fmt.Fprintf(w, "  %-16s %s\n", sub.Name, sub.Short)
+	}
+}
+
+// usageError prints the usage of the running command and returns ErrUsage.
+func usageError(fs *flag.FlagSet, message string) error {
+	fmt.Fprintln(fs.Output(), message)
+	fs.Usage()
+	return ErrUsage
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"context"
+	"flag"
+	"fmt"
+	"time"
+)
+
+func imageCommand() *Command {
+	var grace time.Duration
+	var dryRun bool
+
+	return &Command{
+		Name:  "image",
+		Short: "manage stored menu images",
+		Subcommands: []*Command{
+			{
+				Name:  "gc",
+				Usage: "[--grace DURATION] [--dry-run]",
+				Short: "delete menu images that no menu refers to",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.DurationVar(&grace, "grace", -1, "keep images younger than this (default STORAGE_GC_GRACE_PERIOD)")
+					fs.BoolVar(&dryRun, "dry-run", false, "only list what would be deleted")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if grace < 0 {
+						grace = app.Cfg.Storage.GCGracePeriod
+					}
+					images, err := newImageService(app)
+					if err != nil {
+						return err
+					}
+
+					result, err := images.CollectGarbage(context.Background(), grace, dryRun)
+					if err != nil {
+						return err
+					}
+
+					verb := "deleted"
+					if dryRun {
+						verb = "would be deleted"
+					}
+					for _, key := range result.Deleted {
+						fmt.Println(key)
+					}
+					fmt.Printf("%d of %d files %s (%d bytes)\n", len(result.Deleted), result.Scanned, verb, result.Bytes)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func newImageService(app *App) (services.ImageService, error) {
+	db, err := app.DB()
+	if err != nil {
+		return nil, err
+	}
+	store, err := app.Store()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewImageService(repositories.NewMenuRepository(db), store, app.Cfg), nil
+}
@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"flag"
+	"fmt"
+	"time"
+)
+
+func loyaltyCommand() *Command {
+	return &Command{
+		Name:  "loyalty",
+		Short: "manage loyalty points",
+		Subcommands: []*Command{
+			{
+				Name:  "expire",
+				Short: "expire loyalty points past their expiry date",
+				Run: func(app *App, fs *flag.FlagSet) error {
+					loyalty, err := newLoyaltyService(app)
+					if err != nil {
+						return err
+					}
+
+					expired, err := loyalty.ExpirePoints(time.Now())
+					if err != nil {
+						return err
+					}
+					fmt.Printf("%d points expired\n", expired)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+func newLoyaltyService(app *App) (services.LoyaltyService, error) {
+	db, err := app.DB()
+	if err != nil {
+		return nil, err
+	}
+	return services.NewLoyaltyService(repositories.NewLoyaltyRepository(db), repositories.NewUserRepository(db), repositories.NewMenuRepository(db), app.Cfg.Loyalty), nil
+}
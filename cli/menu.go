@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/services"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func menuCommand() *Command {
+	var commit bool
+	var format string
+
+	return &Command{
+		Name:  "menu",
+		Short: "bulk import and export of menu items",
+		Subcommands: []*Command{
+			{
+				Name:  "import",
+				Usage: "[--commit] FILE.csv|FILE.json",
+				Short: "validate an import file and, with --commit, upsert its rows by menu name",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.BoolVar(&commit, "commit", false, "apply the file, without it the import is a dry-run")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if fs.NArg() != 1 {
+						return usageError(fs, "an import file is required")
+					}
+					path := fs.Arg(0)
+
+					file, err := os.Open(path)
+					if err != nil {
+						return err
+					}
+					defer file.Close()
+
+					var rows []dto.MenuTransferRow
+					switch strings.ToLower(filepath.Ext(path)) {
+					case ".csv":
+						rows, err = services.ParseMenuCSV(file)
+					case ".json":
+						rows, err = services.ParseMenuJSON(file)
+					default:
+						return usageError(fs, "the import file must end in .csv or .json")
+					}
+					if err != nil {
+						return err
+					}
+
+					mode := dto.MenuImportDryRun
+					if commit {
+						mode = dto.MenuImportCommit
+					}
+
+					db, err := app.DB()
+					if err != nil {
+						return err
+					}
+					result, err := services.NewMenuTransferService(db, app.Redis(), app.Cfg.Upload.MaxImportRows).Import(rows, mode)
+					if err != nil {
+						return err
+					}
+
+					for _, row := range result.Rows {
+						fmt.Printf("row %-4d %-7s %s", row.Row, row.Action, row.MenuName)
+						if len(row.Errors) > 0 {
+							fmt.Printf(": %s", strings.Join(row.Errors, "; "))
+						}
+						fmt.Println()
+					}
+					fmt.Printf("\n%d rows: %d to create, %d to update, %d invalid\n", result.Total, result.Created, result.Updated, result.Failed)
+
+					switch {
+					case result.Failed > 0:
+						return errors.New("import file has invalid rows, nothing was imported")
+					case result.Committed:
+						fmt.Println("imported")
+					default:
+						fmt.Println("dry-run, run again with --commit to import")
+					}
+					return nil
+				},
+			},
+			{
+				Name:  "export",
+				Usage: "[--format csv|json]",
+				Short: "write every menu item with its category name to stdout",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&format, "format", "csv", "csv or json")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					db, err := app.DB()
+					if err != nil {
+						return err
+					}
+					service := services.NewMenuTransferService(db, nil, app.Cfg.Upload.MaxImportRows)
+
+					switch format {
+					case "csv":
+						return service.ExportCSV(os.Stdout)
+					case "json":
+						export, err := service.Export()
+						if err != nil {
+							return err
+						}
+						enc := json.NewEncoder(os.Stdout)
+						enc.SetIndent("", "  ")
+						return enc.Encode(export)
+					default:
+						return usageError(fs, "--format must be csv or json")
+					}
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"coffee_shop/db/migrations"
+	"coffee_shop/models"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+)
+
+// migrateCommand runs the migrations embedded in the binary, so
+// deployments no longer need the golang-migrate CLI.
+func migrateCommand() *Command {
+	return &Command{
+		Name:  "migrate",
+		Short: "apply, roll back or inspect the embedded schema migrations",
+		Subcommands: []*Command{
+			{
+				Name:  "up",
+				Short: "apply every pending migration",
+				Run: func(app *App, fs *flag.FlagSet) error {
+					return withMigrator(app, migrateUp)
+				},
+			},
+			{
+				Name:  "down",
+				Usage: "[N]",
+				Short: "roll back N migrations (default 1)",
+				Run: func(app *App, fs *flag.FlagSet) error {
+					steps := 1
+					if fs.NArg() > 0 {
+						n, err := strconv.Atoi(fs.Arg(0))
+						if err != nil {
+							return usageError(fs, "invalid number of steps: "+fs.Arg(0))
+						}
+						steps = n
+					}
+					return withMigrator(app, func(app *App, migrator *migrations.Migrator) error {
+						if err := migrator.Down(steps); err != nil {
+							return errors.New("failed to roll back migrations: " + err.Error())
+						}
+						return printMigrateStatus(app, migrator)
+					})
+				},
+			},
+			{
+				Name:  "status",
+				Short: "show the applied version, pending migrations and schema drift",
+				Run: func(app *App, fs *flag.FlagSet) error {
+					return withMigrator(app, printMigrateStatus)
+				},
+			},
+			{
+				Name:  "force",
+				Usage: "V",
+				Short: "set the version to V without running migrations",
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if fs.NArg() != 1 {
+						return usageError(fs, "a version is required")
+					}
+					version, err := strconv.Atoi(fs.Arg(0))
+					if err != nil {
+						return usageError(fs, "invalid version: "+fs.Arg(0))
+					}
+					return withMigrator(app, func(app *App, migrator *migrations.Migrator) error {
+						if err := migrator.Force(version); err != nil {
+							return errors.New("failed to force version: " + err.Error())
+						}
+						return printMigrateStatus(app, migrator)
+					})
+				},
+			},
+		},
+	}
+}
+
+func withMigrator(app *App, fn func(app *App, migrator *migrations.Migrator) error) error {
+	migrator, err := migrations.NewMigrator(app.Cfg.DB)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+	return fn(app, migrator)
+}
+
+func migrateUp(app *App, migrator *migrations.Migrator) error {
+	if err := migrator.Up(); err != nil {
+		return errors.New("failed to apply migrations: " + err.Error())
+	}
+	return printMigrateStatus(app, migrator)
+}
+
+func printMigrateStatus(app *App, migrator *migrations.Migrator) error {
+	status, err := migrator.Status()
+	if err != nil {
+		return errors.New("failed to read migration status: " + err.Error())
+	}
+
+	fmt.Printf("version: %d (latest %d)\n", status.Version, status.Latest)
+	if status.Dirty {
+		fmt.Println("dirty:   yes, fix the failed migration and run `migrate force`")
+	}
+	fmt.Printf("pending: %v\n", status.Pending)
+
+	db, err := app.DB()
+	if err != nil {
+		return err
+	}
+	drift, err := migrations.CheckDrift(db, models.All()...)
+	if err != nil {
+		return errors.New("failed to check schema drift: " + err.Error())
+	}
+	if len(drift) == 0 {
+		fmt.Println("drift:   none")
+		return nil
+	}
+	fmt.Println("drift:")
+	for _, line := range drift {
+		fmt.Println("  " + line)
+	}
+	return nil
+}
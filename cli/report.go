@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+)
+
+func reportCommand() *Command {
+	var date string
+	var asJSON bool
+
+	return &Command{
+		Name:  "report",
+		Short: "print sales reports",
+		Subcommands: []*Command{
+			{
+				Name:  "daily",
+				Usage: "[--date YYYY-MM-DD] [--json]",
+				Short: "order count, revenue and top items for one day (default today)",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&date, "date", "", "day to report on, in server local time")
+					fs.BoolVar(&asJSON, "json", false, "print the report as JSON")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					day := time.Now()
+					if date != "" {
+						parsed, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+						if err != nil {
+							return usageError(fs, "invalid --date: "+err.Error())
+						}
+						day = parsed
+					}
+
+					db, err := app.DB()
+					if err != nil {
+						return err
+					}
+					report, err := services.NewReportService(repositories.NewOrderRepository(db)).DailyReport(day)
+					if err != nil {
+						return err
+					}
+
+					if asJSON {
+						enc := json.NewEncoder(os.Stdout)
+						enc.SetIndent("", "  ")
+						return enc.Encode(report)
+					}
+
+					fmt.Printf("Daily report %s\n\n", report.Date)
+					fmt.Printf("orders:        %d\n", report.TotalOrders)
+					for _, status := range slices.Sorted(maps.Keys(report.OrdersByStatus)) {
+						fmt.Printf("  %-11s %d\n", status, report.OrdersByStatus[status])
+					}
+					fmt.Printf("revenue:       %.2f\n", report.Revenue)
+					fmt.Printf("refunds:       %.2f\n", report.Refunds)
+					fmt.Printf("net revenue:   %.2f\n", report.NetRevenue)
+					fmt.Printf("average order: %.2f\n", report.AverageOrder)
+					fmt.Println("\ntop items:")
+					for _, item := range report.TopItems {
+						fmt.Printf("  %-30s %5d  %12.2f\n", item.MenuName, item.Quantity, item.Revenue)
+					}
+					return nil
+				},
+			},
+		},
+	}
+}
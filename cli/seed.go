@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"coffee_shop/db/seeds"
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+)
+
+func seedCommand() *Command {
+	var profile, dir string
+	var force bool
+
+	return &Command{
+		Name:  "seed",
+		Usage: "[--profile dev|demo|test] [--dir DIR] [--force]",
+		Short: "load fixture data, records that already exist are skipped",
+		SetFlags: func(fs *flag.FlagSet) {
+			fs.StringVar(&profile, "profile", "dev", "fixture profile to load")
+			fs.StringVar(&dir, "dir", "", "read <profile>.yaml or <profile>.json from DIR instead of the built-in fixtures")
+			fs.BoolVar(&force, "force", false, "allow seeding when APP_ENV is production")
+		},
+		Run: func(app *App, fs *flag.FlagSet) error {
+			if dir == "" && !slices.Contains(seeds.Profiles, profile) {
+				return usageError(fs, fmt.Sprintf("unknown profile %q, expected one of %v", profile, seeds.Profiles))
+			}
+			if app.Cfg.App.IsProduction() && !force {
+				return errors.New("refusing to seed a production environment without --force")
+			}
+
+			fixture, err := seeds.Load(profile, dir)
+			if err != nil {
+				return err
+			}
+
+			db, err := app.DB()
+			if err != nil {
+				return err
+			}
+			store, err := app.Store()
+			if err != nil {
+				return err
+			}
+			result, err := seeds.NewSeeder(db, app.Cfg, app.Redis(), store).Run(fixture)
+			for _, kind := range []string{"categories", "menus", "users", "orders"} {
+				fmt.Printf("%-11s %d created, %d already present\n", kind+":", result.Created[kind], result.Skipped[kind])
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"coffee_shop/lifecycle"
+	"coffee_shop/routes"
+	"coffee_shop/services"
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+func serveCommand() *Command {
+	return &Command{
+		Name:  "serve",
+		Short: "start the HTTP API server",
+		Run:   runServe,
+	}
+}
+
+func runServe(app *App, fs *flag.FlagSet) error {
+	cfg := app.Cfg
+
+	if cfg.DB.MigrateOnStart {
+		if err := withMigrator(app, migrateUp); err != nil {
+			return err
+		}
+	}
+
+	lc := lifecycle.New()
+
+	rdb := app.Redis()
+	lc.OnShutdown("redis", app.CloseRedis)
+
+	db, err := app.DB()
+	if err != nil {
+		return err
+	}
+	lc.OnShutdown("database", app.CloseDB)
+
+	store, err := app.Store()
+	if err != nil {
+		return err
+	}
+
+	e := echo.New()
+
+	//Middleware
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: cfg.CORS.AllowOrigins,
+	}))
+	e.Use(middleware.Logger())
+	e.Use(middleware.Recover())
+
+	// ROUTES
+	routes.ApiRoutes(e, cfg, db, rdb, store, lc)
+
+	if cfg.Storage.GCInterval > 0 {
+		images, err := newImageService(app)
+		if err != nil {
+			return err
+		}
+		lc.Go("image-gc", func(ctx context.Context) {
+			runImageGC(ctx, images, cfg.Storage.GCInterval, cfg.Storage.GCGracePeriod)
+		})
+	}
+
+	if cfg.Loyalty.ExpiryInterval > 0 {
+		loyalty, err := newLoyaltyService(app)
+		if err != nil {
+			return err
+		}
+		lc.Go("loyalty-expiry", func(ctx context.Context) {
+			runLoyaltyExpiry(ctx, loyalty, cfg.Loyalty.ExpiryInterval)
+		})
+	}
+
+	// Start server
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	// A server that fails to start (port in use, bad address) still closes
+	// the resources in order, then its error is returned so the process
+	// exits non-zero.
+	startErr := make(chan error, 1)
+	go func() {
+		if err := e.Start(cfg.App.Address()); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			startErr <- err
+		}
+	}()
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+	case err := <-startErr:
+		serveErr = errors.New("server stopped: " + err.Error())
+	}
+	stop()
+
+	// Take the instance out of rotation, then drain in-flight requests
+	log.Default().Println("Shutting down, draining in-flight requests")
+	lc.StartDraining()
+	if serveErr == nil {
+		time.Sleep(cfg.App.DrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.App.ShutdownTimeout)
+	defer cancel()
+
+	if err := e.Shutdown(shutdownCtx); err != nil {
+		log.Default().Println("Failed to drain HTTP server: " + err.Error())
+	}
+	if err := lc.Shutdown(shutdownCtx); err != nil {
+		log.Default().Println("Shutdown finished with errors: " + err.Error())
+	}
+	log.Default().Println("Server stopped")
+	return serveErr
+}
+
+// runImageGC collects unreferenced images every interval until ctx is
+// cancelled. Running it on several instances at once is harmless.
+func runImageGC(ctx context.Context, images services.ImageService, interval, grace time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			result, err := images.CollectGarbage(ctx, grace, false)
+			if err != nil {
+				log.Default().Println("Image GC failed: " + err.Error())
+				continue
+			}
+			if len(result.Deleted) > 0 {
+				log.Default().Printf("Image GC deleted %d files (%d bytes)", len(result.Deleted), result.Bytes)
+			}
+		}
+	}
+}
+
+// runLoyaltyExpiry expires loyalty points every interval until ctx is
+// stopped. ExpirePoints locks each user's row (lockUser) and re-reads the
+// entries under it, so instances ticking together never expire the same
+// points twice.
+func runLoyaltyExpiry(ctx context.Context, loyalty services.LoyaltyService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := loyalty.ExpirePoints(now)
+			if err != nil {
+				log.Default().Println("Loyalty expiry failed: " + err.Error())
+				continue
+			}
+			if expired > 0 {
+				log.Default().Printf("Loyalty expiry expired %d points", expired)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bufio"
+	"coffee_shop/models"
+	"coffee_shop/services"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+func userCommand() *Command {
+	var name, email, password, phone string
+
+	return &Command{
+		Name:  "user",
+		Short: "manage user accounts",
+		Subcommands: []*Command{
+			{
+				Name:  "create-admin",
+				Usage: "--email EMAIL --name NAME [--phone PHONE] [--password PASSWORD]",
+				Short: "create an admin account, the password is read from stdin when not given",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&name, "name", "", "display name")
+					fs.StringVar(&email, "email", "", "login email")
+					fs.StringVar(&phone, "phone", "", "phone number")
+					fs.StringVar(&password, "password", "", "password (prefer stdin)")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if name == "" || email == "" {
+						return usageError(fs, "--name and --email are required")
+					}
+					password, err := readPassword(password)
+					if err != nil {
+						return err
+					}
+
+					db, err := app.DB()
+					if err != nil {
+						return err
+					}
+					result, err := services.NewUserService(db, app.Cfg).Register(models.User{
+						Name:        name,
+						Email:       email,
+						Password:    password,
+						Role:        "admin",
+						PhoneNumber: phone,
+					})
+					if err != nil {
+						return errors.New("failed to create admin: " + err.Error())
+					}
+					fmt.Printf("admin %s <%s> created\n", result.Name, result.Email)
+					return nil
+				},
+			},
+			{
+				Name:  "reset-password",
+				Usage: "--email EMAIL [--password PASSWORD]",
+				Short: "set a new password for a user, read from stdin when not given",
+				SetFlags: func(fs *flag.FlagSet) {
+					fs.StringVar(&email, "email", "", "login email")
+					fs.StringVar(&password, "password", "", "new password (prefer stdin)")
+				},
+				Run: func(app *App, fs *flag.FlagSet) error {
+					if email == "" {
+						return usageError(fs, "--email is required")
+					}
+					password, err := readPassword(password)
+					if err != nil {
+						return err
+					}
+
+					db, err := app.DB()
+					if err != nil {
+						return err
+					}
+					err = services.NewUserService(db, app.Cfg).ResetPassword(email, password)
+					if err != nil {
+						return err
+					}
+					fmt.Printf("password reset for %s\n", email)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// readPassword keeps passwords out of shell history by reading them from
+// stdin unless one was passed explicitly.
+func readPassword(password string) (string, error) {
+	if password != "" {
+		return password, nil
+	}
+	fmt.Fprint(os.Stderr, "password: ")
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("failed to read password: " + err.Error())
+	}
+	password = strings.TrimRight(line, "\r\n")
+	if password == "" {
+		return "", errors.New("password is required")
+	}
+	return password, nil
+}
@@ -0,0 +1,610 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	App     AppConfig     `yaml:"app"`
+	DB      DBConfig      `yaml:"db"`
+	Redis   RedisConfig   `yaml:"redis"`
+	Cache   CacheConfig   `yaml:"cache"`
+	JWT     JWTConfig     `yaml:"jwt"`
+	Upload  UploadConfig  `yaml:"upload"`
+	Storage StorageConfig `yaml:"storage"`
+	CORS    CORSConfig    `yaml:"cors"`
+	Loyalty LoyaltyConfig `yaml:"loyalty"`
+	Tax     TaxConfig     `yaml:"tax"`
+	Cart    CartConfig    `yaml:"cart"`
+	Receipt ReceiptConfig `yaml:"receipt"`
+	Shift   ShiftConfig   `yaml:"shift"`
+}
+
+type AppConfig struct {
+	// Env is the deployment environment, e.g. development, staging or production
+	Env  string `yaml:"env"`
+	Host string `yaml:"host"`
+	Port string `yaml:"port"`
+	// DrainDelay is how long readiness reports unhealthy before the server
+	// stops accepting connections, so load balancers can stop routing to it
+	DrainDelay      time.Duration `yaml:"drain_delay"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// Timezone is the IANA zone of the shop, used when a schedule names none
+	Timezone string `yaml:"timezone"`
+}
+
+type DBConfig struct {
+	Username        string        `yaml:"username"`
+	Password        string        `yaml:"password"`
+	Host            string        `yaml:"host"`
+	Port            string        `yaml:"port"`
+	Database        string        `yaml:"database"`
+	MaxOpenConns    int           `yaml:"max_open_conns"`
+	MaxIdleConns    int           `yaml:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time"`
+	Debug           bool          `yaml:"debug"`
+	// MigrateOnStart applies pending embedded migrations before serving
+	MigrateOnStart bool `yaml:"migrate_on_start"`
+}
+
+type RedisConfig struct {
+	// Mode is one of "single", "sentinel" or "cluster"
+	Mode             string        `yaml:"mode"`
+	Addr             string        `yaml:"addr"`
+	Addrs            []string      `yaml:"addrs"`
+	MasterName       string        `yaml:"master_name"`
+	Password         string        `yaml:"password"`
+	SentinelPassword string        `yaml:"sentinel_password"`
+	DB               int           `yaml:"db"`
+	PoolSize         int           `yaml:"pool_size"`
+	MinIdleConns     int           `yaml:"min_idle_conns"`
+	DialTimeout      time.Duration `yaml:"dial_timeout"`
+	ReadTimeout      time.Duration `yaml:"read_timeout"`
+	WriteTimeout     time.Duration `yaml:"write_timeout"`
+	PoolTimeout      time.Duration `yaml:"pool_timeout"`
+}
+
+type CacheConfig struct {
+	// TTL bounds how long a cached read lives, 0 disables the read cache
+	TTL time.Duration `yaml:"ttl"`
+}
+
+type JWTConfig struct {
+	SecretKey  string        `yaml:"secret_key"`
+	Issuer     string        `yaml:"issuer"`
+	AccessTTL  time.Duration `yaml:"access_ttl"`
+	RefreshTTL time.Duration `yaml:"refresh_ttl"`
+}
+
+type UploadConfig struct {
+	MaxImageSize int64    `yaml:"max_image_size"`
+	AllowedTypes []string `yaml:"allowed_types"`
+	// MaxImportSize caps the body of a menu import upload, in bytes
+	MaxImportSize int64 `yaml:"max_import_size"`
+	// MaxImportRows caps the rows of a menu import file
+	MaxImportRows int `yaml:"max_import_rows"`
+}
+
+type StorageConfig struct {
+	// Driver is "local" or "s3"
+	Driver string `yaml:"driver"`
+	// LocalDir is the root of the local store, keys such as menu_img/x.jpg live below it
+	LocalDir string `yaml:"local_dir"`
+	// PublicBaseURL prefixes keys of the local store to build image URLs
+	PublicBaseURL string `yaml:"public_base_url"`
+	// DefaultImage is the key shown for menu items without an image
+	DefaultImage string        `yaml:"default_image"`
+	S3Endpoint   string        `yaml:"s3_endpoint"`
+	S3Region     string        `yaml:"s3_region"`
+	S3Bucket     string        `yaml:"s3_bucket"`
+	S3AccessKey  string        `yaml:"s3_access_key"`
+	S3SecretKey  string        `yaml:"s3_secret_key"`
+	S3UseSSL     bool          `yaml:"s3_use_ssl"`
+	S3PathStyle  bool          `yaml:"s3_path_style"`
+	S3PublicURL  string        `yaml:"s3_public_url"`
+	SignedURLTTL time.Duration `yaml:"signed_url_ttl"`
+	// GCInterval is how often the server removes unreferenced images, 0 disables it
+	GCInterval time.Duration `yaml:"gc_interval"`
+	// GCGracePeriod protects blobs uploaded recently, the menu row may not be saved yet
+	GCGracePeriod time.Duration `yaml:"gc_grace_period"`
+}
+
+type CORSConfig struct {
+	AllowOrigins []string `yaml:"allow_origins"`
+}
+
+type LoyaltyConfig struct {
+	// PointsPerOrder is earned for every completed order, one stamp on the card
+	PointsPerOrder int `yaml:"points_per_order"`
+	// SpendPerPoint earns one more point per this much spent, 0 disables it
+	SpendPerPoint float64 `yaml:"spend_per_point"`
+	// PointExpiry is how long earned points stay valid, 0 keeps them forever
+	PointExpiry time.Duration `yaml:"point_expiry"`
+	// ExpiryInterval is how often the server expires points, 0 disables it
+	ExpiryInterval time.Duration `yaml:"expiry_interval"`
+}
+
+type TaxConfig struct {
+	// RoundingMode rounds the grand total "nearest", "up" or "down"
+	RoundingMode string `yaml:"rounding_mode"`
+	// RoundingIncrement is the smallest amount a grand total can end in, e.g. 0.05 or 100
+	RoundingIncrement float64 `yaml:"rounding_increment"`
+}
+
+type CartConfig struct {
+	// TTL is how long a cart is kept after its last change
+	TTL time.Duration `yaml:"ttl"`
+	// MaxLines and MaxQuantity bound a cart, one line is one menu item configuration
+	MaxLines    int `yaml:"max_lines"`
+	MaxQuantity int `yaml:"max_quantity"`
+}
+
+type ReceiptConfig struct {
+	// ShopName heads and Footer closes every printed receipt
+	ShopName string `yaml:"shop_name"`
+	Footer   string `yaml:"footer"`
+	// Width is the number of characters on a line of the receipt printer
+	Width int `yaml:"width"`
+}
+
+type ShiftConfig struct {
+	// DiscrepancyTolerance is how far counted cash may be off the expected
+	// cash before a Z-report flags the shift
+	DiscrepancyTolerance float64 `yaml:"discrepancy_tolerance"`
+}
+
+// Default returns the configuration used when nothing overrides a field.
+// The values match what the application hard-coded before config was centralised.
+func Default() Config {
+	return Config{
+		App: AppConfig{
+			Env:             "development",
+			Host:            "0.0.0.0",
+			Port:            "8080",
+			DrainDelay:      5 * time.Second,
+			ShutdownTimeout: 30 * time.Second,
+			Timezone:        "UTC",
+		},
+		DB: DBConfig{
+			Port:            "3306",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 60 * time.Minute,
+			ConnMaxIdleTime: 10 * time.Minute,
+			Debug:           true,
+		},
+		Redis: RedisConfig{
+			Mode:         RedisModeSingle,
+			Addr:         "0.0.0.0:6379",
+			PoolSize:     10,
+			MinIdleConns: 2,
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+			PoolTimeout:  4 * time.Second,
+		},
+		Cache: CacheConfig{
+			TTL: 10 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Issuer:     "coffee_shop_app",
+			AccessTTL:  15 * time.Minute,
+			RefreshTTL: 7 * 24 * time.Hour,
+		},
+		Upload: UploadConfig{
+			MaxImageSize:  2 << 20,
+			AllowedTypes:  []string{"image/jpeg", "image/jpg", "image/png"},
+			MaxImportSize: 5 << 20,
+			MaxImportRows: 5000,
+		},
+		Storage: StorageConfig{
+			Driver:        StorageDriverLocal,
+			LocalDir:      "./image",
+			PublicBaseURL: "/images",
+			DefaultImage:  "default_product.png",
+			S3UseSSL:      true,
+			SignedURLTTL:  time.Hour,
+			GCGracePeriod: 24 * time.Hour,
+		},
+		CORS: CORSConfig{
+			AllowOrigins: []string{"*"},
+		},
+		Loyalty: LoyaltyConfig{
+			PointsPerOrder: 1,
+			PointExpiry:    365 * 24 * time.Hour,
+			ExpiryInterval: time.Hour,
+		},
+		Tax: TaxConfig{
+			RoundingMode:      RoundingNearest,
+			RoundingIncrement: 0.01,
+		},
+		Cart: CartConfig{
+			TTL:         7 * 24 * time.Hour,
+			MaxLines:    50,
+			MaxQuantity: 99,
+		},
+		Receipt: ReceiptConfig{
+			ShopName: "Coffee Shop",
+			Footer:   "Thank you for your visit!",
+			Width:    32,
+		},
+	}
+}
+
+// Load builds the configuration once at startup. Values are layered:
+// defaults, then the YAML file named by CONFIG_FILE (if any), then the
+// .env file (if present), then the process environment.
+func Load() (*Config, error) {
+	cfg := Default()
+
+	// A missing .env is fine, the variables may come from the environment
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("failed to load .env file: " + err.Error())
+	}
+
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := loadYAML(path, &cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := loadEnv(&cfg); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func loadYAML(path string, cfg *Config) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return errors.New("failed to read config file: " + err.Error())
+	}
+	if err := yaml.Unmarshal(raw, cfg); err != nil {
+		return errors.New("failed to parse config file: " + err.Error())
+	}
+	return nil
+}
+
+func loadEnv(cfg *Config) error {
+	setString(&cfg.App.Env, "APP_ENV")
+	setString(&cfg.App.Timezone, "APP_TIMEZONE")
+	setString(&cfg.App.Host, "API_HOST")
+	setString(&cfg.App.Port, "API_PORT")
+
+	setString(&cfg.DB.Username, "DB_USERNAME")
+	setString(&cfg.DB.Password, "DB_PASSWORD")
+	setString(&cfg.DB.Host, "DB_HOST")
+	setString(&cfg.DB.Port, "DB_PORT")
+	setString(&cfg.DB.Database, "DB_DATABASE")
+
+	setString(&cfg.Redis.Mode, "REDIS_MODE")
+	setString(&cfg.Redis.Addr, "REDIS_ADDR")
+	setList(&cfg.Redis.Addrs, "REDIS_ADDRS")
+	setString(&cfg.Redis.MasterName, "REDIS_MASTER_NAME")
+	setString(&cfg.Redis.Password, "REDIS_PASSWORD")
+	setString(&cfg.Redis.SentinelPassword, "REDIS_SENTINEL_PASSWORD")
+
+	setString(&cfg.JWT.SecretKey, "JWT_SECRET_KEY")
+	setString(&cfg.JWT.Issuer, "JWT_ISSUER")
+
+	setList(&cfg.Upload.AllowedTypes, "UPLOAD_ALLOWED_TYPES")
+
+	setString(&cfg.Tax.RoundingMode, "TAX_ROUNDING_MODE")
+
+	setString(&cfg.Storage.Driver, "STORAGE_DRIVER")
+	setString(&cfg.Storage.LocalDir, "STORAGE_LOCAL_DIR")
+	setString(&cfg.Storage.PublicBaseURL, "STORAGE_PUBLIC_BASE_URL")
+	setString(&cfg.Storage.DefaultImage, "STORAGE_DEFAULT_IMAGE")
+	setString(&cfg.Storage.S3Endpoint, "STORAGE_S3_ENDPOINT")
+	setString(&cfg.Storage.S3Region, "STORAGE_S3_REGION")
+	setString(&cfg.Storage.S3Bucket, "STORAGE_S3_BUCKET")
+	setString(&cfg.Storage.S3AccessKey, "STORAGE_S3_ACCESS_KEY")
+	setString(&cfg.Storage.S3SecretKey, "STORAGE_S3_SECRET_KEY")
+	setString(&cfg.Storage.S3PublicURL, "STORAGE_S3_PUBLIC_URL")
+
+	setList(&cfg.CORS.AllowOrigins, "CORS_ALLOW_ORIGINS")
+
+	setString(&cfg.Receipt.ShopName, "RECEIPT_SHOP_NAME")
+	setString(&cfg.Receipt.Footer, "RECEIPT_FOOTER")
+
+	var errs []error
+	errs = append(errs,
+		setDuration(&cfg.App.DrainDelay, "APP_DRAIN_DELAY"),
+		setDuration(&cfg.App.ShutdownTimeout, "APP_SHUTDOWN_TIMEOUT"),
+		setInt(&cfg.DB.MaxOpenConns, "DB_MAX_OPEN_CONNS"),
+		setInt(&cfg.DB.MaxIdleConns, "DB_MAX_IDLE_CONNS"),
+		setDuration(&cfg.DB.ConnMaxLifetime, "DB_CONN_MAX_LIFETIME"),
+		setDuration(&cfg.DB.ConnMaxIdleTime, "DB_CONN_MAX_IDLE_TIME"),
+		setBool(&cfg.DB.Debug, "DB_DEBUG"),
+		setBool(&cfg.DB.MigrateOnStart, "DB_MIGRATE_ON_START"),
+		setInt(&cfg.Redis.DB, "REDIS_DB"),
+		setInt(&cfg.Redis.PoolSize, "REDIS_POOL_SIZE"),
+		setInt(&cfg.Redis.MinIdleConns, "REDIS_MIN_IDLE_CONNS"),
+		setDuration(&cfg.Redis.DialTimeout, "REDIS_DIAL_TIMEOUT"),
+		setDuration(&cfg.Redis.ReadTimeout, "REDIS_READ_TIMEOUT"),
+		setDuration(&cfg.Redis.WriteTimeout, "REDIS_WRITE_TIMEOUT"),
+		setDuration(&cfg.Redis.PoolTimeout, "REDIS_POOL_TIMEOUT"),
+		setDuration(&cfg.Cache.TTL, "CACHE_TTL"),
+		setDuration(&cfg.JWT.AccessTTL, "JWT_ACCESS_TTL"),
+		setDuration(&cfg.JWT.RefreshTTL, "JWT_REFRESH_TTL"),
+		setInt64(&cfg.Upload.MaxImageSize, "UPLOAD_MAX_IMAGE_SIZE"),
+		setInt64(&cfg.Upload.MaxImportSize, "UPLOAD_MAX_IMPORT_SIZE"),
+		setInt(&cfg.Upload.MaxImportRows, "UPLOAD_MAX_IMPORT_ROWS"),
+		setBool(&cfg.Storage.S3UseSSL, "STORAGE_S3_USE_SSL"),
+		setBool(&cfg.Storage.S3PathStyle, "STORAGE_S3_PATH_STYLE"),
+		setDuration(&cfg.Storage.SignedURLTTL, "STORAGE_SIGNED_URL_TTL"),
+		setDuration(&cfg.Storage.GCInterval, "STORAGE_GC_INTERVAL"),
+		setDuration(&cfg.Storage.GCGracePeriod, "STORAGE_GC_GRACE_PERIOD"),
+		setInt(&cfg.Loyalty.PointsPerOrder, "LOYALTY_POINTS_PER_ORDER"),
+		setFloat64(&cfg.Loyalty.SpendPerPoint, "LOYALTY_SPEND_PER_POINT"),
+		setDuration(&cfg.Loyalty.PointExpiry, "LOYALTY_POINT_EXPIRY"),
+		setDuration(&cfg.Loyalty.ExpiryInterval, "LOYALTY_EXPIRY_INTERVAL"),
+		setFloat64(&cfg.Tax.RoundingIncrement, "TAX_ROUNDING_INCREMENT"),
+		setDuration(&cfg.Cart.TTL, "CART_TTL"),
+		setInt(&cfg.Cart.MaxLines, "CART_MAX_LINES"),
+		setInt(&cfg.Cart.MaxQuantity, "CART_MAX_QUANTITY"),
+		setInt(&cfg.Receipt.Width, "RECEIPT_WIDTH"),
+		setFloat64(&cfg.Shift.DiscrepancyTolerance, "SHIFT_DISCREPANCY_TOLERANCE"),
+	)
+	return errors.Join(errs...)
+}
+
+// Validate reports every missing or out-of-range field at once so a bad
+// deployment fails at startup instead of on the first request.
+func (c *Config) Validate() error {
+	var errs []error
+	required := map[string]string{
+		"API_PORT":       c.App.Port,
+		"DB_USERNAME":    c.DB.Username,
+		"DB_HOST":        c.DB.Host,
+		"DB_PORT":        c.DB.Port,
+		"DB_DATABASE":    c.DB.Database,
+		"JWT_SECRET_KEY": c.JWT.SecretKey,
+	}
+	for _, key := range slices.Sorted(maps.Keys(required)) {
+		if required[key] == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	if c.App.DrainDelay < 0 || c.App.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("APP_DRAIN_DELAY must not be negative and APP_SHUTDOWN_TIMEOUT must be greater than 0"))
+	}
+	if _, err := time.LoadLocation(c.App.Timezone); err != nil {
+		errs = append(errs, errors.New("APP_TIMEZONE is invalid: "+err.Error()))
+	}
+	if c.DB.MaxOpenConns <= 0 {
+		errs = append(errs, errors.New("DB_MAX_OPEN_CONNS must be greater than 0"))
+	}
+	if c.DB.MaxIdleConns < 0 || c.DB.MaxIdleConns > c.DB.MaxOpenConns {
+		errs = append(errs, errors.New("DB_MAX_IDLE_CONNS must be between 0 and DB_MAX_OPEN_CONNS"))
+	}
+	if c.Redis.DB < 0 {
+		errs = append(errs, errors.New("REDIS_DB must not be negative"))
+	}
+	switch c.Redis.Mode {
+	case RedisModeSingle:
+		if c.Redis.Addr == "" {
+			errs = append(errs, errors.New("REDIS_ADDR is required"))
+		}
+	case RedisModeSentinel:
+		if len(c.Redis.Addrs) == 0 || c.Redis.MasterName == "" {
+			errs = append(errs, errors.New("REDIS_ADDRS and REDIS_MASTER_NAME are required in sentinel mode"))
+		}
+	case RedisModeCluster:
+		if len(c.Redis.Addrs) == 0 {
+			errs = append(errs, errors.New("REDIS_ADDRS is required in cluster mode"))
+		}
+		if c.Redis.DB != 0 {
+			errs = append(errs, errors.New("REDIS_DB must be 0 in cluster mode"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("REDIS_MODE must be one of %s, %s or %s", RedisModeSingle, RedisModeSentinel, RedisModeCluster))
+	}
+	if c.Redis.PoolSize <= 0 {
+		errs = append(errs, errors.New("REDIS_POOL_SIZE must be greater than 0"))
+	}
+	if c.Cache.TTL < 0 {
+		errs = append(errs, errors.New("CACHE_TTL must not be negative"))
+	}
+	if c.JWT.AccessTTL <= 0 || c.JWT.RefreshTTL <= 0 {
+		errs = append(errs, errors.New("JWT_ACCESS_TTL and JWT_REFRESH_TTL must be greater than 0"))
+	}
+	if c.JWT.RefreshTTL < c.JWT.AccessTTL {
+		errs = append(errs, errors.New("JWT_REFRESH_TTL must not be shorter than JWT_ACCESS_TTL"))
+	}
+	if c.Upload.MaxImageSize <= 0 {
+		errs = append(errs, errors.New("UPLOAD_MAX_IMAGE_SIZE must be greater than 0"))
+	}
+	if c.Upload.MaxImportSize <= 0 {
+		errs = append(errs, errors.New("UPLOAD_MAX_IMPORT_SIZE must be greater than 0"))
+	}
+	if c.Upload.MaxImportRows <= 0 {
+		errs = append(errs, errors.New("UPLOAD_MAX_IMPORT_ROWS must be greater than 0"))
+	}
+	switch c.Storage.Driver {
+	case StorageDriverLocal:
+		if c.Storage.LocalDir == "" {
+			errs = append(errs, errors.New("STORAGE_LOCAL_DIR is required for the local driver"))
+		}
+	case StorageDriverS3:
+		if c.Storage.S3Endpoint == "" || c.Storage.S3Bucket == "" || c.Storage.S3AccessKey == "" || c.Storage.S3SecretKey == "" {
+			errs = append(errs, errors.New("STORAGE_S3_ENDPOINT, STORAGE_S3_BUCKET, STORAGE_S3_ACCESS_KEY and STORAGE_S3_SECRET_KEY are required for the s3 driver"))
+		}
+		if c.Storage.S3PublicURL == "" && c.Storage.SignedURLTTL <= 0 {
+			errs = append(errs, errors.New("STORAGE_SIGNED_URL_TTL must be greater than 0 when STORAGE_S3_PUBLIC_URL is empty"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("STORAGE_DRIVER must be %s or %s", StorageDriverLocal, StorageDriverS3))
+	}
+	if c.Storage.GCInterval < 0 || c.Storage.GCGracePeriod < 0 {
+		errs = append(errs, errors.New("STORAGE_GC_INTERVAL and STORAGE_GC_GRACE_PERIOD must not be negative"))
+	}
+	if len(c.CORS.AllowOrigins) == 0 {
+		errs = append(errs, errors.New("CORS_ALLOW_ORIGINS must list at least one origin"))
+	}
+	if c.Loyalty.PointsPerOrder < 0 || c.Loyalty.SpendPerPoint < 0 || c.Loyalty.PointExpiry < 0 || c.Loyalty.ExpiryInterval < 0 {
+		errs = append(errs, errors.New("LOYALTY_POINTS_PER_ORDER, LOYALTY_SPEND_PER_POINT, LOYALTY_POINT_EXPIRY and LOYALTY_EXPIRY_INTERVAL must not be negative"))
+	}
+	if !slices.Contains([]string{RoundingNearest, RoundingUp, RoundingDown}, c.Tax.RoundingMode) {
+		errs = append(errs, fmt.Errorf("TAX_ROUNDING_MODE must be %s, %s or %s", RoundingNearest, RoundingUp, RoundingDown))
+	}
+	if c.Tax.RoundingIncrement <= 0 {
+		errs = append(errs, errors.New("TAX_ROUNDING_INCREMENT must be positive"))
+	}
+	if c.Cart.TTL <= 0 || c.Cart.MaxLines <= 0 || c.Cart.MaxQuantity <= 0 {
+		errs = append(errs, errors.New("CART_TTL, CART_MAX_LINES and CART_MAX_QUANTITY must be positive"))
+	}
+	if c.Receipt.Width < 24 || c.Receipt.Width > 80 {
+		errs = append(errs, errors.New("RECEIPT_WIDTH must be between 24 and 80"))
+	}
+	if c.Shift.DiscrepancyTolerance < 0 {
+		errs = append(errs, errors.New("SHIFT_DISCREPANCY_TOLERANCE cannot be negative"))
+	}
+
+	if len(errs) > 0 {
+		return errors.New("invalid configuration: " + errors.Join(errs...).Error())
+	}
+	return nil
+}
+
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+const (
+	RoundingNearest = "nearest"
+	RoundingUp      = "up"
+	RoundingDown    = "down"
+)
+
+// IsProduction reports whether the app runs in the production environment.
+func (a AppConfig) IsProduction() bool {
+	return a.Env == "production"
+}
+
+// Location returns the shop timezone, Validate has checked that it loads.
+func (a AppConfig) Location() *time.Location {
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// Address returns the host:port the HTTP server listens on.
+func (a AppConfig) Address() string {
+	return a.Host + ":" + a.Port
+}
+
+// DSN returns the MySQL data source name for GORM.
+func (d DBConfig) DSN() string {
+	return d.Username + ":" + d.Password + "@tcp(" + d.Host + ":" + d.Port + ")/" + d.Database + "?charset=utf8mb4&parseTime=True&loc=Local"
+}
+
+// MigrateDSN returns the data source name for golang-migrate, which needs
+// multiStatements so a migration file may hold more than one statement.
+func (d DBConfig) MigrateDSN() string {
+	return d.Username + ":" + d.Password + "@tcp(" + d.Host + ":" + d.Port + ")/" + d.Database + "?multiStatements=true"
+}
+
+func setString(dst *string, key string) {
+	if v, ok := os.LookupEnv(key); ok {
+		*dst = v
+	}
+}
+
+func setList(dst *[]string, key string) {
+	v, ok := os.LookupEnv(key)
+	if !ok {
+		return
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	*dst = list
+}
+
+func setInt(dst *int, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setInt64(dst *int64, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be an integer: %w", key, err)
+	}
+	*dst = n
+	return nil
+}
+
+func setFloat64(dst *float64, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		return fmt.Errorf("%s must be a number: %w", key, err)
+	}
+	*dst = f
+	return nil
+}
+
+func setBool(dst *bool, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a boolean: %w", key, err)
+	}
+	*dst = b
+	return nil
+}
+
+func setDuration(dst *time.Duration, key string) error {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return fmt.Errorf("%s must be a duration such as 15m or 168h: %w", key, err)
+	}
+	*dst = d
+	return nil
+}
@@ -1,15 +1,76 @@
 package config
 
 import (
+	"context"
+	"log"
+	"time"
+
 	"github.com/redis/go-redis/v9"
 )
 
-func RedisClient() (*redis.Client, error) {
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     "0.0.0.0:6379",
-		Password: "", // no password set
-		DB:       0,  // use default DB
-	})
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
+// NewRedisClient creates the single long-lived Redis client shared by the
+// whole application. It is safe for concurrent use and owns its own
+// connection pool, so callers must not create clients per request.
+//
+// A failed ping at startup is only logged: the process keeps running and
+// each caller degrades according to its own policy until Redis comes back:
+// authentication fails closed (the request is refused with 503), caches
+// fail open (the request falls through to MySQL).
+func NewRedisClient(cfg RedisConfig) redis.UniversalClient {
+	var rdb redis.UniversalClient
+	switch cfg.Mode {
+	case RedisModeSentinel:
+		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			MinIdleConns:     cfg.MinIdleConns,
+			DialTimeout:      cfg.DialTimeout,
+			ReadTimeout:      cfg.ReadTimeout,
+			WriteTimeout:     cfg.WriteTimeout,
+			PoolTimeout:      cfg.PoolTimeout,
+		})
+	case RedisModeCluster:
+		rdb = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			Password:     cfg.Password,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			PoolTimeout:  cfg.PoolTimeout,
+		})
+	default:
+		rdb = redis.NewClient(&redis.Options{
+			Addr:         cfg.Addr,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			PoolTimeout:  cfg.PoolTimeout,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout+time.Second)
+	defer cancel()
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		log.Default().Println("Redis is not reachable, continuing in degraded mode: " + err.Error())
+	} else {
+		log.Default().Println("Redis Connected Succesfully")
+	}
 
-	return rdb, nil
+	return rdb
 }
@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type AvailabilityController interface {
+	GetMenuAvailability(c echo.Context) error
+	SetMenuAvailability(c echo.Context) error
+	GetCategoryAvailability(c echo.Context) error
+	SetCategoryAvailability(c echo.Context) error
+}
+
+type availabilityControllerImpl struct {
+	AvailabilityService services.AvailabilityService
+}
+
+// GetMenuAvailability implements AvailabilityController.
+func (a *availabilityControllerImpl) GetMenuAvailability(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+
+	windows, err := a.AvailabilityService.GetMenuWindows(uint(id))
+	if err != nil {
+		return availabilityError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Menu availability retrieved successfully",
+		Data:    windows,
+	})
+}
+
+// SetMenuAvailability implements AvailabilityController.
+// PUT /menu/:id/availability replaces the whole schedule of the item.
+func (a *availabilityControllerImpl) SetMenuAvailability(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.AvailabilityRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	windows, err := a.AvailabilityService.SetMenuWindows(uint(id), payload.Windows)
+	if err != nil {
+		return availabilityError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Menu availability updated successfully",
+		Data:    windows,
+	})
+}
+
+// GetCategoryAvailability implements AvailabilityController.
+func (a *availabilityControllerImpl) GetCategoryAvailability(c echo.Context) error {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid category ID: " + err.Error(),
+		})
+	}
+
+	windows, err := a.AvailabilityService.GetCategoryWindows(uint(id))
+	if err != nil {
+		return availabilityError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Category availability retrieved successfully",
+		Data:    windows,
+	})
+}
+
+// SetCategoryAvailability implements AvailabilityController.
+// PUT /categories/:id/availability replaces the schedule of the category,
+// which applies to its items and to every subcategory.
+func (a *availabilityControllerImpl) SetCategoryAvailability(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid category ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.AvailabilityRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	windows, err := a.AvailabilityService.SetCategoryWindows(uint(id), payload.Windows)
+	if err != nil {
+		return availabilityError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Category availability updated successfully",
+		Data:    windows,
+	})
+}
+
+func availabilityError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidAvailability):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Not found: " + err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle availability: " + err.Error(),
+		})
+	}
+}
+
+func NewAvailabilityController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) AvailabilityController {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	availabilityRepo := repositories.NewCachedAvailabilityRepository(repositories.NewAvailabilityRepository(db), rdb, cfg.Cache.TTL)
+	return &availabilityControllerImpl{
+		AvailabilityService: services.NewAvailabilityService(availabilityRepo, menuRepo, categoryRepo, cfg.App.Location()),
+	}
+}
@@ -0,0 +1,357 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// CartSessionHeader carries the session of a guest cart. It is handed out
+// on the first cart request without one and sent back by the client.
+const CartSessionHeader = "X-Cart-Session"
+
+var errInvalidCartSession = errors.New("invalid " + CartSessionHeader + " header")
+
+type CartController interface {
+	GetCart(c echo.Context) error
+	UpdateCart(c echo.Context) error
+	ClearCart(c echo.Context) error
+	MergeCart(c echo.Context) error
+	AddItem(c echo.Context) error
+	UpdateItem(c echo.Context) error
+	RemoveItem(c echo.Context) error
+	SetVoucher(c echo.Context) error
+	RemoveVoucher(c echo.Context) error
+	Checkout(c echo.Context) error
+}
+
+type cartControllerImpl struct {
+	CartService services.CartService
+}
+
+// GetCart implements CartController.
+func (o *cartControllerImpl) GetCart(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	cart, err := o.CartService.GetCart(owner)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Cart retrieved successfully", cart)
+}
+
+// UpdateCart implements CartController.
+func (o *cartControllerImpl) UpdateCart(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	payload := new(dto.CartUpdateRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	cart, err := o.CartService.UpdateCart(owner, *payload)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Cart updated successfully", cart)
+}
+
+// ClearCart implements CartController.
+func (o *cartControllerImpl) ClearCart(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	if err := o.CartService.ClearCart(owner); err != nil {
+		return cartError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Cart cleared successfully",
+	})
+}
+
+// MergeCart implements CartController.
+// A client that signs in with a guest cart calls it once with the guest
+// session; the guest cart is moved into the user's and the merged cart
+// returned.
+func (o *cartControllerImpl) MergeCart(c echo.Context) error {
+	userID, ok := c.Get("user_id").(uint)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	sessionID := c.Request().Header.Get(CartSessionHeader)
+	if !services.ValidCartSessionID(sessionID) {
+		return cartError(c, errInvalidCartSession)
+	}
+
+	if err := o.CartService.MergeGuestCart(sessionID, userID); err != nil {
+		return cartError(c, err)
+	}
+	owner := services.CartOwner{UserID: userID}
+	cart, err := o.CartService.GetCart(owner)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Guest cart merged successfully", cart)
+}
+
+// AddItem implements CartController.
+func (o *cartControllerImpl) AddItem(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	payload := new(dto.OrderItemRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	cart, err := o.CartService.AddItem(owner, *payload)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Item added to cart", cart)
+}
+
+// UpdateItem implements CartController.
+// The payload replaces the line; a quantity of 0 removes it.
+func (o *cartControllerImpl) UpdateItem(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	lineID, err := strconv.Atoi(c.Param("line"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid cart line ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.OrderItemRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	cart, err := o.CartService.UpdateItem(owner, lineID, *payload)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Cart item updated successfully", cart)
+}
+
+// RemoveItem implements CartController.
+func (o *cartControllerImpl) RemoveItem(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	lineID, err := strconv.Atoi(c.Param("line"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid cart line ID: " + err.Error(),
+		})
+	}
+
+	cart, err := o.CartService.RemoveItem(owner, lineID)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Item removed from cart", cart)
+}
+
+// SetVoucher implements CartController.
+func (o *cartControllerImpl) SetVoucher(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	payload := new(dto.CartVoucherRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	cart, err := o.CartService.SetVoucher(owner, payload.Code)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Voucher applied to cart", cart)
+}
+
+// RemoveVoucher implements CartController.
+func (o *cartControllerImpl) RemoveVoucher(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	cart, err := o.CartService.RemoveVoucher(owner)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return cartResponse(c, owner, "Voucher removed from cart", cart)
+}
+
+// Checkout implements CartController.
+// The cart is priced again before the order is placed. If it changed since
+// the customer last saw it the checkout is refused with 409 and the cart
+// as it is now in data.
+func (o *cartControllerImpl) Checkout(c echo.Context) error {
+	owner, err := o.cartOwner(c)
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	payload := new(dto.CartCheckoutRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	order, err := o.CartService.Checkout(owner, *payload)
+	var rewardRejected *services.RewardRejectedError
+	if errors.As(err, &rewardRejected) {
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Reward cannot be used: " + rewardRejected.Reason,
+		})
+	}
+	if err != nil {
+		return cartError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Order created successfully",
+		Data:    order,
+	})
+}
+
+// cartOwner resolves whose cart a request is about. Signed-in users get
+// their own cart, a guest cart is only merged into it by MergeCart. Guests
+// without a session are handed a new one.
+func (o *cartControllerImpl) cartOwner(c echo.Context) (services.CartOwner, error) {
+	if userID, ok := c.Get("user_id").(uint); ok {
+		return services.CartOwner{UserID: userID}, nil
+	}
+
+	sessionID := c.Request().Header.Get(CartSessionHeader)
+	if sessionID != "" && !services.ValidCartSessionID(sessionID) {
+		return services.CartOwner{}, errInvalidCartSession
+	}
+	if sessionID == "" {
+		var err error
+		if sessionID, err = services.NewCartSessionID(); err != nil {
+			return services.CartOwner{}, err
+		}
+	}
+	c.Response().Header().Set(CartSessionHeader, sessionID)
+	return services.CartOwner{SessionID: sessionID}, nil
+}
+
+func cartResponse(c echo.Context, owner services.CartOwner, message string, cart dto.CartResponse) error {
+	cart.SessionID = owner.SessionID
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    cart,
+	})
+}
+
+func cartError(c echo.Context, err error) error {
+	var unavailable *services.UnavailableItemsError
+	var rejected *services.VoucherRejectedError
+	var changed *services.CartChangedError
+	switch {
+	case errors.As(err, &unavailable):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Some items cannot be ordered right now",
+			Data:    unavailable.Items,
+		})
+	case errors.As(err, &rejected):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Voucher cannot be used: " + rejected.Reason,
+		})
+	case errors.As(err, &changed):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: "Cart has changed: " + changed.Reason,
+			Data:    changed.Cart,
+		})
+	case errors.Is(err, repositories.ErrCartBusy):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: repositories.ErrCartBusy.Error(),
+		})
+	case errors.Is(err, services.ErrCartLineNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Cart line not found",
+		})
+	case errors.Is(err, errInvalidCartSession), errors.Is(err, services.ErrInvalidCart), errors.Is(err, services.ErrInvalidOrder):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle cart: " + err.Error(),
+		})
+	}
+}
+
+func NewCartController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) CartController {
+	return &cartControllerImpl{
+		CartService: services.NewCartService(repositories.NewCartRepository(rdb, cfg.Cart.TTL), newOrderService(db, cfg, rdb), cfg.Cart),
+	}
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/services"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+)
+
+// mergeRecorder is a CartService that records guest cart merges.
+type mergeRecorder struct {
+	services.CartService
+	merged []string
+}
+
+func (m *mergeRecorder) GetCart(owner services.CartOwner) (dto.CartResponse, error) {
+	return dto.CartResponse{}, nil
+}
+
+func (m *mergeRecorder) MergeGuestCart(sessionID string, userID uint) error {
+	m.merged = append(m.merged, sessionID)
+	return nil
+}
+
+func TestCartMergesOnlyWhenAsked(t *testing.T) {
+	const session = "0123456789abcdef0123456789abcdef"
+	request := func(method string, handler func(*cartControllerImpl) echo.HandlerFunc) (*mergeRecorder, int) {
+		recorder := &mergeRecorder{}
+		controller := &cartControllerImpl{CartService: recorder}
+		req := httptest.NewRequest(method, "/cart", nil)
+		req.Header.Set(CartSessionHeader, session)
+		rec := httptest.NewRecorder()
+		c := echo.New().NewContext(req, rec)
+		c.Set("user_id", uint(7))
+		if err := handler(controller)(c); err != nil {
+			t.Fatal(err)
+		}
+		return recorder, rec.Code
+	}
+
+	recorder, code := request(http.MethodGet, func(o *cartControllerImpl) echo.HandlerFunc { return o.GetCart })
+	if code != http.StatusOK || len(recorder.merged) != 0 {
+		t.Errorf("GET /cart: status %d, merged %v, want 200 and no merge", code, recorder.merged)
+	}
+
+	recorder, code = request(http.MethodPost, func(o *cartControllerImpl) echo.HandlerFunc { return o.MergeCart })
+	if code != http.StatusOK || len(recorder.merged) != 1 || recorder.merged[0] != session {
+		t.Errorf("POST /cart/merge: status %d, merged %v, want 200 and one merge of %s", code, recorder.merged, session)
+	}
+}
@@ -1,15 +1,18 @@
 package controllers
 
 import (
+	"coffee_shop/config"
 	"coffee_shop/dto"
 	"coffee_shop/models"
 	"coffee_shop/repositories"
 	"coffee_shop/services"
+	"errors"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -19,6 +22,8 @@ type CategoryController interface {
 	UpdateCategory(c echo.Context) error
 	DeleteCategory(c echo.Context) error
 	GetAllCategories(c echo.Context) error
+	GetCategoryTree(c echo.Context) error
+	ReorderCategories(c echo.Context) error
 }
 
 type categoryControllerImpl struct {
@@ -41,7 +46,74 @@ func (r *categoryControllerImpl) GetAllCategories(c echo.Context) error {
 		Data:    categories,
 	}
 
-	return c.JSON(http.StatusOK, apiResponse)
+	return jsonWithETag(c, http.StatusOK, apiResponse)
+}
+
+// GetCategoryTree implements CategoryController.
+func (r *categoryControllerImpl) GetCategoryTree(c echo.Context) error {
+	tree, err := r.CategoryService.GetCategoryTree()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get category tree: " + err.Error(),
+		})
+	}
+
+	apiResponse := dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Category tree retrieved successfully",
+		Data:    tree,
+	}
+
+	return jsonWithETag(c, http.StatusOK, apiResponse)
+}
+
+// ReorderCategories implements CategoryController.
+// PUT /categories/reorder moves categories to a new parent and position.
+func (r *categoryControllerImpl) ReorderCategories(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	payload := new(dto.CategoryReorderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload : " + err.Error(),
+		})
+	}
+
+	if err := r.CategoryService.Reorder(payload.Categories); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Failed to reorder categories: " + err.Error(),
+		})
+	}
+
+	tree, err := r.CategoryService.GetCategoryTree()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get category tree: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Categories reordered successfully",
+		Data:    tree,
+	})
 }
 
 // CreateCategory implements CategoryController.
@@ -73,6 +145,9 @@ func (r *categoryControllerImpl) CreateCategory(c echo.Context) error {
 	categoriesName := strings.ToTitle(userPayload.CategoriesName)
 	result, err := r.CategoryService.CreateCategory(models.Category{
 		CategoriesName: categoriesName,
+		ParentID:       userPayload.ParentID,
+		SortOrder:      userPayload.SortOrder,
+		TaxRateID:      userPayload.TaxRateID,
 	})
 
 	if err != nil {
@@ -93,6 +168,9 @@ func (r *categoryControllerImpl) CreateCategory(c echo.Context) error {
 }
 
 // DeleteCategory implements CategoryController.
+// DELETE /categories/:id is refused with 409 while the category still has
+// menu items or subcategories. ?reassign_to=ID moves them to another
+// category first, ?cascade=true deletes them with it.
 func (r *categoryControllerImpl) DeleteCategory(c echo.Context) error {
 	// Check if user is admin
 	userRole, ok := c.Get("role").(string)
@@ -118,10 +196,49 @@ func (r *categoryControllerImpl) DeleteCategory(c echo.Context) error {
 		})
 	}
 
-	err = r.CategoryService.DeleteCategory(uint(id))
+	var reassignTo *uint
+	if param := c.QueryParam("reassign_to"); param != "" {
+		target, err := strconv.ParseUint(param, 10, 32)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid reassign_to category ID: " + err.Error(),
+			})
+		}
+		targetID := uint(target)
+		reassignTo = &targetID
+	}
+	cascade := false
+	if param := c.QueryParam("cascade"); param != "" {
+		cascade, err = strconv.ParseBool(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid cascade parameter: " + err.Error(),
+			})
+		}
+	}
+
+	if reassignTo != nil && cascade {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "reassign_to and cascade cannot be combined",
+		})
+	}
+
+	result, err := r.CategoryService.DeleteCategory(uint(id), reassignTo, cascade)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		status := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, repositories.ErrCategoryInUse):
+			status = http.StatusConflict
+		case errors.Is(err, repositories.ErrInvalidCategoryTarget):
+			status = http.StatusBadRequest
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			status = http.StatusNotFound
+		}
+		return c.JSON(status, dto.ApiResponse{
+			Status:  status,
 			Message: "Failed to delete category: " + err.Error(),
 		})
 	}
@@ -129,6 +246,7 @@ func (r *categoryControllerImpl) DeleteCategory(c echo.Context) error {
 	ApiResponse := dto.ApiResponse{
 		Status:  http.StatusOK,
 		Message: "Category deleted successfully",
+		Data:    result,
 	}
 
 	return c.JSON(http.StatusOK, ApiResponse)
@@ -164,6 +282,7 @@ func (r *categoryControllerImpl) GetCategoryByID(c echo.Context) error {
 }
 
 // UpdateCategory implements CategoryController.
+// Only the fields in the payload are changed.
 func (r *categoryControllerImpl) UpdateCategory(c echo.Context) error {
 	// Check if user is admin
 	userRole, ok := c.Get("role").(string)
@@ -180,7 +299,7 @@ func (r *categoryControllerImpl) UpdateCategory(c echo.Context) error {
 		})
 	}
 
-	userPayload := new(dto.CategoryRequest)
+	userPayload := new(dto.CategoryUpdateRequest)
 	err := c.Bind(userPayload)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
@@ -198,10 +317,8 @@ func (r *categoryControllerImpl) UpdateCategory(c echo.Context) error {
 		})
 	}
 
-	categoriesName := strings.ToTitle(userPayload.CategoriesName)
-	result, err := r.CategoryService.UpdateCategory(uint(id), models.Category{
-		CategoriesName: categoriesName,
-	})
+	userPayload.CategoriesName = strings.ToTitle(userPayload.CategoriesName)
+	result, err := r.CategoryService.UpdateCategory(uint(id), *userPayload)
 
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
@@ -219,8 +336,10 @@ func (r *categoryControllerImpl) UpdateCategory(c echo.Context) error {
 	return c.JSON(http.StatusOK, ApiResponse)
 }
 
-func NewCategoryController(db *gorm.DB) CategoryController {
-	service := services.NewCategoryService(repositories.NewCategoryRepository(db))
+func NewCategoryController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) CategoryController {
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	service := services.NewCategoryService(categoryRepo, menuRepo)
 	return &categoryControllerImpl{
 		CategoryService: service,
 	}
@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// jsonWithETag writes body as JSON with a weak ETag computed from the
+// encoded bytes. A client that sends the same value in If-None-Match gets
+// an empty 304, so polling an unchanged list costs one small response.
+func jsonWithETag(c echo.Context, status int, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	// Clients may store the response but must revalidate before using it
+	header.Set("Cache-Control", "no-cache")
+
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.JSONBlob(status, data)
+}
+
+// etagMatches implements the weak comparison used for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
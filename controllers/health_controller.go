@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/lifecycle"
+	"coffee_shop/services"
+	"coffee_shop/storage"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type HealthController interface {
+	Livez(c echo.Context) error
+	Readyz(c echo.Context) error
+}
+
+type HealthControllerImpl struct {
+	HealthService services.HealthService
+}
+
+// Livez implements HealthController.
+// It only proves the process is serving HTTP, it never touches dependencies
+// so a MySQL or Redis outage does not get the instance restarted.
+func (h *HealthControllerImpl) Livez(c echo.Context) error {
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Coffee Shop API is alive",
+		Data:    h.HealthService.Liveness(),
+	})
+}
+
+// Readyz implements HealthController.
+func (h *HealthControllerImpl) Readyz(c echo.Context) error {
+	report := h.HealthService.Readiness(c.Request().Context())
+	if report.Status != dto.HealthStatusUp {
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
+			Message: "Coffee Shop API is not ready",
+			Data:    report,
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Coffee Shop API is ready",
+		Data:    report,
+	})
+}
+
+func NewHealthController(db *gorm.DB, rdb redis.UniversalClient, store storage.BlobStore, lc *lifecycle.Manager) HealthController {
+	service := services.NewHealthService(db, rdb, store, lc)
+	return &HealthControllerImpl{
+		HealthService: service,
+	}
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"bytes"
+	"coffee_shop/dto"
+	"coffee_shop/storage"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// Content-hashed keys never change, so browsers and CDNs may keep them
+	cacheControlImmutable = "public, max-age=31536000, immutable"
+	// Other keys, such as the default image, can be replaced in place
+	cacheControlShort = "public, max-age=300"
+)
+
+type ImageController interface {
+	ServeImage(c echo.Context) error
+}
+
+type ImageControllerImpl struct {
+	store storage.BlobStore
+}
+
+// ServeImage implements ImageController.
+// GET /images/* streams a blob from the store. http.ServeContent answers
+// conditional requests (If-None-Match, If-Modified-Since) with 304 and
+// handles Range requests.
+func (i *ImageControllerImpl) ServeImage(c echo.Context) error {
+	key := storage.NormalizeKey(c.Param("*"))
+	if key == "" || isHiddenKey(key) {
+		return imageNotFound(c)
+	}
+
+	blob, info, err := i.store.Get(c.Request().Context(), key)
+	if errors.Is(err, storage.ErrNotFound) {
+		return imageNotFound(c)
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to read image: " + err.Error(),
+		})
+	}
+	defer blob.Close()
+
+	content, ok := blob.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(blob)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to read image: " + err.Error(),
+			})
+		}
+		content = bytes.NewReader(data)
+	}
+
+	header := c.Response().Header()
+	if info.ETag != "" {
+		header.Set("ETag", info.ETag)
+	}
+	if info.ContentType != "" {
+		header.Set(echo.HeaderContentType, info.ContentType)
+	}
+	header.Set("X-Content-Type-Options", "nosniff")
+	if storage.IsContentAddressed(key) {
+		header.Set("Cache-Control", cacheControlImmutable)
+	} else {
+		header.Set("Cache-Control", cacheControlShort)
+	}
+
+	http.ServeContent(c.Response(), c.Request(), path.Base(key), info.LastModified, content)
+	return nil
+}
+
+// isHiddenKey hides dot-prefixed keys such as the readiness probe and
+// temporary upload files.
+func isHiddenKey(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		if strings.HasPrefix(segment, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+func imageNotFound(c echo.Context) error {
+	return c.JSON(http.StatusNotFound, dto.ApiResponse{
+		Status:  http.StatusNotFound,
+		Message: "Image not found",
+	})
+}
+
+func NewImageController(store storage.BlobStore) ImageController {
+	return &ImageControllerImpl{
+		store: store,
+	}
+}
@@ -0,0 +1,343 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Paging of the loyalty history.
+const (
+	loyaltyDefaultLimit = 20
+	loyaltyMaxLimit     = 100
+)
+
+type LoyaltyController interface {
+	GetMyLoyalty(c echo.Context) error
+	GetUserLoyalty(c echo.Context) error
+	AdjustPoints(c echo.Context) error
+	GetAllRewards(c echo.Context) error
+	CreateReward(c echo.Context) error
+	UpdateReward(c echo.Context) error
+	DeleteReward(c echo.Context) error
+}
+
+type loyaltyControllerImpl struct {
+	LoyaltyService services.LoyaltyService
+}
+
+// GetMyLoyalty implements LoyaltyController.
+// GET /user/loyalty returns the balance and history of the current user,
+// paged with ?limit= and ?offset=.
+func (l *loyaltyControllerImpl) GetMyLoyalty(c echo.Context) error {
+	// Get user_id from JWT token
+	userID := c.Get("user_id").(uint)
+
+	limit, offset, err := loyaltyPage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	loyalty, err := l.LoyaltyService.GetLoyalty(userID, limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get loyalty points: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Loyalty points retrieved successfully",
+		Data:    loyalty,
+	})
+}
+
+// GetUserLoyalty implements LoyaltyController.
+// GET /loyalty/users/:id is the same view of any user, for admins.
+func (l *loyaltyControllerImpl) GetUserLoyalty(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid user ID: " + err.Error(),
+		})
+	}
+	limit, offset, err := loyaltyPage(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	}
+
+	loyalty, err := l.LoyaltyService.GetLoyalty(uint(id), limit, offset)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get loyalty points: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Loyalty points retrieved successfully",
+		Data:    loyalty,
+	})
+}
+
+// AdjustPoints implements LoyaltyController.
+// POST /loyalty/adjustments records the admin making the adjustment.
+func (l *loyaltyControllerImpl) AdjustPoints(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+	adminID := c.Get("user_id").(uint)
+
+	payload := new(dto.LoyaltyAdjustRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	entry, err := l.LoyaltyService.AdjustPoints(adminID, *payload)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "User not found",
+		})
+	}
+	if err != nil {
+		return loyaltyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Loyalty points adjusted successfully",
+		Data:    entry,
+	})
+}
+
+// GetAllRewards implements LoyaltyController.
+// Admins see inactive rewards too.
+func (l *loyaltyControllerImpl) GetAllRewards(c echo.Context) error {
+	userRole, _ := c.Get("role").(string)
+
+	rewards, err := l.LoyaltyService.GetAllRewards(userRole != "admin")
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get rewards: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Rewards retrieved successfully",
+		Data:    rewards,
+	})
+}
+
+// CreateReward implements LoyaltyController.
+func (l *loyaltyControllerImpl) CreateReward(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	payload := new(dto.LoyaltyRewardRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	reward, err := l.LoyaltyService.CreateReward(*payload)
+	if err != nil {
+		return loyaltyError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Reward created successfully",
+		Data:    reward,
+	})
+}
+
+// UpdateReward implements LoyaltyController.
+func (l *loyaltyControllerImpl) UpdateReward(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid reward ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.LoyaltyRewardRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	reward, err := l.LoyaltyService.UpdateReward(uint(id), *payload)
+	if err != nil {
+		return loyaltyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Reward updated successfully",
+		Data:    reward,
+	})
+}
+
+// DeleteReward implements LoyaltyController.
+func (l *loyaltyControllerImpl) DeleteReward(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid reward ID: " + err.Error(),
+		})
+	}
+
+	if err := l.LoyaltyService.DeleteReward(uint(id)); err != nil {
+		return loyaltyError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Reward deleted successfully",
+	})
+}
+
+// loyaltyPage reads ?limit= and ?offset= for the loyalty history.
+func loyaltyPage(c echo.Context) (int, int, error) {
+	limit, offset := loyaltyDefaultLimit, 0
+	if param := c.QueryParam("limit"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 1 || value > loyaltyMaxLimit {
+			return 0, 0, fmt.Errorf("limit must be between 1 and %d", loyaltyMaxLimit)
+		}
+		limit = value
+	}
+	if param := c.QueryParam("offset"); param != "" {
+		value, err := strconv.Atoi(param)
+		if err != nil || value < 0 {
+			return 0, 0, errors.New("offset must be 0 or more")
+		}
+		offset = value
+	}
+	return limit, offset, nil
+}
+
+func loyaltyError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidLoyalty):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Reward not found",
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle loyalty request: " + err.Error(),
+		})
+	}
+}
+
+func NewLoyaltyController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) LoyaltyController {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	return &loyaltyControllerImpl{
+		LoyaltyService: services.NewLoyaltyService(repositories.NewLoyaltyRepository(db), repositories.NewUserRepository(db), menuRepo, cfg.Loyalty),
+	}
+}
@@ -1,19 +1,24 @@
 package controllers
 
 import (
+	"bytes"
+	"coffee_shop/config"
 	"coffee_shop/dto"
+	"coffee_shop/imaging"
 	"coffee_shop/models"
 	"coffee_shop/repositories"
 	"coffee_shop/services"
+	"coffee_shop/storage"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -24,16 +29,24 @@ type MenuController interface {
 	CreateMenu(c echo.Context) error
 	DeleteMenu(c echo.Context) error
 	GetMenuByID(c echo.Context) error
+	ExportMenus(c echo.Context) error
+	ImportMenus(c echo.Context) error
+	SetSoldOut(c echo.Context) error
 }
 
 type MenuControllerImpl struct {
-	MenuService services.MenuService
+	MenuService         services.MenuService
+	MenuTransferService services.MenuTransferService
+	ImageService        services.ImageService
+	upload              config.UploadConfig
 }
 
-func storeImage(c echo.Context, menuName string) (string, error) {
-	// Limit file size to 2MB
-	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, 2<<20)
-	if err := c.Request().ParseMultipartForm(2 << 20); err != nil {
+// storeImage validates the uploaded menu image by its content, stores all
+// of its renditions and returns the key of the full rendition.
+func storeImage(c echo.Context, images services.ImageService, cfg config.UploadConfig) (string, error) {
+	// Limit file size to the configured upload limit
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, cfg.MaxImageSize)
+	if err := c.Request().ParseMultipartForm(cfg.MaxImageSize); err != nil {
 		return "", err
 	}
 	defer c.Request().MultipartForm.RemoveAll()
@@ -43,14 +56,8 @@ func storeImage(c echo.Context, menuName string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	if file.Size > 2*1024*1024 {
-		return "", fmt.Errorf("file size exceeds 2MB limit")
-	}
-
-	// Validate file type
-	fileType := file.Header.Get("Content-Type")
-	if fileType != "image/jpeg" && fileType != "image/png" && fileType != "image/jpg" {
-		return "", fmt.Errorf("only JPEG, JPG, and PNG images are allowed")
+	if file.Size > cfg.MaxImageSize {
+		return "", fmt.Errorf("file size exceeds %d bytes limit", cfg.MaxImageSize)
 	}
 
 	// Open the file
@@ -60,22 +67,17 @@ func storeImage(c echo.Context, menuName string) (string, error) {
 	}
 	defer src.Close()
 
-	// Create destination file
-	storagePath := "./image/menu_img/"
-	file.Filename = menuName + filepath.Ext(file.Filename)
-	path := filepath.Join(storagePath, file.Filename)
-	dst, err := os.Create(path)
+	content, err := io.ReadAll(src)
 	if err != nil {
 		return "", err
 	}
-	defer dst.Close()
 
-	// Copy the file content to destination
-	if _, err = io.Copy(dst, src); err != nil {
-		return "", c.JSON(http.StatusBadRequest, err)
+	// The Content-Type header is ignored, the format is sniffed from the bytes
+	key, err := images.StoreMenuImage(c.Request().Context(), content)
+	if errors.Is(err, imaging.ErrUnsupportedFormat) {
+		return "", fmt.Errorf("only %s images are allowed", strings.Join(cfg.AllowedTypes, ", "))
 	}
-
-	return storagePath + file.Filename, nil
+	return key, err
 }
 
 // CreateMenu implements MenuController.
@@ -104,7 +106,7 @@ func (m *MenuControllerImpl) CreateMenu(c echo.Context) error {
 	}
 
 	// Store Image
-	imgURL, err := storeImage(c, userPayload.MenuName)
+	imgURL, err := storeImage(c, m.ImageService, m.upload)
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
 			Status:  http.StatusBadRequest,
@@ -179,8 +181,29 @@ func (m *MenuControllerImpl) DeleteMenu(c echo.Context) error {
 }
 
 // GetAllMenus implements MenuController.
+// GET /menu?include=category,variants,modifiers expands the relations and
+// ?available=true lists only the items that can be ordered right now.
 func (m *MenuControllerImpl) GetAllMenus(c echo.Context) error {
-	allMenus, err := m.MenuService.GetAllMenus()
+	includes, err := services.ParseMenuIncludes(c.QueryParam("include"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid include parameter: " + err.Error(),
+		})
+	}
+	query := dto.MenuQuery{Includes: includes}
+	if param := c.QueryParam("available"); param != "" {
+		available, err := strconv.ParseBool(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid available parameter, expected true or false",
+			})
+		}
+		query.Available = &available
+	}
+
+	allMenus, err := m.MenuService.GetAllMenus(query)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
 			Status:  http.StatusInternalServerError,
@@ -194,7 +217,7 @@ func (m *MenuControllerImpl) GetAllMenus(c echo.Context) error {
 		Data:    allMenus,
 	}
 
-	return c.JSON(http.StatusOK, apiResponse)
+	return jsonWithETag(c, http.StatusOK, apiResponse)
 }
 
 // GetMenuByID implements MenuController.
@@ -208,7 +231,15 @@ func (m *MenuControllerImpl) GetMenuByID(c echo.Context) error {
 		})
 	}
 
-	menu, err := m.MenuService.GetMenuByID(uint(menuID))
+	includes, err := services.ParseMenuIncludes(c.QueryParam("include"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid include parameter: " + err.Error(),
+		})
+	}
+
+	menu, err := m.MenuService.GetMenuByID(uint(menuID), includes)
 	if err != nil {
 		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
 			Status:  http.StatusInternalServerError,
@@ -259,8 +290,11 @@ func (m *MenuControllerImpl) UpdateMenu(c echo.Context) error {
 		})
 	}
 
-	// Store Image
-	imgURL, err := storeImage(c, updatePayload.MenuName)
+	// Store Image, the current one is kept when no file is sent
+	imgURL, err := storeImage(c, m.ImageService, m.upload)
+	if errors.Is(err, http.ErrNotMultipart) || errors.Is(err, http.ErrMissingFile) {
+		imgURL, err = "", nil
+	}
 	if err != nil {
 		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
 			Status:  http.StatusBadRequest,
@@ -293,9 +327,209 @@ func (m *MenuControllerImpl) UpdateMenu(c echo.Context) error {
 	return c.JSON(http.StatusOK, apiResponse)
 }
 
-func NewMenuController(db *gorm.DB) MenuController {
-	service := services.NewMenuService(repositories.NewMenuRepository(db))
+// ExportMenus implements MenuController.
+// GET /menu/export?format=json|csv, JSON is the default.
+func (m *MenuControllerImpl) ExportMenus(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	switch c.QueryParam("format") {
+	case "", "json":
+		export, err := m.MenuTransferService.Export()
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to export menus: " + err.Error(),
+			})
+		}
+		return c.JSON(http.StatusOK, dto.ApiResponse{
+			Status:  http.StatusOK,
+			Message: "Menus exported successfully",
+			Data:    export,
+		})
+	case "csv":
+		var buf bytes.Buffer
+		if err := m.MenuTransferService.ExportCSV(&buf); err != nil {
+			return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to export menus: " + err.Error(),
+			})
+		}
+		c.Response().Header().Set(echo.HeaderContentDisposition, `attachment; filename="menu.csv"`)
+		return c.Blob(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+	default:
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid format, expected json or csv",
+		})
+	}
+}
+
+// ImportMenus implements MenuController.
+// POST /menu/import?mode=dry-run|commit with a multipart "file" field
+// holding a .csv or .json file. Dry-run is the default.
+func (m *MenuControllerImpl) ImportMenus(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	mode := c.QueryParam("mode")
+	if mode == "" {
+		mode = dto.MenuImportDryRun
+	}
+
+	// Limit the upload to the configured import size
+	c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, m.upload.MaxImportSize)
+	file, err := c.FormFile("file")
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Import file is required: " + err.Error(),
+		})
+	}
+	src, err := file.Open()
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Failed to open import file: " + err.Error(),
+		})
+	}
+	defer src.Close()
+
+	var rows []dto.MenuTransferRow
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		rows, err = services.ParseMenuCSV(src)
+	case ".json":
+		rows, err = services.ParseMenuJSON(src)
+	default:
+		err = fmt.Errorf("unsupported file type %q, expected .csv or .json", filepath.Ext(file.Filename))
+	}
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid import file: " + err.Error(),
+		})
+	}
+
+	result, err := m.MenuTransferService.Import(rows, mode)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Failed to import menus: " + err.Error(),
+		})
+	}
+
+	if result.Failed > 0 {
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Import file has invalid rows, nothing was imported",
+			Data:    result,
+		})
+	}
+
+	message := "Import file is valid, nothing was imported (dry-run)"
+	if result.Committed {
+		message = "Menus imported successfully"
+	}
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    result,
+	})
+}
+
+// SetSoldOut implements MenuController.
+// PATCH /menu/:id/sold-out marks an item as 86'd or back in stock. Cashiers
+// may do it as well, they are the first to know.
+func (m *MenuControllerImpl) SetSoldOut(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	idParam := c.Param("id")
+	menuID, err := strconv.Atoi(idParam)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.SoldOutRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	menu, err := m.MenuService.SetSoldOut(uint(menuID), payload.SoldOut)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Menu not found",
+		})
+	}
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update menu: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Menu sold out status updated successfully",
+		Data:    menu,
+	})
+}
+
+func NewMenuController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient, store storage.BlobStore) MenuController {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	availabilityRepo := repositories.NewCachedAvailabilityRepository(repositories.NewAvailabilityRepository(db), rdb, cfg.Cache.TTL)
+	images := services.NewImageService(menuRepo, store, cfg)
+	availability := services.NewAvailabilityService(availabilityRepo, menuRepo, categoryRepo, cfg.App.Location())
+	service := services.NewMenuService(menuRepo, images, availability)
 	return &MenuControllerImpl{
-		MenuService: service,
+		MenuService:         service,
+		MenuTransferService: services.NewMenuTransferService(db, rdb, cfg.Upload.MaxImportRows),
+		ImageService:        images,
+		upload:              cfg.Upload,
 	}
 }
@@ -0,0 +1,337 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type MenuOptionController interface {
+	CreateVariant(c echo.Context) error
+	UpdateVariant(c echo.Context) error
+	DeleteVariant(c echo.Context) error
+	CreateModifier(c echo.Context) error
+	UpdateModifier(c echo.Context) error
+	DeleteModifier(c echo.Context) error
+}
+
+type menuOptionControllerImpl struct {
+	MenuOptionService services.MenuOptionService
+}
+
+// CreateVariant implements MenuOptionController.
+// POST /menu/:id/variants adds a variant to a menu item.
+func (m *menuOptionControllerImpl) CreateVariant(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.MenuVariantRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	variant, err := m.MenuOptionService.CreateVariant(uint(menuID), *payload)
+	if err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Variant created successfully",
+		Data:    variant,
+	})
+}
+
+// UpdateVariant implements MenuOptionController.
+// PUT /menu/:id/variants/:variant_id changes the fields that are sent.
+func (m *menuOptionControllerImpl) UpdateVariant(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+	id, err := strconv.Atoi(c.Param("variant_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid variant ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.MenuVariantRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	variant, err := m.MenuOptionService.UpdateVariant(uint(menuID), uint(id), *payload)
+	if err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Variant updated successfully",
+		Data:    variant,
+	})
+}
+
+// DeleteVariant implements MenuOptionController.
+func (m *menuOptionControllerImpl) DeleteVariant(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+	id, err := strconv.Atoi(c.Param("variant_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid variant ID parameter: " + err.Error(),
+		})
+	}
+
+	if err := m.MenuOptionService.DeleteVariant(uint(menuID), uint(id)); err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Variant deleted successfully",
+	})
+}
+
+// CreateModifier implements MenuOptionController.
+// POST /menu/:id/modifiers adds a modifier to a menu item.
+func (m *menuOptionControllerImpl) CreateModifier(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.MenuModifierRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	modifier, err := m.MenuOptionService.CreateModifier(uint(menuID), *payload)
+	if err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Modifier created successfully",
+		Data:    modifier,
+	})
+}
+
+// UpdateModifier implements MenuOptionController.
+// PUT /menu/:id/modifiers/:modifier_id changes the fields that are sent.
+func (m *menuOptionControllerImpl) UpdateModifier(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+	id, err := strconv.Atoi(c.Param("modifier_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid modifier ID parameter: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.MenuModifierRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	modifier, err := m.MenuOptionService.UpdateModifier(uint(menuID), uint(id), *payload)
+	if err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Modifier updated successfully",
+		Data:    modifier,
+	})
+}
+
+// DeleteModifier implements MenuOptionController.
+func (m *menuOptionControllerImpl) DeleteModifier(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	menuID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid menu ID parameter: " + err.Error(),
+		})
+	}
+	id, err := strconv.Atoi(c.Param("modifier_id"))
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid modifier ID parameter: " + err.Error(),
+		})
+	}
+
+	if err := m.MenuOptionService.DeleteModifier(uint(menuID), uint(id)); err != nil {
+		return menuOptionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Modifier deleted successfully",
+	})
+}
+
+func menuOptionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrInvalidMenuOption):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle menu option request: " + err.Error(),
+		})
+	}
+}
+
+func NewMenuOptionController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) MenuOptionController {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	return &menuOptionControllerImpl{
+		MenuOptionService: services.NewMenuOptionService(menuRepo),
+	}
+}
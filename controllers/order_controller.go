@@ -0,0 +1,376 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/payments"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type OrderController interface {
+	CreateOrder(c echo.Context) error
+	CompleteOrder(c echo.Context) error
+	PrepareOrder(c echo.Context) error
+	CancelOrder(c echo.Context) error
+	RefundOrder(c echo.Context) error
+	GetCreditNotes(c echo.Context) error
+}
+
+type orderControllerImpl struct {
+	OrderService  services.OrderService
+	RefundService services.RefundService
+}
+
+// CreateOrder implements OrderController.
+// Items that are sold out or outside their schedule are refused with 422
+// and listed in data. A voucher or loyalty reward that cannot be used is
+// refused with 422 too.
+func (o *orderControllerImpl) CreateOrder(c echo.Context) error {
+	// Get user_id from JWT token
+	userID := c.Get("user_id").(uint)
+
+	payload := new(dto.OrderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	order, err := o.OrderService.CreateOrder(userID, *payload)
+	var unavailable *services.UnavailableItemsError
+	var rejected *services.VoucherRejectedError
+	var rewardRejected *services.RewardRejectedError
+	switch {
+	case errors.As(err, &unavailable):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Some items cannot be ordered right now",
+			Data:    unavailable.Items,
+		})
+	case errors.As(err, &rejected):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Voucher cannot be used: " + rejected.Reason,
+		})
+	case errors.As(err, &rewardRejected):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Reward cannot be used: " + rewardRejected.Reason,
+		})
+	case errors.Is(err, services.ErrInvalidOrder):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create order: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Order created successfully",
+		Data:    order,
+	})
+}
+
+// CompleteOrder implements OrderController.
+// PATCH /orders/:id/complete hands a pending order over to the customer,
+// who earns their loyalty points for it.
+func (o *orderControllerImpl) CompleteOrder(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	order, points, err := o.OrderService.CompleteOrder(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Order not found",
+		})
+	case errors.Is(err, repositories.ErrOrderNotOpen):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to complete order: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: fmt.Sprintf("Order completed, %d loyalty points earned", points),
+		Data:    order,
+	})
+}
+
+// PrepareOrder implements OrderController.
+// PATCH /orders/:id/prepare starts a pending order. Customers can no
+// longer cancel it after that.
+func (o *orderControllerImpl) PrepareOrder(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	order, err := o.OrderService.PrepareOrder(uint(id))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Order not found",
+		})
+	case errors.Is(err, repositories.ErrOrderNotPending):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to prepare order: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Order is being prepared",
+		Data:    order,
+	})
+}
+
+// CancelOrder implements OrderController.
+// PATCH /orders/:id/cancel cancels an order and refunds it in full.
+// Customers can cancel their own orders while they are pending, staff any
+// order until it is completed.
+func (o *orderControllerImpl) CancelOrder(c echo.Context) error {
+	// Get user_id from JWT token
+	userID := c.Get("user_id").(uint)
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.CancelOrderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	note, err := o.RefundService.CancelOrder(userID, userRole, uint(id), *payload)
+	if err != nil {
+		return refundError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Order canceled, credit note " + note.Number + " issued",
+		Data:    note,
+	})
+}
+
+// RefundOrder implements OrderController.
+// POST /orders/:id/refunds refunds lines of a completed order, in full or
+// in part, and returns the credit note.
+func (o *orderControllerImpl) RefundOrder(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	userID := c.Get("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.RefundOrderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	note, err := o.RefundService.RefundOrder(userID, uint(id), *payload)
+	if err != nil {
+		return refundError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Refund issued as credit note " + note.Number,
+		Data:    note,
+	})
+}
+
+// GetCreditNotes implements OrderController.
+func (o *orderControllerImpl) GetCreditNotes(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	notes, err := o.RefundService.GetCreditNotes(uint(id))
+	if err != nil {
+		return refundError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Credit notes retrieved successfully",
+		Data:    notes,
+	})
+}
+
+func refundError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Order not found",
+		})
+	case errors.Is(err, services.ErrInvalidRefund):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrNotCancelable), errors.Is(err, repositories.ErrOrderNotCompleted), errors.Is(err, repositories.ErrRefundConflict),
+		errors.Is(err, repositories.ErrShiftNotOpen), errors.Is(err, services.ErrNoOpenShift):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrRefundFailed):
+		return c.JSON(http.StatusBadGateway, dto.ApiResponse{
+			Status:  http.StatusBadGateway,
+			Message: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to refund order: " + err.Error(),
+		})
+	}
+}
+
+func NewOrderController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) OrderController {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	loyalty := services.NewLoyaltyService(repositories.NewLoyaltyRepository(db), repositories.NewUserRepository(db), menuRepo, cfg.Loyalty)
+	return &orderControllerImpl{
+		OrderService:  newOrderService(db, cfg, rdb),
+		RefundService: services.NewRefundService(repositories.NewOrderRepository(db), repositories.NewShiftRepository(db), loyalty, payments.NewManualGateway()),
+	}
+}
+
+// newOrderService wires the order pricing pipeline: availability,
+// promotions, vouchers, loyalty rewards and taxes.
+func newOrderService(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) services.OrderService {
+	menuRepo := repositories.NewCachedMenuRepository(repositories.NewMenuRepository(db), rdb, cfg.Cache.TTL)
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	availabilityRepo := repositories.NewCachedAvailabilityRepository(repositories.NewAvailabilityRepository(db), rdb, cfg.Cache.TTL)
+	orderRepo := repositories.NewOrderRepository(db)
+	availability := services.NewAvailabilityService(availabilityRepo, menuRepo, categoryRepo, cfg.App.Location())
+	promotions := services.NewPromotionService(repositories.NewPromotionRepository(db), categoryRepo, cfg.App.Location())
+	vouchers := services.NewVoucherService(repositories.NewVoucherRepository(db), orderRepo, categoryRepo)
+	loyalty := services.NewLoyaltyService(repositories.NewLoyaltyRepository(db), repositories.NewUserRepository(db), menuRepo, cfg.Loyalty)
+	taxes := services.NewTaxService(repositories.NewTaxRepository(db), categoryRepo, cfg.Tax)
+	return services.NewOrderService(orderRepo, menuRepo, availability, promotions, vouchers, loyalty, taxes)
+}
@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type POSController interface {
+	FindCustomers(c echo.Context) error
+	PreviewOrder(c echo.Context) error
+	CreateOrder(c echo.Context) error
+	GetReceipt(c echo.Context) error
+}
+
+type posControllerImpl struct {
+	POSService services.POSService
+}
+
+// FindCustomers implements POSController.
+// GET /pos/customers?phone= looks customers up by phone number.
+func (p *posControllerImpl) FindCustomers(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	customers, err := p.POSService.FindCustomers(c.QueryParam("phone"))
+	if err != nil {
+		return posError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Customers retrieved successfully",
+		Data:    customers,
+	})
+}
+
+// PreviewOrder implements POSController.
+func (p *posControllerImpl) PreviewOrder(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	payload := new(dto.POSOrderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	order, err := p.POSService.PreviewOrder(*payload)
+	if err != nil {
+		return posError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Order priced successfully",
+		Data:    order,
+	})
+}
+
+// CreateOrder implements POSController.
+// The order is recorded under the cashier taking it. The change to give
+// back is in the message and on the cash payments.
+func (p *posControllerImpl) CreateOrder(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	cashierID := c.Get("user_id").(uint)
+
+	payload := new(dto.POSOrderRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	order, err := p.POSService.CreateOrder(cashierID, *payload)
+	if err != nil {
+		return posError(c, err)
+	}
+
+	change := 0.0
+	for _, payment := range order.Payments {
+		change += payment.ChangeDue
+	}
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: fmt.Sprintf("Order created successfully, change due %.2f", change),
+		Data:    order,
+	})
+}
+
+// GetReceipt implements POSController.
+// GET /pos/orders/:id/receipt returns the receipt as plain text, ready
+// for the receipt printer.
+func (p *posControllerImpl) GetReceipt(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid order ID: " + err.Error(),
+		})
+	}
+
+	receipt, err := p.POSService.GetReceipt(uint(id))
+	if err != nil {
+		return posError(c, err)
+	}
+
+	return c.String(http.StatusOK, receipt)
+}
+
+func posError(c echo.Context, err error) error {
+	var unavailable *services.UnavailableItemsError
+	var rejected *services.VoucherRejectedError
+	var rewardRejected *services.RewardRejectedError
+	switch {
+	case errors.As(err, &unavailable):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Some items cannot be ordered right now",
+			Data:    unavailable.Items,
+		})
+	case errors.As(err, &rejected):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Voucher cannot be used: " + rejected.Reason,
+		})
+	case errors.As(err, &rewardRejected):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Reward cannot be used: " + rewardRejected.Reason,
+		})
+	case errors.Is(err, services.ErrInvalidOrder), errors.Is(err, services.ErrInvalidPayment):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrNoOpenShift):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Order not found",
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle POS request: " + err.Error(),
+		})
+	}
+}
+
+func NewPOSController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) POSController {
+	return &posControllerImpl{
+		POSService: services.NewPOSService(newOrderService(db, cfg, rdb), repositories.NewOrderRepository(db), repositories.NewShiftRepository(db), repositories.NewUserRepository(db), repositories.NewLoyaltyRepository(db), cfg.Receipt, cfg.App.Location()),
+	}
+}
@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type PromotionController interface {
+	GetAllPromotions(c echo.Context) error
+	GetPromotionByID(c echo.Context) error
+	CreatePromotion(c echo.Context) error
+	UpdatePromotion(c echo.Context) error
+	DeletePromotion(c echo.Context) error
+}
+
+type promotionControllerImpl struct {
+	PromotionService services.PromotionService
+}
+
+// GetAllPromotions implements PromotionController.
+func (p *promotionControllerImpl) GetAllPromotions(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	promotions, err := p.PromotionService.GetAllPromotions()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get promotions: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Promotions retrieved successfully",
+		Data:    promotions,
+	})
+}
+
+// GetPromotionByID implements PromotionController.
+func (p *promotionControllerImpl) GetPromotionByID(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid promotion ID: " + err.Error(),
+		})
+	}
+
+	promotion, err := p.PromotionService.GetPromotionByID(uint(id))
+	if err != nil {
+		return promotionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Promotion retrieved successfully",
+		Data:    promotion,
+	})
+}
+
+// CreatePromotion implements PromotionController.
+func (p *promotionControllerImpl) CreatePromotion(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	payload := new(dto.PromotionRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	promotion, err := p.PromotionService.CreatePromotion(*payload)
+	if err != nil {
+		return promotionError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Promotion created successfully",
+		Data:    promotion,
+	})
+}
+
+// UpdatePromotion implements PromotionController.
+func (p *promotionControllerImpl) UpdatePromotion(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid promotion ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.PromotionRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	promotion, err := p.PromotionService.UpdatePromotion(uint(id), *payload)
+	if err != nil {
+		return promotionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Promotion updated successfully",
+		Data:    promotion,
+	})
+}
+
+// DeletePromotion implements PromotionController.
+func (p *promotionControllerImpl) DeletePromotion(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid promotion ID: " + err.Error(),
+		})
+	}
+
+	if err := p.PromotionService.DeletePromotion(uint(id)); err != nil {
+		return promotionError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Promotion deleted successfully",
+	})
+}
+
+func promotionError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidPromotion):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Promotion not found",
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle promotion: " + err.Error(),
+		})
+	}
+}
+
+func NewPromotionController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) PromotionController {
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	return &promotionControllerImpl{
+		PromotionService: services.NewPromotionService(repositories.NewPromotionRepository(db), categoryRepo, cfg.App.Location()),
+	}
+}
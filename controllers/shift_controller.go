@@ -0,0 +1,300 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"gorm.io/gorm"
+)
+
+type ShiftController interface {
+	OpenShift(c echo.Context) error
+	GetCurrentShift(c echo.Context) error
+	RecordMovement(c echo.Context) error
+	CloseShift(c echo.Context) error
+	GetShiftReport(c echo.Context) error
+	GetShifts(c echo.Context) error
+}
+
+type shiftControllerImpl struct {
+	ShiftService services.ShiftService
+	Location     *time.Location
+}
+
+// OpenShift implements ShiftController.
+// POST /shifts/open opens a shift for the cashier with the opening float
+// counted into the drawer.
+func (s *shiftControllerImpl) OpenShift(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	cashierID := c.Get("user_id").(uint)
+
+	payload := new(dto.OpenShiftRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	shift, err := s.ShiftService.OpenShift(cashierID, *payload)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Shift opened successfully",
+		Data:    shift,
+	})
+}
+
+// GetCurrentShift implements ShiftController.
+// GET /shifts/current returns the report so far of the cashier's open
+// shift, with the cash expected in the drawer.
+func (s *shiftControllerImpl) GetCurrentShift(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	cashierID := c.Get("user_id").(uint)
+
+	shift, err := s.ShiftService.GetCurrentShift(cashierID)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Shift retrieved successfully",
+		Data:    shift,
+	})
+}
+
+// RecordMovement implements ShiftController.
+// POST /shifts/current/movements records cash put into or taken out of
+// the drawer of the cashier's open shift.
+func (s *shiftControllerImpl) RecordMovement(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	cashierID := c.Get("user_id").(uint)
+
+	payload := new(dto.CashMovementRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	movement, err := s.ShiftService.RecordMovement(cashierID, *payload)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Cash movement recorded successfully",
+		Data:    movement,
+	})
+}
+
+// CloseShift implements ShiftController.
+// POST /shifts/current/close closes the cashier's open shift with the
+// cash counted in the drawer and returns its Z-report.
+func (s *shiftControllerImpl) CloseShift(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	cashierID := c.Get("user_id").(uint)
+
+	payload := new(dto.CloseShiftRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	report, err := s.ShiftService.CloseShift(cashierID, *payload)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	message := "Shift closed successfully"
+	if report.Flagged {
+		message = "Shift closed with a cash discrepancy"
+	}
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: message,
+		Data:    report,
+	})
+}
+
+// GetShiftReport implements ShiftController.
+// GET /shifts/:id/report returns the Z-report of a shift. Cashiers can
+// only get their own.
+func (s *shiftControllerImpl) GetShiftReport(c echo.Context) error {
+	// Check if user is staff
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" && userRole != "cashier" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Staff only",
+		})
+	}
+	userID := c.Get("user_id").(uint)
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid shift ID: " + err.Error(),
+		})
+	}
+
+	report, err := s.ShiftService.GetShiftReport(userID, userRole, uint(id))
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Shift report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// GetShifts implements ShiftController.
+// GET /shifts?date=YYYY-MM-DD returns the Z-reports of the shifts opened
+// on a day, today when date is omitted.
+func (s *shiftControllerImpl) GetShifts(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	day := time.Now().In(s.Location)
+	if date := c.QueryParam("date"); date != "" {
+		parsed, err := time.ParseInLocation(time.DateOnly, date, s.Location)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid date, expected YYYY-MM-DD",
+			})
+		}
+		day = parsed
+	}
+
+	reports, err := s.ShiftService.GetShifts(day)
+	if err != nil {
+		return shiftError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Shifts retrieved successfully",
+		Data:    reports,
+	})
+}
+
+func shiftError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Shift not found",
+		})
+	case errors.Is(err, services.ErrInvalidShift):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, services.ErrNoOpenShift), errors.Is(err, repositories.ErrShiftAlreadyOpen), errors.Is(err, repositories.ErrShiftNotOpen):
+		return c.JSON(http.StatusConflict, dto.ApiResponse{
+			Status:  http.StatusConflict,
+			Message: err.Error(),
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle shift request: " + err.Error(),
+		})
+	}
+}
+
+func NewShiftController(db *gorm.DB, cfg *config.Config) ShiftController {
+	return &shiftControllerImpl{
+		ShiftService: services.NewShiftService(repositories.NewShiftRepository(db), cfg.Shift),
+		Location:     cfg.App.Location(),
+	}
+}
@@ -11,10 +11,10 @@ import (
 	"log"
 	"net/http"
 	"strings"
-	"time"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/gorm"
 )
 
@@ -31,10 +31,16 @@ type UserController interface {
 
 type UserControllerImpl struct {
 	UserService services.UserService
+	cfg         *config.Config
+	rdb         redis.UniversalClient
 }
 
 var ctx = context.Background()
 
+// Tokens live in Redis, so every Redis failure in this controller is
+// fail-closed: the request is refused with 503 instead of issuing or
+// accepting a token that cannot be tracked.
+
 // Define user-related controller methods here
 func (u *UserControllerImpl) Register(c echo.Context) error {
 	userPayload := new(dto.RegisterRequest)
@@ -99,28 +105,19 @@ func (u *UserControllerImpl) Login(c echo.Context) error {
 	}
 	fmt.Println(result)
 
-	// Store the token in Redis with an expiration time
-	rdb, err := config.RedisClient()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to connect to Redis: " + err.Error(),
-		})
-	}
-
 	// Store Access Token and Refresh Token in Redis
-	err = rdb.Set(ctx, userPayload.Email, result.AccessToken, time.Minute*15).Err()
+	err = u.rdb.Set(ctx, userPayload.Email, result.AccessToken, u.cfg.JWT.AccessTTL).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to Store Access Token to Redis: " + err.Error(),
 		})
 	}
 
-	err = rdb.Set(ctx, "refresh:"+userPayload.Email, result.RefresherToken, time.Hour*24).Err()
+	err = u.rdb.Set(ctx, "refresh:"+userPayload.Email, result.RefresherToken, u.cfg.JWT.RefreshTTL).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to Store Refresh Token to Redis: " + err.Error(),
 		})
 	}
@@ -142,29 +139,20 @@ func (u *UserControllerImpl) Logout(c echo.Context) error {
 		})
 	}
 
-	// Delete tokens from Redis
-	rdb, err := config.RedisClient()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to connect to Redis: " + err.Error(),
-		})
-	}
-
 	// Delete Access Token from Redis
-	err = rdb.Del(ctx, userEmail).Err()
+	err := u.rdb.Del(ctx, userEmail).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to logout: " + err.Error(),
 		})
 	}
 
 	// Delete Refresh Token from Redis
-	err = rdb.Del(ctx, "refresh:"+userEmail).Err()
+	err = u.rdb.Del(ctx, "refresh:"+userEmail).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to logout: " + err.Error(),
 		})
 	}
@@ -299,18 +287,9 @@ func (u *UserControllerImpl) RefreshToken(c echo.Context) error {
 		})
 	}
 
-	// Connect to Redis
-	rdb, err := config.RedisClient()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to connect to Redis: " + err.Error(),
-		})
-	}
-
 	// Parse refresh token
 	token, err := jwt.Parse(req.RefresherToken, func(token *jwt.Token) (interface{}, error) {
-		return utils.GetSecretKey(), nil
+		return utils.GetSecretKey(u.cfg.JWT), nil
 	})
 	if err != nil || !token.Valid {
 		return c.JSON(http.StatusUnauthorized, echo.Map{"message": "invalid refresh token"})
@@ -322,10 +301,10 @@ func (u *UserControllerImpl) RefreshToken(c echo.Context) error {
 	role, _ := claims["role"].(string)
 
 	// Check if refresh token exists in Redis
-	exist, err := rdb.Exists(ctx, "refresh:"+email).Result()
+	exist, err := u.rdb.Exists(ctx, "refresh:"+email).Result()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to check refresh token in Redis: " + err.Error(),
 		})
 	}
@@ -337,22 +316,22 @@ func (u *UserControllerImpl) RefreshToken(c echo.Context) error {
 	}
 
 	// Delete old access token from Redis
-	err = rdb.Del(ctx, email).Err()
+	err = u.rdb.Del(ctx, email).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to logout: " + err.Error(),
 		})
 	}
 
 	// Generate new access token
-	newAccessToken, _ := utils.GenerateJWT(uint(userID), email, role)
+	newAccessToken, _ := utils.GenerateJWT(u.cfg.JWT, uint(userID), email, role)
 
 	// Store new access token in Redis
-	err = rdb.Set(ctx, email, newAccessToken, time.Minute*15).Err()
+	err = u.rdb.Set(ctx, email, newAccessToken, u.cfg.JWT.AccessTTL).Err()
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
+		return c.JSON(http.StatusServiceUnavailable, dto.ApiResponse{
+			Status:  http.StatusServiceUnavailable,
 			Message: "Failed to Store Access Token to Redis: " + err.Error(),
 		})
 	}
@@ -365,9 +344,11 @@ func (u *UserControllerImpl) RefreshToken(c echo.Context) error {
 	return c.JSON(http.StatusOK, apiResponse)
 }
 
-func NewUserController(db *gorm.DB) UserControllerImpl {
-	service := services.NewUserService(db)
+func NewUserController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) UserControllerImpl {
+	service := services.NewUserService(db, cfg)
 	return UserControllerImpl{
 		UserService: service,
+		cfg:         cfg,
+		rdb:         rdb,
 	}
 }
@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/repositories"
+	"coffee_shop/services"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+type VoucherController interface {
+	GetAllVouchers(c echo.Context) error
+	GetVoucherByID(c echo.Context) error
+	CreateVoucher(c echo.Context) error
+	UpdateVoucher(c echo.Context) error
+	DeleteVoucher(c echo.Context) error
+	ValidateVoucher(c echo.Context) error
+}
+
+type voucherControllerImpl struct {
+	VoucherService services.VoucherService
+	OrderService   services.OrderService
+}
+
+// GetAllVouchers implements VoucherController.
+func (v *voucherControllerImpl) GetAllVouchers(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	vouchers, err := v.VoucherService.GetAllVouchers()
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to get vouchers: " + err.Error(),
+		})
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Vouchers retrieved successfully",
+		Data:    vouchers,
+	})
+}
+
+// GetVoucherByID implements VoucherController.
+func (v *voucherControllerImpl) GetVoucherByID(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid voucher ID: " + err.Error(),
+		})
+	}
+
+	voucher, err := v.VoucherService.GetVoucherByID(uint(id))
+	if err != nil {
+		return voucherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Voucher retrieved successfully",
+		Data:    voucher,
+	})
+}
+
+// CreateVoucher implements VoucherController.
+func (v *voucherControllerImpl) CreateVoucher(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	payload := new(dto.VoucherRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	voucher, err := v.VoucherService.CreateVoucher(*payload)
+	if err != nil {
+		return voucherError(c, err)
+	}
+
+	return c.JSON(http.StatusCreated, dto.ApiResponse{
+		Status:  http.StatusCreated,
+		Message: "Voucher created successfully",
+		Data:    voucher,
+	})
+}
+
+// UpdateVoucher implements VoucherController.
+func (v *voucherControllerImpl) UpdateVoucher(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid voucher ID: " + err.Error(),
+		})
+	}
+
+	payload := new(dto.VoucherRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+
+	voucher, err := v.VoucherService.UpdateVoucher(uint(id), *payload)
+	if err != nil {
+		return voucherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Voucher updated successfully",
+		Data:    voucher,
+	})
+}
+
+// DeleteVoucher implements VoucherController.
+func (v *voucherControllerImpl) DeleteVoucher(c echo.Context) error {
+	// Check if user is admin
+	userRole, ok := c.Get("role").(string)
+	if !ok {
+		return c.JSON(http.StatusUnauthorized, dto.ApiResponse{
+			Status:  http.StatusUnauthorized,
+			Message: "Unauthorized",
+		})
+	}
+	if userRole != "admin" {
+		return c.JSON(http.StatusForbidden, dto.ApiResponse{
+			Status:  http.StatusForbidden,
+			Message: "Forbidden: Admins only",
+		})
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid voucher ID: " + err.Error(),
+		})
+	}
+
+	if err := v.VoucherService.DeleteVoucher(uint(id)); err != nil {
+		return voucherError(c, err)
+	}
+
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Voucher deleted successfully",
+	})
+}
+
+// ValidateVoucher implements VoucherController.
+// POST /vouchers/validate prices the cart with the code for the current
+// user without placing an order. A code that cannot be used is not an
+// error: the response says why and prices the cart without it.
+func (v *voucherControllerImpl) ValidateVoucher(c echo.Context) error {
+	// Get user_id from JWT token
+	userID := c.Get("user_id").(uint)
+
+	payload := new(dto.VoucherValidateRequest)
+	if err := c.Bind(payload); err != nil {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request payload: " + err.Error(),
+		})
+	}
+	if strings.TrimSpace(payload.Code) == "" {
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: "Voucher code is required",
+		})
+	}
+
+	validation := dto.VoucherValidationResponse{Code: services.NormalizeVoucherCode(payload.Code), Valid: true}
+	request := dto.OrderRequest{Items: payload.Items, VoucherCode: payload.Code}
+	order, err := v.OrderService.PreviewOrder(userID, request)
+	var rejected *services.VoucherRejectedError
+	if errors.As(err, &rejected) {
+		validation.Valid = false
+		validation.Reason = rejected.Reason
+		request.VoucherCode = ""
+		order, err = v.OrderService.PreviewOrder(userID, request)
+	}
+
+	var unavailable *services.UnavailableItemsError
+	switch {
+	case errors.As(err, &unavailable):
+		return c.JSON(http.StatusUnprocessableEntity, dto.ApiResponse{
+			Status:  http.StatusUnprocessableEntity,
+			Message: "Some items cannot be ordered right now",
+			Data:    unavailable.Items,
+		})
+	case errors.Is(err, services.ErrInvalidOrder):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case err != nil:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to validate voucher: " + err.Error(),
+		})
+	}
+
+	validation.Discount = order.VoucherDiscount
+	validation.Order = order
+	return c.JSON(http.StatusOK, dto.ApiResponse{
+		Status:  http.StatusOK,
+		Message: "Voucher validated",
+		Data:    validation,
+	})
+}
+
+func voucherError(c echo.Context, err error) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidVoucher):
+		return c.JSON(http.StatusBadRequest, dto.ApiResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return c.JSON(http.StatusNotFound, dto.ApiResponse{
+			Status:  http.StatusNotFound,
+			Message: "Voucher not found",
+		})
+	default:
+		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to handle voucher: " + err.Error(),
+		})
+	}
+}
+
+func NewVoucherController(db *gorm.DB, cfg *config.Config, rdb redis.UniversalClient) VoucherController {
+	categoryRepo := repositories.NewCachedCategoryRepository(repositories.NewCategoryRepository(db), rdb, cfg.Cache.TTL)
+	return &voucherControllerImpl{
+		VoucherService: services.NewVoucherService(repositories.NewVoucherRepository(db), repositories.NewOrderRepository(db), categoryRepo),
+		OrderService:   newOrderService(db, cfg, rdb),
+	}
+}
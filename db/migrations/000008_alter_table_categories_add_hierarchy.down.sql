@@ -0,0 +1,6 @@
+ALTER TABLE categories
+DROP FOREIGN KEY FK_CategoryParent;
+ALTER TABLE categories
+DROP INDEX idx_categories_parent_sort,
+DROP COLUMN sort_order,
+DROP COLUMN parent_id;
\ No newline at end of file
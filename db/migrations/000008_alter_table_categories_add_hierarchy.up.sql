@@ -0,0 +1,6 @@
+ALTER TABLE categories
+ADD COLUMN parent_id INT NULL,
+ADD COLUMN sort_order INT NOT NULL DEFAULT 0,
+ADD INDEX idx_categories_parent_sort (parent_id, sort_order),
+ADD CONSTRAINT FK_CategoryParent
+FOREIGN KEY (parent_id) REFERENCES categories(id);
\ No newline at end of file
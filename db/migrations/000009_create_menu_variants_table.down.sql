@@ -0,0 +1 @@
+DROP TABLE menu_variants;
\ No newline at end of file
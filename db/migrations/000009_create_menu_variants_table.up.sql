@@ -0,0 +1,11 @@
+CREATE TABLE menu_variants (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    menu_id INT NOT NULL,
+    variant_name VARCHAR(100) NOT NULL,
+    price_delta FLOAT NOT NULL DEFAULT 0,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_MenuVariantMenu FOREIGN KEY (menu_id) REFERENCES menu(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
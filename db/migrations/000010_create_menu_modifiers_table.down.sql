@@ -0,0 +1 @@
+DROP TABLE menu_modifiers;
\ No newline at end of file
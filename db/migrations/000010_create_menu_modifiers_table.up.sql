@@ -0,0 +1,11 @@
+CREATE TABLE menu_modifiers (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    menu_id INT NOT NULL,
+    modifier_name VARCHAR(100) NOT NULL,
+    price FLOAT NOT NULL DEFAULT 0,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_MenuModifierMenu FOREIGN KEY (menu_id) REFERENCES menu(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
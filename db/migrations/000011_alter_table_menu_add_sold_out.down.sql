@@ -0,0 +1,2 @@
+ALTER TABLE menu
+DROP COLUMN sold_out;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE menu
+ADD COLUMN sold_out TINYINT(1) NOT NULL DEFAULT 0;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE availability_windows;
\ No newline at end of file
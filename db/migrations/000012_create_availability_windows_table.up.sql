@@ -0,0 +1,16 @@
+CREATE TABLE availability_windows (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    menu_id INT NULL,
+    category_id INT NULL,
+    days_of_week INT NOT NULL DEFAULT 127,
+    start_time VARCHAR(5) NOT NULL DEFAULT '',
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    start_date VARCHAR(10) NOT NULL DEFAULT '',
+    end_date VARCHAR(10) NOT NULL DEFAULT '',
+    timezone VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_AvailabilityMenu FOREIGN KEY (menu_id) REFERENCES menu(id) ON DELETE CASCADE,
+    CONSTRAINT FK_AvailabilityCategory FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE promotions;
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE promotions (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    type enum('percentage', 'fixed', 'buy_x_get_y') NOT NULL,
+    value FLOAT NOT NULL DEFAULT 0,
+    buy_quantity INT NOT NULL DEFAULT 0,
+    get_quantity INT NOT NULL DEFAULT 0,
+    menu_id INT NULL,
+    category_id INT NULL,
+    min_spend FLOAT NOT NULL DEFAULT 0,
+    days_of_week INT NOT NULL DEFAULT 127,
+    start_time VARCHAR(5) NOT NULL DEFAULT '',
+    end_time VARCHAR(5) NOT NULL DEFAULT '',
+    start_date VARCHAR(10) NOT NULL DEFAULT '',
+    end_date VARCHAR(10) NOT NULL DEFAULT '',
+    priority INT NOT NULL DEFAULT 0,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_PromotionMenu FOREIGN KEY (menu_id) REFERENCES menu(id) ON DELETE CASCADE,
+    CONSTRAINT FK_PromotionCategory FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+DROP FOREIGN KEY FK_OrderPromotion;
+ALTER TABLE orders
+DROP COLUMN promotion_id,
+DROP COLUMN discount;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+ADD COLUMN discount FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN promotion_id INT NULL,
+ADD CONSTRAINT FK_OrderPromotion FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE order_menu_items
+DROP FOREIGN KEY FK_OrderItemPromotion;
+ALTER TABLE order_menu_items
+DROP COLUMN promotion_id;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE order_menu_items
+ADD COLUMN promotion_id INT NULL,
+ADD CONSTRAINT FK_OrderItemPromotion FOREIGN KEY (promotion_id) REFERENCES promotions(id) ON DELETE SET NULL;
\ No newline at end of file
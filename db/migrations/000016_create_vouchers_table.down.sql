@@ -0,0 +1 @@
+DROP TABLE vouchers;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE vouchers (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    code VARCHAR(50) NOT NULL,
+    description TEXT,
+    type enum('percentage', 'fixed') NOT NULL,
+    value FLOAT NOT NULL DEFAULT 0,
+    max_discount FLOAT NOT NULL DEFAULT 0,
+    min_spend FLOAT NOT NULL DEFAULT 0,
+    valid_from TIMESTAMP NULL DEFAULT NULL,
+    valid_until TIMESTAMP NULL DEFAULT NULL,
+    max_redemptions INT NOT NULL DEFAULT 0,
+    max_per_user INT NOT NULL DEFAULT 0,
+    redemption_count INT NOT NULL DEFAULT 0,
+    first_order_only TINYINT(1) NOT NULL DEFAULT 0,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    UNIQUE INDEX idx_vouchers_code (code)
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
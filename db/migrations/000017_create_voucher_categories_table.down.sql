@@ -0,0 +1 @@
+DROP TABLE voucher_categories;
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE voucher_categories (
+    voucher_id INT NOT NULL,
+    category_id INT NOT NULL,
+    PRIMARY KEY (voucher_id, category_id),
+    CONSTRAINT FK_VoucherCategoryVoucher FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE CASCADE,
+    CONSTRAINT FK_VoucherCategoryCategory FOREIGN KEY (category_id) REFERENCES categories(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE voucher_redemptions;
\ No newline at end of file
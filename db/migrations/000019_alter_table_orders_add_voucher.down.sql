@@ -0,0 +1,5 @@
+ALTER TABLE orders
+DROP FOREIGN KEY FK_OrderVoucher;
+ALTER TABLE orders
+DROP COLUMN voucher_discount,
+DROP COLUMN voucher_id;
\ No newline at end of file
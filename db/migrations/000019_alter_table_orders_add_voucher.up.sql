@@ -0,0 +1,4 @@
+ALTER TABLE orders
+ADD COLUMN voucher_id INT NULL,
+ADD COLUMN voucher_discount FLOAT NOT NULL DEFAULT 0,
+ADD CONSTRAINT FK_OrderVoucher FOREIGN KEY (voucher_id) REFERENCES vouchers(id) ON DELETE SET NULL;
\ No newline at end of file
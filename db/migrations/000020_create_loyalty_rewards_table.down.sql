@@ -0,0 +1 @@
+DROP TABLE loyalty_rewards;
\ No newline at end of file
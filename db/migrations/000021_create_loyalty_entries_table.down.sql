@@ -0,0 +1 @@
+DROP TABLE loyalty_entries;
\ No newline at end of file
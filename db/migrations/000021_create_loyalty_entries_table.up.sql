@@ -0,0 +1,21 @@
+CREATE TABLE loyalty_entries (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    user_id INT NOT NULL,
+    type enum('earn', 'redeem', 'adjust', 'expire') NOT NULL,
+    points INT NOT NULL,
+    remaining INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NULL DEFAULT NULL,
+    order_id INT NULL,
+    reward_id INT NULL,
+    adjusted_by INT NULL,
+    note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_loyalty_entries_user (user_id, created_at),
+    INDEX idx_loyalty_entries_expiry (remaining, expires_at),
+    CONSTRAINT FK_LoyaltyEntryUser FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    CONSTRAINT FK_LoyaltyEntryOrder FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE SET NULL,
+    CONSTRAINT FK_LoyaltyEntryReward FOREIGN KEY (reward_id) REFERENCES loyalty_rewards(id) ON DELETE SET NULL,
+    CONSTRAINT FK_LoyaltyEntryAdmin FOREIGN KEY (adjusted_by) REFERENCES users(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE orders
+DROP FOREIGN KEY FK_OrderLoyaltyReward;
+ALTER TABLE orders
+DROP COLUMN loyalty_discount,
+DROP COLUMN loyalty_reward_id;
\ No newline at end of file
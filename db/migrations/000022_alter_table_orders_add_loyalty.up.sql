@@ -0,0 +1,4 @@
+ALTER TABLE orders
+ADD COLUMN loyalty_reward_id INT NULL,
+ADD COLUMN loyalty_discount FLOAT NOT NULL DEFAULT 0,
+ADD CONSTRAINT FK_OrderLoyaltyReward FOREIGN KEY (loyalty_reward_id) REFERENCES loyalty_rewards(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE tax_rates;
\ No newline at end of file
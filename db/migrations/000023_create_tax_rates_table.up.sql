@@ -0,0 +1,10 @@
+CREATE TABLE tax_rates (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    rate FLOAT NOT NULL,
+    inclusive TINYINT(1) NOT NULL DEFAULT 0,
+    is_default TINYINT(1) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
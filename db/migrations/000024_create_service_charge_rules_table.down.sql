@@ -0,0 +1 @@
+DROP TABLE service_charge_rules;
\ No newline at end of file
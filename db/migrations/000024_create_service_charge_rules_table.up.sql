@@ -0,0 +1,11 @@
+CREATE TABLE service_charge_rules (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    name VARCHAR(100) NOT NULL,
+    order_type enum('takeaway', 'dine_in') NOT NULL,
+    rate FLOAT NOT NULL,
+    taxable TINYINT(1) NOT NULL DEFAULT 0,
+    active TINYINT(1) NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE categories
+DROP FOREIGN KEY FK_CategoryTaxRate;
+ALTER TABLE categories
+DROP COLUMN tax_rate_id;
\ No newline at end of file
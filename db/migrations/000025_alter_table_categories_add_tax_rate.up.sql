@@ -0,0 +1,3 @@
+ALTER TABLE categories
+ADD COLUMN tax_rate_id INT NULL,
+ADD CONSTRAINT FK_CategoryTaxRate FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(id) ON DELETE SET NULL;
\ No newline at end of file
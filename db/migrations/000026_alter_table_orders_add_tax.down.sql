@@ -0,0 +1,8 @@
+ALTER TABLE orders
+DROP COLUMN grand_total,
+DROP COLUMN rounding,
+DROP COLUMN service_charge,
+DROP COLUMN tax,
+DROP COLUMN discount_total,
+DROP COLUMN subtotal,
+DROP COLUMN order_type;
\ No newline at end of file
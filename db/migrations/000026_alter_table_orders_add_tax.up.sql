@@ -0,0 +1,16 @@
+ALTER TABLE orders
+ADD COLUMN order_type enum('takeaway', 'dine_in') NOT NULL DEFAULT 'takeaway',
+ADD COLUMN subtotal FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN discount_total FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN tax FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN service_charge FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN rounding FLOAT NOT NULL DEFAULT 0,
+ADD COLUMN grand_total FLOAT NOT NULL DEFAULT 0;
+UPDATE orders
+SET subtotal = (
+    SELECT COALESCE(SUM(i.price * i.quantity), 0) FROM order_menu_items i
+    WHERE i.order_id = orders.id AND i.deleted_at IS NULL
+),
+grand_total = total_price;
+UPDATE orders
+SET discount_total = GREATEST(subtotal - total_price, 0);
\ No newline at end of file
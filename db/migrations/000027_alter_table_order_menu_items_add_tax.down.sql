@@ -0,0 +1,5 @@
+ALTER TABLE order_menu_items
+DROP FOREIGN KEY FK_OrderItemTaxRate;
+ALTER TABLE order_menu_items
+DROP COLUMN tax,
+DROP COLUMN tax_rate_id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE order_menu_items
+ADD COLUMN tax_rate_id INT NULL,
+ADD COLUMN tax FLOAT NOT NULL DEFAULT 0,
+ADD CONSTRAINT FK_OrderItemTaxRate FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(id) ON DELETE SET NULL;
\ No newline at end of file
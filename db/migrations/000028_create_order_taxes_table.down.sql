@@ -0,0 +1 @@
+DROP TABLE order_taxes;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE order_taxes (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    order_id INT NOT NULL,
+    tax_rate_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    rate FLOAT NOT NULL,
+    inclusive TINYINT(1) NOT NULL DEFAULT 0,
+    taxable_amount FLOAT NOT NULL DEFAULT 0,
+    amount FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_OrderTaxOrder FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT FK_OrderTaxRate FOREIGN KEY (tax_rate_id) REFERENCES tax_rates(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE order_menu_items
+DROP FOREIGN KEY FK_OrderItemVariant;
+ALTER TABLE order_menu_items
+DROP COLUMN variant_name,
+DROP COLUMN variant_id;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE order_menu_items
+ADD COLUMN variant_id INT NULL,
+ADD COLUMN variant_name VARCHAR(100) NOT NULL DEFAULT '',
+ADD CONSTRAINT FK_OrderItemVariant FOREIGN KEY (variant_id) REFERENCES menu_variants(id) ON DELETE SET NULL;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE order_item_modifiers;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE order_item_modifiers (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    order_menu_item_id INT NOT NULL,
+    modifier_id INT NULL,
+    name VARCHAR(100) NOT NULL,
+    price FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_OrderItemModifierItem FOREIGN KEY (order_menu_item_id) REFERENCES order_menu_items(id) ON DELETE CASCADE,
+    CONSTRAINT FK_OrderItemModifierModifier FOREIGN KEY (modifier_id) REFERENCES menu_modifiers(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,4 @@
+UPDATE orders SET status = 'pending' WHERE status = 'preparing';
+ALTER TABLE orders
+MODIFY COLUMN status enum('pending', 'completed', 'canceled') DEFAULT 'pending',
+DROP COLUMN canceled_at;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE orders
+MODIFY COLUMN status enum('pending', 'preparing', 'completed', 'canceled') DEFAULT 'pending',
+ADD COLUMN canceled_at TIMESTAMP NULL DEFAULT NULL;
\ No newline at end of file
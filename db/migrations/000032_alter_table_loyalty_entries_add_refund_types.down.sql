@@ -0,0 +1,3 @@
+DELETE FROM loyalty_entries WHERE type IN ('restore', 'reverse');
+ALTER TABLE loyalty_entries
+MODIFY COLUMN type enum('earn', 'redeem', 'adjust', 'expire') NOT NULL;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE loyalty_entries
+MODIFY COLUMN type enum('earn', 'redeem', 'adjust', 'expire', 'restore', 'reverse') NOT NULL;
\ No newline at end of file
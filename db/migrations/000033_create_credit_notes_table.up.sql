@@ -0,0 +1,34 @@
+CREATE TABLE credit_notes (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    order_id INT NOT NULL,
+    type enum('cancellation', 'refund') NOT NULL,
+    reason enum('customer_request', 'wrong_order', 'quality_issue', 'out_of_stock', 'duplicate_order', 'other') NOT NULL,
+    note TEXT,
+    amount FLOAT NOT NULL DEFAULT 0,
+    revenue FLOAT NOT NULL DEFAULT 0,
+    refund_method enum('cash', 'card', 'ewallet') NOT NULL,
+    refund_status enum('pending', 'refunded', 'failed') NOT NULL DEFAULT 'pending',
+    refund_reference VARCHAR(100),
+    issued_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_credit_notes_created (created_at),
+    CONSTRAINT FK_CreditNoteOrder FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE,
+    CONSTRAINT FK_CreditNoteIssuer FOREIGN KEY (issued_by) REFERENCES users(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE TABLE credit_note_items (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    credit_note_id INT NOT NULL,
+    order_menu_item_id INT NOT NULL,
+    menu_id INT NULL,
+    quantity INT NOT NULL,
+    amount FLOAT NOT NULL DEFAULT 0,
+    revenue FLOAT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_CreditNoteItemNote FOREIGN KEY (credit_note_id) REFERENCES credit_notes(id) ON DELETE CASCADE,
+    CONSTRAINT FK_CreditNoteItemLine FOREIGN KEY (order_menu_item_id) REFERENCES order_menu_items(id) ON DELETE CASCADE,
+    CONSTRAINT FK_CreditNoteItemMenu FOREIGN KEY (menu_id) REFERENCES menu(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE orders
+DROP FOREIGN KEY FK_OrderCashier;
+ALTER TABLE orders
+DROP COLUMN cashier_id;
\ No newline at end of file
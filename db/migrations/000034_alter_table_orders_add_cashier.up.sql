@@ -0,0 +1,3 @@
+ALTER TABLE orders
+ADD COLUMN cashier_id INT NULL,
+ADD CONSTRAINT FK_OrderCashier FOREIGN KEY (cashier_id) REFERENCES users(id) ON DELETE SET NULL;
\ No newline at end of file
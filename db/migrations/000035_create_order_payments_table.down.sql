@@ -0,0 +1 @@
+DROP TABLE order_payments;
\ No newline at end of file
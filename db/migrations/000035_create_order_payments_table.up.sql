@@ -0,0 +1,13 @@
+CREATE TABLE order_payments (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    order_id INT NOT NULL,
+    method enum('cash', 'card', 'ewallet') NOT NULL,
+    amount FLOAT NOT NULL,
+    tendered FLOAT NOT NULL DEFAULT 0,
+    change_due FLOAT NOT NULL DEFAULT 0,
+    reference VARCHAR(100),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_OrderPaymentOrder FOREIGN KEY (order_id) REFERENCES orders(id) ON DELETE CASCADE
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,2 @@
+DROP TABLE shift_cash_movements;
+DROP TABLE shifts;
\ No newline at end of file
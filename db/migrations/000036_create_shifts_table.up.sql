@@ -0,0 +1,31 @@
+CREATE TABLE shifts (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    cashier_id INT NOT NULL,
+    status enum('open', 'closed') NOT NULL DEFAULT 'open',
+    opening_float FLOAT NOT NULL DEFAULT 0,
+    opened_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_at TIMESTAMP NULL DEFAULT NULL,
+    expected_cash FLOAT NULL,
+    counted_cash FLOAT NULL,
+    discrepancy FLOAT NULL,
+    closing_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    INDEX idx_shifts_cashier_status (cashier_id, status),
+    INDEX idx_shifts_opened (opened_at),
+    CONSTRAINT FK_ShiftCashier FOREIGN KEY (cashier_id) REFERENCES users(id)
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
+CREATE TABLE shift_cash_movements (
+    id INT PRIMARY KEY NOT NULL AUTO_INCREMENT,
+    shift_id INT NOT NULL,
+    type enum('cash_in', 'paid_out', 'drop') NOT NULL,
+    amount FLOAT NOT NULL,
+    note TEXT,
+    recorded_by INT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL DEFAULT NULL,
+    CONSTRAINT FK_ShiftCashMovementShift FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE CASCADE,
+    CONSTRAINT FK_ShiftCashMovementUser FOREIGN KEY (recorded_by) REFERENCES users(id) ON DELETE SET NULL
+) Engine=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
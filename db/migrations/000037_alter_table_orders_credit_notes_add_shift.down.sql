@@ -0,0 +1,8 @@
+ALTER TABLE credit_notes
+DROP FOREIGN KEY FK_CreditNoteShift;
+ALTER TABLE credit_notes
+DROP COLUMN shift_id;
+ALTER TABLE orders
+DROP FOREIGN KEY FK_OrderShift;
+ALTER TABLE orders
+DROP COLUMN shift_id;
\ No newline at end of file
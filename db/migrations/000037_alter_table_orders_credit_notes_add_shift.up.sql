@@ -0,0 +1,6 @@
+ALTER TABLE orders
+ADD COLUMN shift_id INT NULL,
+ADD CONSTRAINT FK_OrderShift FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE SET NULL;
+ALTER TABLE credit_notes
+ADD COLUMN shift_id INT NULL,
+ADD CONSTRAINT FK_CreditNoteShift FOREIGN KEY (shift_id) REFERENCES shifts(id) ON DELETE SET NULL;
\ No newline at end of file
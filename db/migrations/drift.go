@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// CheckDrift compares the GORM models against the live schema and returns
+// one line per difference. It checks that every mapped column exists and
+// that NOT NULL and UNIQUE agree, and lists columns the models do not map.
+// Column types are not compared because MySQL reports them in a different
+// form than GORM declares them.
+func CheckDrift(db *gorm.DB, models ...interface{}) ([]string, error) {
+	var drift []string
+	migrator := db.Migrator()
+
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(model) {
+			drift = append(drift, fmt.Sprintf("%s: table is missing", table))
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, err
+		}
+		columns := make(map[string]gorm.ColumnType, len(columnTypes))
+		for _, column := range columnTypes {
+			columns[column.Name()] = column
+		}
+
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			column, ok := columns[field.DBName]
+			if !ok {
+				drift = append(drift, fmt.Sprintf("%s.%s: column is missing", table, field.DBName))
+				continue
+			}
+			delete(columns, field.DBName)
+
+			if field.PrimaryKey {
+				continue
+			}
+			if nullable, ok := column.Nullable(); ok && nullable == field.NotNull {
+				drift = append(drift, fmt.Sprintf("%s.%s: model NOT NULL=%t, database NOT NULL=%t", table, field.DBName, field.NotNull, !nullable))
+			}
+			if unique, ok := column.Unique(); ok && unique != field.Unique {
+				drift = append(drift, fmt.Sprintf("%s.%s: model UNIQUE=%t, database UNIQUE=%t", table, field.DBName, field.Unique, unique))
+			}
+		}
+
+		var unmapped []string
+		for name := range columns {
+			unmapped = append(unmapped, name)
+		}
+		sort.Strings(unmapped)
+		for _, name := range unmapped {
+			drift = append(drift, fmt.Sprintf("%s.%s: column is not mapped by the model", table, name))
+		}
+	}
+	return drift, nil
+}
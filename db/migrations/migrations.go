@@ -0,0 +1,20 @@
+package migrations
+
+import (
+	"embed"
+)
+
+// FS holds the SQL migrations compiled into the binary. File names follow
+// the golang-migrate convention: <version>_<title>.<up|down>.sql
+//
+//go:embed *.sql
+var FS embed.FS
+
+// LatestVersion returns the highest migration version shipped with this binary.
+func LatestVersion() (uint, error) {
+	versions, err := Versions()
+	if err != nil {
+		return 0, err
+	}
+	return versions[len(versions)-1], nil
+}
@@ -0,0 +1,135 @@
+package migrations
+
+import (
+	"coffee_shop/config"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/mysql"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+)
+
+// Migrator applies the embedded migrations with golang-migrate, so the
+// schema_migrations table stays compatible with the migrate CLI.
+type Migrator struct {
+	m *migrate.Migrate
+}
+
+type Status struct {
+	Version uint
+	Dirty   bool
+	Latest  uint
+	Pending []uint
+}
+
+func NewMigrator(cfg config.DBConfig) (*Migrator, error) {
+	src, err := iofs.New(FS, ".")
+	if err != nil {
+		return nil, errors.New("failed to read embedded migrations: " + err.Error())
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", src, "mysql://"+cfg.MigrateDSN())
+	if err != nil {
+		return nil, errors.New("failed to connect migrator: " + err.Error())
+	}
+	m.Log = migrateLogger{}
+	return &Migrator{m: m}, nil
+}
+
+// Up applies every pending migration. Having nothing to apply is not an error.
+func (mg *Migrator) Up() error {
+	err := mg.m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Down rolls back the given number of migrations.
+func (mg *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return errors.New("steps must be greater than 0")
+	}
+	err := mg.m.Steps(-steps)
+	if errors.Is(err, migrate.ErrNoChange) {
+		return nil
+	}
+	return err
+}
+
+// Force records the version without running any migration, used to clear
+// the dirty flag after a failed migration has been fixed by hand.
+func (mg *Migrator) Force(version int) error {
+	return mg.m.Force(version)
+}
+
+func (mg *Migrator) Status() (Status, error) {
+	versions, err := Versions()
+	if err != nil {
+		return Status{}, err
+	}
+
+	version, dirty, err := mg.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+
+	status := Status{
+		Version: version,
+		Dirty:   dirty,
+		Latest:  versions[len(versions)-1],
+	}
+	for _, v := range versions {
+		if v > version {
+			status.Pending = append(status.Pending, v)
+		}
+	}
+	return status, nil
+}
+
+func (mg *Migrator) Close() error {
+	srcErr, dbErr := mg.m.Close()
+	return errors.Join(srcErr, dbErr)
+}
+
+// Versions returns every migration version embedded in the binary, sorted.
+func Versions() ([]uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []uint
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		version, _, _ := strings.Cut(entry.Name(), "_")
+		n, err := strconv.ParseUint(version, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration file name %q", entry.Name())
+		}
+		versions = append(versions, uint(n))
+	}
+	if len(versions) == 0 {
+		return nil, errors.New("no migrations found")
+	}
+	slices.Sort(versions)
+	return versions, nil
+}
+
+type migrateLogger struct{}
+
+func (migrateLogger) Printf(format string, v ...interface{}) {
+	log.Default().Printf("migrate: "+format, v...)
+}
+
+func (migrateLogger) Verbose() bool {
+	return false
+}
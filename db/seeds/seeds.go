@@ -0,0 +1,90 @@
+package seeds
+
+import (
+	"embed"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FS holds the fixture profiles compiled into the binary, one file per
+// profile named <profile>.yaml or <profile>.json.
+//
+//go:embed *.yaml
+var FS embed.FS
+
+// Profiles lists the built-in fixture profiles.
+var Profiles = []string{"dev", "demo", "test"}
+
+type Fixture struct {
+	Categories []CategoryFixture `yaml:"categories" json:"categories"`
+	Menus      []MenuFixture     `yaml:"menus" json:"menus"`
+	Users      []UserFixture     `yaml:"users" json:"users"`
+	Orders     []OrderFixture    `yaml:"orders" json:"orders"`
+}
+
+type CategoryFixture struct {
+	Name string `yaml:"name" json:"name"`
+}
+
+type MenuFixture struct {
+	Name        string  `yaml:"name" json:"name"`
+	Price       float64 `yaml:"price" json:"price"`
+	Description string  `yaml:"description" json:"description"`
+	Category    string  `yaml:"category" json:"category"`
+	// Image is a path relative to the working directory, e.g. image/menu_img/x.jpg
+	Image string `yaml:"image" json:"image"`
+}
+
+type UserFixture struct {
+	Name     string `yaml:"name" json:"name"`
+	Email    string `yaml:"email" json:"email"`
+	Password string `yaml:"password" json:"password"`
+	Role     string `yaml:"role" json:"role"`
+	Phone    string `yaml:"phone" json:"phone"`
+}
+
+type OrderFixture struct {
+	// Ref identifies the order across runs so seeding stays idempotent
+	Ref    string             `yaml:"ref" json:"ref"`
+	User   string             `yaml:"user" json:"user"`
+	Status string             `yaml:"status" json:"status"`
+	Note   string             `yaml:"note" json:"note"`
+	Items  []OrderItemFixture `yaml:"items" json:"items"`
+}
+
+type OrderItemFixture struct {
+	Menu     string `yaml:"menu" json:"menu"`
+	Quantity int    `yaml:"quantity" json:"quantity"`
+}
+
+// Load reads a fixture profile. When dir is empty the embedded profiles
+// are used, otherwise <dir>/<profile>.yaml or <dir>/<profile>.json.
+// JSON files are parsed by the YAML decoder, JSON being a subset of YAML.
+func Load(profile string, dir string) (Fixture, error) {
+	var fsys fs.FS = FS
+	if dir != "" {
+		fsys = os.DirFS(dir)
+	}
+
+	var raw []byte
+	var err error
+	for _, ext := range []string{".yaml", ".yml", ".json"} {
+		raw, err = fs.ReadFile(fsys, profile+ext)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return Fixture{}, errors.New("fixture profile not found: " + filepath.Join(dir, profile))
+	}
+
+	var fixture Fixture
+	if err := yaml.Unmarshal(raw, &fixture); err != nil {
+		return Fixture{}, errors.New("failed to parse fixture profile " + profile + ": " + err.Error())
+	}
+	return fixture, nil
+}
@@ -0,0 +1,37 @@
+package dto
+
+// Day names accepted in AvailabilityWindowRequest.Days, indexed by
+// time.Weekday.
+var WeekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// AvailabilityWindowRequest describes one window. Every field is
+// optional: no days means every day, no times means all day and no dates
+// means all year. A window from 22:00 to 02:00 runs past midnight.
+type AvailabilityWindowRequest struct {
+	Days      []string `json:"days"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+	Timezone  string   `json:"timezone"`
+}
+
+// AvailabilityRequest replaces the whole schedule of a menu item or a
+// category. An empty list removes every restriction.
+type AvailabilityRequest struct {
+	Windows []AvailabilityWindowRequest `json:"windows"`
+}
+
+type AvailabilityWindowResponse struct {
+	ID        uint     `json:"id"`
+	Days      []string `json:"days"`
+	StartTime string   `json:"start_time"`
+	EndTime   string   `json:"end_time"`
+	StartDate string   `json:"start_date"`
+	EndDate   string   `json:"end_date"`
+	Timezone  string   `json:"timezone"`
+}
+
+type SoldOutRequest struct {
+	SoldOut bool `json:"sold_out"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// CartUpdateRequest changes the order details of a cart, omitted fields
+// are kept.
+type CartUpdateRequest struct {
+	OrderType *string `json:"order_type"`
+	Note      *string `json:"note"`
+}
+
+type CartVoucherRequest struct {
+	Code string `json:"code"`
+}
+
+// CartCheckoutRequest places the order of a cart. ExpectedTotal is the
+// grand total the customer was shown; when set, the order is refused if
+// the cart costs something else by now.
+type CartCheckoutRequest struct {
+	ExpectedTotal *float64 `json:"expected_total"`
+	RewardID      *uint    `json:"reward_id"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// CartItemResponse is one line of a cart. Unavailable tells why the line
+// cannot be ordered right now, such lines are left out of the pricing.
+type CartItemResponse struct {
+	LineID      int                `json:"line_id"`
+	MenuID      uint               `json:"menu_id"`
+	VariantID   *uint              `json:"variant_id"`
+	ModifierIDs []uint             `json:"modifier_ids"`
+	Quantity    int                `json:"quantity"`
+	Unavailable string             `json:"unavailable,omitempty"`
+	Priced      *OrderItemResponse `json:"priced,omitempty"`
+}
+
+// CartResponse is a cart with its live price breakdown. SessionID is set
+// for guests, who send it back in the X-Cart-Session header. Pricing is
+// nil while nothing in the cart can be ordered.
+type CartResponse struct {
+	SessionID    string             `json:"session_id,omitempty"`
+	OrderType    string             `json:"order_type"`
+	Note         string             `json:"note"`
+	VoucherCode  string             `json:"voucher_code"`
+	VoucherError string             `json:"voucher_error,omitempty"`
+	Items        []CartItemResponse `json:"items"`
+	Pricing      *OrderResponse     `json:"pricing"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
@@ -2,4 +2,32 @@ package dto
 
 type CategoryRequest struct {
 	CategoriesName string `json:"categories_name" binding:"required"`
+	ParentID       *uint  `json:"parent_id"`
+	SortOrder      int    `json:"sort_order"`
+	// TaxRateID is the tax class of the category
+	TaxRateID *uint `json:"tax_rate_id"`
+}
+
+// CategoryUpdateRequest changes a category. Only the fields that are sent
+// change, an omitted one keeps its stored value; a parent_id sent as null
+// moves the category to the top level.
+type CategoryUpdateRequest struct {
+	CategoriesName string         `json:"categories_name"`
+	ParentID       Optional[uint] `json:"parent_id"`
+	SortOrder      *int           `json:"sort_order"`
+	// TaxRateID is the tax class of the category
+	TaxRateID *uint `json:"tax_rate_id"`
+}
+
+// CategoryPosition places one category in the tree.
+type CategoryPosition struct {
+	ID        uint  `json:"id"`
+	ParentID  *uint `json:"parent_id"`
+	SortOrder int   `json:"sort_order"`
+}
+
+// CategoryReorderRequest is sent after a drag-and-drop. Only the moved
+// categories need to be listed, the others keep their position.
+type CategoryReorderRequest struct {
+	Categories []CategoryPosition `json:"categories"`
 }
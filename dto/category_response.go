@@ -1,15 +1,42 @@
 package dto
 
-import "coffee_shop/models"
+import (
+	"coffee_shop/models"
+	"time"
+)
 
 type CategoryResponse struct {
 	ID           uint   `json:"id"`
 	CategoryName string `json:"categories_name"`
+	ParentID     *uint  `json:"parent_id"`
+	SortOrder    int    `json:"sort_order"`
+	TaxRateID    *uint  `json:"tax_rate_id"`
+	// ItemCount counts the menu items directly in the category
+	ItemCount int64 `json:"item_count"`
+	// TotalItemCount also counts the items of every subcategory, it is
+	// only filled in the tree
+	TotalItemCount int64              `json:"total_item_count,omitempty"`
+	Children       []CategoryResponse `json:"children,omitempty"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
 }
 
 func ToCategoryResponse(category *models.Category) CategoryResponse {
 	return CategoryResponse{
 		ID:           category.ID,
 		CategoryName: category.CategoriesName,
+		ParentID:     category.ParentID,
+		SortOrder:    category.SortOrder,
+		TaxRateID:    category.TaxRateID,
+		CreatedAt:    category.CreatedAt,
+		UpdatedAt:    category.UpdatedAt,
 	}
 }
+
+// CategoryDeleteResponse reports what deleting a category changed.
+type CategoryDeleteResponse struct {
+	MovedItems        int64 `json:"moved_items"`
+	MovedCategories   int64 `json:"moved_categories"`
+	DeletedItems      int64 `json:"deleted_items"`
+	DeletedCategories int64 `json:"deleted_categories"`
+}
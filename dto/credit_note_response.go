@@ -0,0 +1,67 @@
+package dto
+
+import (
+	"coffee_shop/models"
+	"fmt"
+	"time"
+)
+
+type CreditNoteItemResponse struct {
+	ItemID   uint    `json:"item_id"`
+	MenuID   *uint   `json:"menu_id"`
+	Quantity int     `json:"quantity"`
+	Amount   float64 `json:"amount"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type CreditNoteResponse struct {
+	ID              uint                     `json:"id"`
+	Number          string                   `json:"number"`
+	OrderID         uint                     `json:"order_id"`
+	Type            string                   `json:"type"`
+	Reason          string                   `json:"reason"`
+	Note            string                   `json:"note"`
+	Amount          float64                  `json:"amount"`
+	Revenue         float64                  `json:"revenue"`
+	RefundMethod    string                   `json:"refund_method"`
+	RefundStatus    string                   `json:"refund_status"`
+	RefundReference string                   `json:"refund_reference"`
+	IssuedBy        *uint                    `json:"issued_by"`
+	Items           []CreditNoteItemResponse `json:"items"`
+	CreatedAt       time.Time                `json:"created_at"`
+}
+
+// CreditNoteNumber is the number a credit note is printed and refunded
+// under.
+func CreditNoteNumber(id uint) string {
+	return fmt.Sprintf("CN-%06d", id)
+}
+
+func ToCreditNoteResponse(note *models.CreditNote) CreditNoteResponse {
+	response := CreditNoteResponse{
+		ID:              note.ID,
+		Number:          CreditNoteNumber(note.ID),
+		OrderID:         note.OrderID,
+		Type:            note.Type,
+		Reason:          note.Reason,
+		Note:            note.Note,
+		Amount:          note.Amount,
+		Revenue:         note.Revenue,
+		RefundMethod:    note.RefundMethod,
+		RefundStatus:    note.RefundStatus,
+		RefundReference: note.RefundReference,
+		IssuedBy:        note.IssuedBy,
+		Items:           make([]CreditNoteItemResponse, 0, len(note.Items)),
+		CreatedAt:       note.CreatedAt,
+	}
+	for _, item := range note.Items {
+		response.Items = append(response.Items, CreditNoteItemResponse{
+			ItemID:   item.OrderMenuItemID,
+			MenuID:   item.MenuID,
+			Quantity: item.Quantity,
+			Amount:   item.Amount,
+			Revenue:  item.Revenue,
+		})
+	}
+	return response
+}
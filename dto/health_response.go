@@ -0,0 +1,23 @@
+package dto
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type DependencyCheck struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Detail    string  `json:"detail,omitempty"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type ReadinessReport struct {
+	Status string                     `json:"status"`
+	Checks map[string]DependencyCheck `json:"checks"`
+}
+
+type LivenessReport struct {
+	Status string `json:"status"`
+	Uptime string `json:"uptime"`
+}
@@ -0,0 +1,20 @@
+package dto
+
+// LoyaltyRewardRequest creates or replaces a reward. It gives either one
+// free unit of MenuID or DiscountAmount off the order; an omitted Active
+// defaults to true.
+type LoyaltyRewardRequest struct {
+	Name           string  `json:"name"`
+	Points         int     `json:"points"`
+	MenuID         *uint   `json:"menu_id"`
+	DiscountAmount float64 `json:"discount_amount"`
+	Active         *bool   `json:"active"`
+}
+
+// LoyaltyAdjustRequest adds points to a user, or removes them when Points
+// is negative. The note is required and kept in the ledger.
+type LoyaltyAdjustRequest struct {
+	UserID uint   `json:"user_id"`
+	Points int    `json:"points"`
+	Note   string `json:"note"`
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+type LoyaltyEntryResponse struct {
+	ID         uint       `json:"id"`
+	Type       string     `json:"type"`
+	Points     int        `json:"points"`
+	Remaining  int        `json:"remaining"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	OrderID    *uint      `json:"order_id"`
+	RewardID   *uint      `json:"reward_id"`
+	AdjustedBy *uint      `json:"adjusted_by"`
+	Note       string     `json:"note"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// LoyaltyResponse is a user's balance with one page of their ledger,
+// newest first.
+type LoyaltyResponse struct {
+	UserID  uint                   `json:"user_id"`
+	Balance int                    `json:"balance"`
+	Total   int64                  `json:"total"`
+	Entries []LoyaltyEntryResponse `json:"entries"`
+}
+
+type LoyaltyRewardResponse struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Points         int       `json:"points"`
+	MenuID         *uint     `json:"menu_id"`
+	DiscountAmount float64   `json:"discount_amount"`
+	Active         bool      `json:"active"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
@@ -7,3 +7,19 @@ type MenuRequest struct {
 	Description string  `json:"description" form:"description"`
 	ImageURL    string  `json:"image_url" form:"image_url"`
 }
+
+// MenuVariantRequest creates or changes a variant of a menu item. On an
+// update the fields that are left out keep their value.
+type MenuVariantRequest struct {
+	VariantName string   `json:"variant_name"`
+	PriceDelta  *float64 `json:"price_delta"`
+	SortOrder   *int     `json:"sort_order"`
+}
+
+// MenuModifierRequest creates or changes a modifier of a menu item. On an
+// update the fields that are left out keep their value.
+type MenuModifierRequest struct {
+	ModifierName string   `json:"modifier_name"`
+	Price        *float64 `json:"price"`
+	SortOrder    *int     `json:"sort_order"`
+}
@@ -1,21 +1,105 @@
 package dto
 
-import "coffee_shop/models"
+import (
+	"coffee_shop/models"
+	"time"
+)
+
+// Relations a menu response can be expanded with through ?include=
+const (
+	MenuIncludeCategory  = models.MenuRelationCategory
+	MenuIncludeVariants  = models.MenuRelationVariants
+	MenuIncludeModifiers = models.MenuRelationModifiers
+)
+
+var MenuIncludes = []string{MenuIncludeCategory, MenuIncludeVariants, MenuIncludeModifiers}
+
+// MenuQuery holds the options of a menu listing. A nil Available returns
+// every item, true only the items that can be ordered now.
+type MenuQuery struct {
+	Includes  []string
+	Available *bool
+}
 
 type MenuResponse struct {
-	MenuName    string  `json:"menu_name"`
-	Price       float64 `json:"price"`
-	CategoryID  uint    `json:"category_id"`
-	Description string  `json:"description"`
-	ImageURL    string  `json:"image_url"`
+	ID          uint                      `json:"id"`
+	MenuName    string                    `json:"menu_name"`
+	Price       float64                   `json:"price"`
+	CategoryID  uint                      `json:"category_id"`
+	Description string                    `json:"description"`
+	ImageURL    string                    `json:"image_url"`
+	SoldOut     bool                      `json:"sold_out"`
+	Available   bool                      `json:"available"`
+	Images      map[string]ImageRendition `json:"images,omitempty"`
+	Category    *CategoryResponse         `json:"category,omitempty"`
+	Variants    []MenuVariantResponse     `json:"variants,omitempty"`
+	Modifiers   []MenuModifierResponse    `json:"modifiers,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// ImageRendition holds the URLs of one image size. WebPURL is empty for
+// images uploaded before WebP versions were generated.
+type ImageRendition struct {
+	URL     string `json:"url"`
+	WebPURL string `json:"webp_url,omitempty"`
+}
+
+type MenuVariantResponse struct {
+	ID          uint    `json:"id"`
+	VariantName string  `json:"variant_name"`
+	PriceDelta  float64 `json:"price_delta"`
+	SortOrder   int     `json:"sort_order"`
+}
+
+type MenuModifierResponse struct {
+	ID           uint    `json:"id"`
+	ModifierName string  `json:"modifier_name"`
+	Price        float64 `json:"price"`
+	SortOrder    int     `json:"sort_order"`
 }
 
+// ToMenuResponse copies the menu and whatever relations were preloaded.
+// A category that was not loaded has a zero ID and is left out.
 func ToMenuResponse(menu *models.Menu) MenuResponse {
-	return MenuResponse{
+	response := MenuResponse{
+		ID:          menu.ID,
 		MenuName:    menu.MenuName,
 		Price:       menu.Price,
 		CategoryID:  menu.CategoryID,
 		Description: menu.Description,
 		ImageURL:    menu.ImageURL,
+		SoldOut:     menu.SoldOut,
+		CreatedAt:   menu.CreatedAt,
+		UpdatedAt:   menu.UpdatedAt,
+	}
+	if menu.Category.ID != 0 {
+		category := ToCategoryResponse(&menu.Category)
+		response.Category = &category
+	}
+	for _, variant := range menu.Variants {
+		response.Variants = append(response.Variants, ToMenuVariantResponse(&variant))
+	}
+	for _, modifier := range menu.Modifiers {
+		response.Modifiers = append(response.Modifiers, ToMenuModifierResponse(&modifier))
+	}
+	return response
+}
+
+func ToMenuVariantResponse(variant *models.MenuVariant) MenuVariantResponse {
+	return MenuVariantResponse{
+		ID:          variant.ID,
+		VariantName: variant.VariantName,
+		PriceDelta:  variant.PriceDelta,
+		SortOrder:   variant.SortOrder,
+	}
+}
+
+func ToMenuModifierResponse(modifier *models.MenuModifier) MenuModifierResponse {
+	return MenuModifierResponse{
+		ID:           modifier.ID,
+		ModifierName: modifier.ModifierName,
+		Price:        modifier.Price,
+		SortOrder:    modifier.SortOrder,
 	}
 }
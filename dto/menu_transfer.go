@@ -0,0 +1,43 @@
+package dto
+
+const (
+	MenuImportDryRun = "dry-run"
+	MenuImportCommit = "commit"
+
+	MenuImportActionCreate = "create"
+	MenuImportActionUpdate = "update"
+	MenuImportActionError  = "error"
+)
+
+// MenuTransferRow is one menu item in an export or an import file.
+// Categories are referenced by name so a file can move between databases.
+type MenuTransferRow struct {
+	ID           uint    `json:"id,omitempty"`
+	MenuName     string  `json:"menu_name"`
+	Price        float64 `json:"price"`
+	Description  string  `json:"description"`
+	CategoryName string  `json:"category_name"`
+	ImageURL     string  `json:"image_url"`
+}
+
+type MenuExportResponse struct {
+	Categories []CategoryResponse `json:"categories"`
+	Menus      []MenuTransferRow  `json:"menus"`
+}
+
+type MenuImportRowResult struct {
+	Row      int      `json:"row"`
+	MenuName string   `json:"menu_name"`
+	Action   string   `json:"action"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type MenuImportResponse struct {
+	Mode      string                `json:"mode"`
+	Committed bool                  `json:"committed"`
+	Total     int                   `json:"total"`
+	Created   int                   `json:"created"`
+	Updated   int                   `json:"updated"`
+	Failed    int                   `json:"failed"`
+	Rows      []MenuImportRowResult `json:"rows"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "encoding/json"
+
+// Optional is a field of a partial update that tells an omitted value
+// apart from an explicit null: Set is true when the field was sent, Value
+// is nil when it was sent as null.
+type Optional[T any] struct {
+	Set   bool
+	Value *T
+}
+
+// UnmarshalJSON is only called for fields that are present.
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	o.Value = nil
+	if string(data) == "null" {
+		return nil
+	}
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	o.Value = &value
+	return nil
+}
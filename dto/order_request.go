@@ -0,0 +1,44 @@
+package dto
+
+// OrderItemRequest is one line of an order. VariantID and ModifierIDs
+// must belong to the menu item.
+type OrderItemRequest struct {
+	MenuID      uint   `json:"menu_id"`
+	VariantID   *uint  `json:"variant_id,omitempty"`
+	ModifierIDs []uint `json:"modifier_ids,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
+
+// OrderRequest places an order. OrderType is takeaway when omitted.
+type OrderRequest struct {
+	OrderType   string             `json:"order_type"`
+	Note        string             `json:"note"`
+	Items       []OrderItemRequest `json:"items"`
+	VoucherCode string             `json:"voucher_code"`
+	RewardID    *uint              `json:"reward_id"`
+}
+
+// CancelOrderRequest cancels an order. RefundMethod is how the order was
+// paid when omitted, and required when it was split across methods. An
+// order not paid at the till gets nothing back.
+type CancelOrderRequest struct {
+	Reason       string `json:"reason"`
+	Note         string `json:"note"`
+	RefundMethod string `json:"refund_method"`
+}
+
+// RefundItemRequest gives back Quantity units of the order line ItemID.
+type RefundItemRequest struct {
+	ItemID   uint `json:"item_id"`
+	Quantity int  `json:"quantity"`
+}
+
+// RefundOrderRequest refunds a completed order. Without items everything
+// not refunded yet is refunded. RefundMethod defaults like on
+// CancelOrderRequest.
+type RefundOrderRequest struct {
+	Reason       string              `json:"reason"`
+	Note         string              `json:"note"`
+	RefundMethod string              `json:"refund_method"`
+	Items        []RefundItemRequest `json:"items"`
+}
@@ -0,0 +1,165 @@
+package dto
+
+import (
+	"coffee_shop/models"
+	"time"
+)
+
+// Reasons an order item is refused, see UnavailableItem.
+const (
+	UnavailableNotFound = "not_found"
+	UnavailableSoldOut  = "sold_out"
+	UnavailableSchedule = "not_available_now"
+	UnavailableOption   = "option_unavailable"
+)
+
+// UnavailableItem is a refused line of an order, Line is its position in
+// the request starting at 1.
+type UnavailableItem struct {
+	Line     int    `json:"line"`
+	MenuID   uint   `json:"menu_id"`
+	MenuName string `json:"menu_name,omitempty"`
+	Reason   string `json:"reason"`
+}
+
+type OrderItemModifierResponse struct {
+	ModifierID *uint   `json:"modifier_id"`
+	Name       string  `json:"name"`
+	Price      float64 `json:"price"`
+}
+
+type OrderItemResponse struct {
+	ID          uint                        `json:"id"`
+	MenuID      uint                        `json:"menu_id"`
+	MenuName    string                      `json:"menu_name"`
+	VariantID   *uint                       `json:"variant_id"`
+	VariantName string                      `json:"variant_name"`
+	Modifiers   []OrderItemModifierResponse `json:"modifiers"`
+	Price       float64                     `json:"price"`
+	Quantity    int                         `json:"quantity"`
+	Discount    float64                     `json:"discount"`
+	PromotionID *uint                       `json:"promotion_id"`
+	TaxRateID   *uint                       `json:"tax_rate_id"`
+	Tax         float64                     `json:"tax"`
+}
+
+// OrderTaxResponse is one line of the tax breakdown of a receipt.
+// Inclusive taxes are already part of the prices.
+type OrderTaxResponse struct {
+	TaxRateID     *uint   `json:"tax_rate_id"`
+	Name          string  `json:"name"`
+	Rate          float64 `json:"rate"`
+	Inclusive     bool    `json:"inclusive"`
+	TaxableAmount float64 `json:"taxable_amount"`
+	Amount        float64 `json:"amount"`
+}
+
+type OrderPaymentResponse struct {
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Tendered  float64 `json:"tendered"`
+	ChangeDue float64 `json:"change_due"`
+	Reference string  `json:"reference"`
+}
+
+type OrderResponse struct {
+	ID              uint                   `json:"id"`
+	UserID          uint                   `json:"user_id"`
+	Status          string                 `json:"status"`
+	OrderType       string                 `json:"order_type"`
+	Note            string                 `json:"note"`
+	Subtotal        float64                `json:"subtotal"`
+	Discount        float64                `json:"discount"`
+	PromotionID     *uint                  `json:"promotion_id"`
+	VoucherID       *uint                  `json:"voucher_id"`
+	VoucherDiscount float64                `json:"voucher_discount"`
+	LoyaltyRewardID *uint                  `json:"loyalty_reward_id"`
+	LoyaltyDiscount float64                `json:"loyalty_discount"`
+	DiscountTotal   float64                `json:"discount_total"`
+	TotalPrice      float64                `json:"total_price"`
+	Tax             float64                `json:"tax"`
+	ServiceCharge   float64                `json:"service_charge"`
+	Rounding        float64                `json:"rounding"`
+	GrandTotal      float64                `json:"grand_total"`
+	Taxes           []OrderTaxResponse     `json:"taxes"`
+	Items           []OrderItemResponse    `json:"items"`
+	CashierID       *uint                  `json:"cashier_id"`
+	Payments        []OrderPaymentResponse `json:"payments"`
+	CanceledAt      *time.Time             `json:"canceled_at"`
+	CreatedAt       time.Time              `json:"created_at"`
+	UpdatedAt       time.Time              `json:"updated_at"`
+}
+
+func ToOrderResponse(order *models.Order) OrderResponse {
+	response := OrderResponse{
+		ID:              order.ID,
+		UserID:          order.UserID,
+		Status:          order.Status,
+		OrderType:       order.OrderType,
+		Note:            order.Note,
+		Subtotal:        order.Subtotal,
+		Discount:        order.Discount,
+		PromotionID:     order.PromotionID,
+		VoucherID:       order.VoucherID,
+		VoucherDiscount: order.VoucherDiscount,
+		LoyaltyRewardID: order.LoyaltyRewardID,
+		LoyaltyDiscount: order.LoyaltyDiscount,
+		DiscountTotal:   order.DiscountTotal,
+		TotalPrice:      order.TotalPrice,
+		Tax:             order.Tax,
+		ServiceCharge:   order.ServiceCharge,
+		Rounding:        order.Rounding,
+		GrandTotal:      order.GrandTotal,
+		Taxes:           make([]OrderTaxResponse, 0, len(order.Taxes)),
+		Items:           make([]OrderItemResponse, 0, len(order.Items)),
+		CashierID:       order.CashierID,
+		Payments:        make([]OrderPaymentResponse, 0, len(order.Payments)),
+		CanceledAt:      order.CanceledAt,
+		CreatedAt:       order.CreatedAt,
+		UpdatedAt:       order.UpdatedAt,
+	}
+	for _, item := range order.Items {
+		modifiers := make([]OrderItemModifierResponse, 0, len(item.Modifiers))
+		for _, modifier := range item.Modifiers {
+			modifiers = append(modifiers, OrderItemModifierResponse{
+				ModifierID: modifier.ModifierID,
+				Name:       modifier.Name,
+				Price:      modifier.Price,
+			})
+		}
+		response.Items = append(response.Items, OrderItemResponse{
+			ID:          item.ID,
+			MenuID:      item.MenuID,
+			MenuName:    item.Menu.MenuName,
+			VariantID:   item.VariantID,
+			VariantName: item.VariantName,
+			Modifiers:   modifiers,
+			Price:       item.Price,
+			Quantity:    item.Quantity,
+			Discount:    item.Discount,
+			PromotionID: item.PromotionID,
+			TaxRateID:   item.TaxRateID,
+			Tax:         item.Tax,
+		})
+	}
+	for _, payment := range order.Payments {
+		response.Payments = append(response.Payments, OrderPaymentResponse{
+			Method:    payment.Method,
+			Amount:    payment.Amount,
+			Tendered:  payment.Tendered,
+			ChangeDue: payment.ChangeDue,
+			Reference: payment.Reference,
+		})
+	}
+	for _, tax := range order.Taxes {
+		response.Taxes = append(response.Taxes, OrderTaxResponse{
+			TaxRateID:     tax.TaxRateID,
+			Name:          tax.Name,
+			Rate:          tax.Rate,
+			Inclusive:     tax.Inclusive,
+			TaxableAmount: tax.TaxableAmount,
+			Amount:        tax.Amount,
+		})
+	}
+	return response
+}
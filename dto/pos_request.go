@@ -0,0 +1,20 @@
+package dto
+
+// POSPaymentRequest is one payment taken at the till. An Amount of 0
+// takes what is left to pay, on at most one payment. Tendered is the cash
+// handed over, the amount when omitted.
+type POSPaymentRequest struct {
+	Method    string  `json:"method"`
+	Amount    float64 `json:"amount"`
+	Tendered  float64 `json:"tendered"`
+	Reference string  `json:"reference"`
+}
+
+// POSOrderRequest is an order a cashier takes for a walk-in customer.
+// Without CustomerID the customer is anonymous and cannot use vouchers or
+// rewards.
+type POSOrderRequest struct {
+	OrderRequest
+	CustomerID *uint               `json:"customer_id"`
+	Payments   []POSPaymentRequest `json:"payments"`
+}
@@ -0,0 +1,10 @@
+package dto
+
+// POSCustomerResponse is a customer found at the till, with the points
+// they can spend.
+type POSCustomerResponse struct {
+	ID            uint   `json:"id"`
+	Name          string `json:"name"`
+	PhoneNumber   string `json:"phone_number"`
+	LoyaltyPoints int    `json:"loyalty_points"`
+}
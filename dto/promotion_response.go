@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type PromotionResponse struct {
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Value       float64   `json:"value"`
+	BuyQuantity int       `json:"buy_quantity"`
+	GetQuantity int       `json:"get_quantity"`
+	MenuID      *uint     `json:"menu_id"`
+	CategoryID  *uint     `json:"category_id"`
+	MinSpend    float64   `json:"min_spend"`
+	Days        []string  `json:"days"`
+	StartTime   string    `json:"start_time"`
+	EndTime     string    `json:"end_time"`
+	StartDate   string    `json:"start_date"`
+	EndDate     string    `json:"end_date"`
+	Priority    int       `json:"priority"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
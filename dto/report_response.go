@@ -0,0 +1,19 @@
+package dto
+
+type DailyReportItem struct {
+	MenuID   uint    `json:"menu_id"`
+	MenuName string  `json:"menu_name"`
+	Quantity int     `json:"quantity"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type DailyReportResponse struct {
+	Date           string            `json:"date"`
+	TotalOrders    int               `json:"total_orders"`
+	OrdersByStatus map[string]int    `json:"orders_by_status"`
+	Revenue        float64           `json:"revenue"`
+	Refunds        float64           `json:"refunds"`
+	NetRevenue     float64           `json:"net_revenue"`
+	AverageOrder   float64           `json:"average_order"`
+	TopItems       []DailyReportItem `json:"top_items"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// OpenShiftRequest opens a shift with the cash counted into the drawer.
+type OpenShiftRequest struct {
+	OpeningFloat float64 `json:"opening_float"`
+}
+
+// CashMovementRequest records cash put into or taken out of the drawer.
+// Type is cash_in, paid_out or drop; paid_outs need a note saying what
+// they paid for.
+type CashMovementRequest struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+	Note   string  `json:"note"`
+}
+
+// CloseShiftRequest closes a shift with the cash counted in the drawer.
+type CloseShiftRequest struct {
+	CountedCash *float64 `json:"counted_cash"`
+	Note        string   `json:"note"`
+}
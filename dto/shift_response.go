@@ -0,0 +1,53 @@
+package dto
+
+import (
+	"coffee_shop/models"
+	"time"
+)
+
+type ShiftMovementResponse struct {
+	ID         uint      `json:"id"`
+	Type       string    `json:"type"`
+	Amount     float64   `json:"amount"`
+	Note       string    `json:"note"`
+	RecordedBy *uint     `json:"recorded_by"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ShiftReportResponse is the Z-report of a shift, or the report so far of
+// a shift still open. Sales and Refunds are per payment method; the cash
+// ones make up the cash expected in the drawer together with the float
+// and the cash movements.
+type ShiftReportResponse struct {
+	ID           uint                    `json:"id"`
+	CashierID    uint                    `json:"cashier_id"`
+	Status       string                  `json:"status"`
+	OpenedAt     time.Time               `json:"opened_at"`
+	ClosedAt     *time.Time              `json:"closed_at"`
+	Orders       int64                   `json:"orders"`
+	Sales        map[string]float64      `json:"sales"`
+	Refunds      map[string]float64      `json:"refunds"`
+	OpeningFloat float64                 `json:"opening_float"`
+	CashSales    float64                 `json:"cash_sales"`
+	CashRefunds  float64                 `json:"cash_refunds"`
+	CashIn       float64                 `json:"cash_in"`
+	PaidOut      float64                 `json:"paid_out"`
+	Drops        float64                 `json:"drops"`
+	ExpectedCash float64                 `json:"expected_cash"`
+	CountedCash  *float64                `json:"counted_cash"`
+	Discrepancy  *float64                `json:"discrepancy"`
+	Flagged      bool                    `json:"flagged"`
+	ClosingNote  string                  `json:"closing_note"`
+	Movements    []ShiftMovementResponse `json:"movements"`
+}
+
+func ToShiftMovementResponse(movement *models.ShiftCashMovement) ShiftMovementResponse {
+	return ShiftMovementResponse{
+		ID:         movement.ID,
+		Type:       movement.Type,
+		Amount:     movement.Amount,
+		Note:       movement.Note,
+		RecordedBy: movement.RecordedBy,
+		CreatedAt:  movement.CreatedAt,
+	}
+}
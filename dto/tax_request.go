@@ -0,0 +1,20 @@
+package dto
+
+// TaxRateRequest creates or replaces a tax rate. Rate is a percentage;
+// making a rate the default takes it away from the previous default.
+type TaxRateRequest struct {
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	Inclusive bool    `json:"inclusive"`
+	IsDefault bool    `json:"is_default"`
+}
+
+// ServiceChargeRequest creates or replaces a service charge rule. Rate is
+// a percentage and an omitted Active defaults to true.
+type ServiceChargeRequest struct {
+	Name      string  `json:"name"`
+	OrderType string  `json:"order_type"`
+	Rate      float64 `json:"rate"`
+	Taxable   bool    `json:"taxable"`
+	Active    *bool   `json:"active"`
+}
@@ -0,0 +1,24 @@
+package dto
+
+import "time"
+
+type TaxRateResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Rate      float64   `json:"rate"`
+	Inclusive bool      `json:"inclusive"`
+	IsDefault bool      `json:"is_default"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type ServiceChargeResponse struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	OrderType string    `json:"order_type"`
+	Rate      float64   `json:"rate"`
+	Taxable   bool      `json:"taxable"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
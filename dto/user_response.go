@@ -1,63 +1,86 @@
 package dto
 
-import "coffee_shop/models"
+import (
+	"coffee_shop/models"
+	"time"
+)
 
 type RegisterResponse struct {
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	Role        string `json:"role"`
-	PhoneNumber string `json:"phone_number"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	PhoneNumber string    `json:"phone_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func ToRegisterResponse(user models.User) RegisterResponse {
 	return RegisterResponse{
+		ID:          user.ID,
 		Name:        user.Name,
 		Email:       user.Email,
 		Role:        user.Role,
 		PhoneNumber: user.PhoneNumber,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 }
 
 type LoginResponse struct {
-	Name           string `json:"name"`
-	Email          string `json:"email"`
-	Role           string `json:"role"`
-	PhoneNumber    string `json:"phone_number"`
-	AccessToken    string `json:"access_token"`
-	RefresherToken string `json:"refresher_token"`
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Email          string    `json:"email"`
+	Role           string    `json:"role"`
+	PhoneNumber    string    `json:"phone_number"`
+	AccessToken    string    `json:"access_token"`
+	RefresherToken string    `json:"refresher_token"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
 func ToLoginResponse(user models.User, accessToken string, refresherToken string) LoginResponse {
 	return LoginResponse{
+		ID:             user.ID,
 		Name:           user.Name,
 		Email:          user.Email,
 		Role:           user.Role,
 		PhoneNumber:    user.PhoneNumber,
 		AccessToken:    accessToken,
 		RefresherToken: refresherToken,
+		CreatedAt:      user.CreatedAt,
+		UpdatedAt:      user.UpdatedAt,
 	}
 }
 
 type UpdateUserResponse struct {
-	Name        string `json:"name"`
-	Role        string `json:"role"`
-	PhoneNumber string `json:"phone_number"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Role        string    `json:"role"`
+	PhoneNumber string    `json:"phone_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func ToUpdateUserResponse(user models.User) UpdateUserResponse {
 	return UpdateUserResponse{
+		ID:          user.ID,
 		Name:        user.Name,
 		Role:        user.Role,
 		PhoneNumber: user.PhoneNumber,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 }
 
 type GetUserByIDResponse struct {
-	ID          uint   `json:"id"`
-	Name        string `json:"name"`
-	Email       string `json:"email"`
-	Role        string `json:"role"`
-	PhoneNumber string `json:"phone_number"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	PhoneNumber string    `json:"phone_number"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
 }
 
 func ToGetUserByIDResponse(user models.User) GetUserByIDResponse {
@@ -67,5 +90,7 @@ func ToGetUserByIDResponse(user models.User) GetUserByIDResponse {
 		Email:       user.Email,
 		Role:        user.Role,
 		PhoneNumber: user.PhoneNumber,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
 	}
 }
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// VoucherRequest creates or replaces a voucher. Limits of 0 are unlimited,
+// no categories means the whole order is eligible and an omitted Active
+// defaults to true.
+type VoucherRequest struct {
+	Code           string     `json:"code"`
+	Description    string     `json:"description"`
+	Type           string     `json:"type"`
+	Value          float64    `json:"value"`
+	MaxDiscount    float64    `json:"max_discount"`
+	MinSpend       float64    `json:"min_spend"`
+	ValidFrom      *time.Time `json:"valid_from"`
+	ValidUntil     *time.Time `json:"valid_until"`
+	MaxRedemptions int        `json:"max_redemptions"`
+	MaxPerUser     int        `json:"max_per_user"`
+	FirstOrderOnly bool       `json:"first_order_only"`
+	CategoryIDs    []uint     `json:"category_ids"`
+	Active         *bool      `json:"active"`
+}
+
+// VoucherValidateRequest checks a code against the items of a cart.
+type VoucherValidateRequest struct {
+	Code  string             `json:"code"`
+	Items []OrderItemRequest `json:"items"`
+}
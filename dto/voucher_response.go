@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+type VoucherResponse struct {
+	ID              uint       `json:"id"`
+	Code            string     `json:"code"`
+	Description     string     `json:"description"`
+	Type            string     `json:"type"`
+	Value           float64    `json:"value"`
+	MaxDiscount     float64    `json:"max_discount"`
+	MinSpend        float64    `json:"min_spend"`
+	ValidFrom       *time.Time `json:"valid_from"`
+	ValidUntil      *time.Time `json:"valid_until"`
+	MaxRedemptions  int        `json:"max_redemptions"`
+	MaxPerUser      int        `json:"max_per_user"`
+	RedemptionCount int        `json:"redemption_count"`
+	FirstOrderOnly  bool       `json:"first_order_only"`
+	CategoryIDs     []uint     `json:"category_ids"`
+	Active          bool       `json:"active"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// VoucherValidationResponse tells whether a code can be used on a cart.
+// Order is the cart priced with the voucher when it is valid and without
+// it otherwise.
+type VoucherValidationResponse struct {
+	Code     string        `json:"code"`
+	Valid    bool          `json:"valid"`
+	Reason   string        `json:"reason,omitempty"`
+	Discount float64       `json:"discount"`
+	Order    OrderResponse `json:"order"`
+}
@@ -0,0 +1,146 @@
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"slices"
+
+	"github.com/HugoSmits86/nativewebp"
+	orient "github.com/disintegration/imaging"
+	"golang.org/x/image/draw"
+)
+
+// Images larger than this are rejected before decoding, so a small file
+// that declares huge dimensions cannot exhaust memory.
+const maxPixels = 40_000_000
+
+const jpegQuality = 85
+
+// ErrUnsupportedFormat is returned when the sniffed content type is not
+// one of the allowed image types.
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// Rendition is a named size an upload is resized to. MaxSize bounds the
+// longest side; smaller images are never upscaled.
+type Rendition struct {
+	Name    string
+	MaxSize int
+}
+
+const (
+	Thumbnail = "thumbnail"
+	Card      = "card"
+	Full      = "full"
+)
+
+// Renditions lists every size generated for an upload, smallest first.
+var Renditions = []Rendition{
+	{Name: Thumbnail, MaxSize: 150},
+	{Name: Card, MaxSize: 480},
+	{Name: Full, MaxSize: 1200},
+}
+
+// Output is one encoded file produced by Process.
+type Output struct {
+	Rendition   string
+	Ext         string
+	ContentType string
+	Data        []byte
+}
+
+// DetectType sniffs the content type from the bytes themselves, ignoring
+// whatever the client claimed.
+func DetectType(content []byte) string {
+	return http.DetectContentType(content)
+}
+
+// Process validates an uploaded image and returns every rendition in its
+// original family (JPEG, or PNG when the image has transparency) and as
+// WebP. Re-encoding from decoded pixels drops EXIF and any other metadata,
+// including GPS coordinates from phone cameras; the EXIF orientation is
+// applied first so the renditions come out upright.
+func Process(content []byte, allowedTypes []string) ([]Output, error) {
+	contentType := DetectType(content)
+	if !slices.Contains(allowedTypes, contentType) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, contentType)
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(content))
+	if err != nil {
+		return nil, errors.New("invalid image: " + err.Error())
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxPixels {
+		return nil, fmt.Errorf("invalid image dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+
+	// Phone cameras store portrait photos sideways with an EXIF
+	// orientation; turn the pixels upright before the metadata is dropped
+	src, err := orient.Decode(bytes.NewReader(content), orient.AutoOrientation(true))
+	if err != nil {
+		return nil, errors.New("invalid image: " + err.Error())
+	}
+
+	opaque := isOpaque(src)
+	var outputs []Output
+	for _, r := range Renditions {
+		img := resize(src, r.MaxSize)
+
+		var buf bytes.Buffer
+		output := Output{Rendition: r.Name}
+		if opaque {
+			err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+			output.Ext, output.ContentType = ".jpg", "image/jpeg"
+		} else {
+			err = png.Encode(&buf, img)
+			output.Ext, output.ContentType = ".png", "image/png"
+		}
+		if err != nil {
+			return nil, errors.New("failed to encode " + r.Name + ": " + err.Error())
+		}
+		output.Data = buf.Bytes()
+		outputs = append(outputs, output)
+
+		var webp bytes.Buffer
+		if err := nativewebp.Encode(&webp, img, nil); err != nil {
+			return nil, errors.New("failed to encode " + r.Name + " webp: " + err.Error())
+		}
+		outputs = append(outputs, Output{
+			Rendition:   r.Name,
+			Ext:         ".webp",
+			ContentType: "image/webp",
+			Data:        webp.Bytes(),
+		})
+	}
+	return outputs, nil
+}
+
+// resize scales img down so its longest side is at most maxSize.
+func resize(img image.Image, maxSize int) image.Image {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= maxSize && h <= maxSize {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxSize/w)
+		w = maxSize
+	} else {
+		w = max(1, w*maxSize/h)
+		h = maxSize
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+func isOpaque(img image.Image) bool {
+	if o, ok := img.(interface{ Opaque() bool }); ok {
+		return o.Opaque()
+	}
+	return true
+}
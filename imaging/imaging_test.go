@@ -0,0 +1,122 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+
+	_ "golang.org/x/image/webp"
+)
+
+// testJPEG encodes a w x h JPEG and, when orientation is set, adds an
+// EXIF segment carrying it like a phone camera does.
+func testJPEG(t *testing.T, w, h int, orientation uint16) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	if orientation == 0 {
+		return buf.Bytes()
+	}
+
+	// Big-endian TIFF header, one IFD with the orientation tag
+	var exif bytes.Buffer
+	exif.WriteString("Exif\x00\x00MM\x00\x2a\x00\x00\x00\x08")
+	binary.Write(&exif, binary.BigEndian, []uint16{1, 0x0112, 3})
+	binary.Write(&exif, binary.BigEndian, uint32(1))
+	binary.Write(&exif, binary.BigEndian, []uint16{orientation, 0})
+	binary.Write(&exif, binary.BigEndian, uint32(0))
+
+	segment := []byte{0xFF, 0xE1}
+	segment = binary.BigEndian.AppendUint16(segment, uint16(exif.Len()+2))
+	segment = append(segment, exif.Bytes()...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func rendition(t *testing.T, outputs []Output, name, ext string) image.Image {
+	t.Helper()
+	for _, output := range outputs {
+		if output.Rendition == name && output.Ext == ext {
+			img, _, err := image.Decode(bytes.NewReader(output.Data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return img
+		}
+	}
+	t.Fatalf("no %s %s rendition", name, ext)
+	return nil
+}
+
+func TestProcessAppliesOrientation(t *testing.T) {
+	tests := []struct {
+		name        string
+		orientation uint16
+		wantW       int
+		wantH       int
+	}{
+		{"no exif", 0, 40, 20},
+		{"upright", 1, 40, 20},
+		{"rotated 90 cw", 6, 20, 40},
+		{"rotated 90 ccw", 8, 20, 40},
+		{"upside down", 3, 40, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputs, err := Process(testJPEG(t, 40, 20, tt.orientation), []string{"image/jpeg"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			bounds := rendition(t, outputs, Full, ".jpg").Bounds()
+			if bounds.Dx() != tt.wantW || bounds.Dy() != tt.wantH {
+				t.Errorf("full rendition is %dx%d, want %dx%d", bounds.Dx(), bounds.Dy(), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestProcessResizes(t *testing.T) {
+	outputs, err := Process(testJPEG(t, 600, 300, 6), []string{"image/jpeg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 2*len(Renditions) {
+		t.Fatalf("got %d outputs, want %d", len(outputs), 2*len(Renditions))
+	}
+	// Rotated to 300x600, so the height is the longest side
+	tests := []struct {
+		name  string
+		wantW int
+		wantH int
+	}{
+		{Thumbnail, 75, 150},
+		{Card, 240, 480},
+		{Full, 300, 600},
+	}
+	for _, tt := range tests {
+		bounds := rendition(t, outputs, tt.name, ".webp").Bounds()
+		if bounds.Dx() != tt.wantW || bounds.Dy() != tt.wantH {
+			t.Errorf("%s is %dx%d, want %dx%d", tt.name, bounds.Dx(), bounds.Dy(), tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestProcessRejectsUnsupportedContent(t *testing.T) {
+	_, err := Process([]byte("GIF89a not really"), []string{"image/jpeg", "image/png"})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Process = %v, want ErrUnsupportedFormat", err)
+	}
+}
@@ -0,0 +1,103 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// Manager owns the shutdown sequence of the process. Resources register a
+// close hook when they are created and background workers are started
+// through Go, so a single Shutdown call can stop everything in order.
+type Manager struct {
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks []hook
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	workers sync.WaitGroup
+}
+
+type hook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+func New() *Manager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Draining reports whether shutdown has started. Readiness checks use it
+// to take the instance out of rotation while in-flight requests finish.
+func (m *Manager) Draining() bool {
+	return m.draining.Load()
+}
+
+// StartDraining flips readiness to unhealthy without stopping anything yet.
+func (m *Manager) StartDraining() {
+	m.draining.Store(true)
+}
+
+// OnShutdown registers a close hook. Hooks run in reverse registration
+// order, like defer, so a resource is closed after everything that was
+// created on top of it.
+func (m *Manager) OnShutdown(name string, fn func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, fn: fn})
+}
+
+// Go runs a background worker. The context passed to fn is cancelled when
+// Shutdown starts and Shutdown waits for fn to return before closing the
+// resources registered with OnShutdown.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.ctx)
+		log.Default().Println("Worker stopped: " + name)
+	}()
+}
+
+// Shutdown stops the background workers, then runs the close hooks. It
+// keeps going when a hook fails and returns every error it collected.
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.StartDraining()
+
+	var errs []error
+
+	m.cancel()
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, errors.New("background workers did not stop in time: "+ctx.Err().Error()))
+	}
+
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i].fn(ctx); err != nil {
+			errs = append(errs, errors.New("failed to close "+hooks[i].name+": "+err.Error()))
+			continue
+		}
+		log.Default().Println("Closed " + hooks[i].name)
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,33 +1,20 @@
 package main
 
 import (
-	"coffee_shop/routes"
+	"coffee_shop/cli"
+	"errors"
 	"log"
 	"os"
 
-	"github.com/joho/godotenv"
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
+	// Availability schedules load IANA zones, embed them for minimal images
+	_ "time/tzdata"
 )
 
 func main() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Fatal("Error loading .env file")
+	if err := cli.Execute(os.Args[1:]); err != nil {
+		if errors.Is(err, cli.ErrUsage) {
+			os.Exit(2)
+		}
+		log.Fatal(err)
 	}
-
-	e := echo.New()
-
-	//Middleware
-	e.Use(middleware.CORS())
-	e.Use(middleware.Logger())
-	e.Use(middleware.Recover())
-
-	// ROUTES
-	routes.ApiRoutes(e)
-
-	// Start server
-	api_host := os.Getenv("API_HOST")
-	api_port := os.Getenv("API_PORT")
-	e.Logger.Fatal(e.Start(api_host + ":" + api_port))
 }
@@ -1,59 +1,111 @@
 package middlewares
 
 import (
+	"coffee_shop/config"
 	"coffee_shop/utils"
+	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
 )
 
-func JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		authHeader := c.Request().Header.Get("Authorization")
-		if !strings.HasPrefix(authHeader, "Bearer ") {
-			return c.JSON(http.StatusUnauthorized, map[string]string{
-				"message": "Missing or invalid token -> Unauthorized",
-			})
-		}
+// JWTMiddleware refuses requests without a valid token. A token is only
+// valid while it is the access token stored for its user in Redis, so
+// logging out or refreshing revokes it. Like the user controller, it
+// fails closed: when Redis cannot be reached the request is refused with
+// 503.
+func JWTMiddleware(cfg config.JWTConfig, rdb redis.UniversalClient) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if err := authenticate(c, cfg, rdb); err != nil {
+				return c.JSON(err.Code, echo.Map{"message": err.Message})
+			}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+			return next(c)
+		}
+	}
+}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			return utils.GetSecretKey(), nil
-		})
+// OptionalJWTMiddleware lets requests without a token through as
+// anonymous, with no user in the context. A token that is sent must still
+// be valid, as on JWTMiddleware.
+func OptionalJWTMiddleware(cfg config.JWTConfig, rdb redis.UniversalClient) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			if err := authenticate(c, cfg, rdb); err != nil {
+				return c.JSON(err.Code, echo.Map{"message": err.Message})
+			}
 
-		if err != nil || token == nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "invalid or expired token"})
+			return next(c)
 		}
+	}
+}
 
-		// safe conversion
-		claims, ok := token.Claims.(jwt.MapClaims)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "cannot parse JWT claims"})
-		}
+// authenticate checks the bearer token of the request and stores its
+// claims in the context, or returns why the request is refused.
+func authenticate(c echo.Context, cfg config.JWTConfig, rdb redis.UniversalClient) *echo.HTTPError {
+	authHeader := c.Request().Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Missing or invalid token -> Unauthorized")
+	}
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		userID, ok := claims["user_id"].(float64)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "invalid user_id in token"})
-		}
+	if err := setClaims(c, cfg, tokenString); err != nil {
+		return echo.NewHTTPError(http.StatusUnauthorized, err.Error())
+	}
 
-		email, ok := claims["email"].(string)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "invalid email in token"})
-		}
+	// The login and refresh endpoints store the current access token
+	// under the email of its user.
+	stored, err := rdb.Get(c.Request().Context(), c.Get("email").(string)).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return echo.NewHTTPError(http.StatusServiceUnavailable, "Failed to check token in Redis: "+err.Error())
+	}
+	if stored != tokenString {
+		return echo.NewHTTPError(http.StatusUnauthorized, "token revoked, log in again")
+	}
+	return nil
+}
 
-		role, ok := claims["role"].(string)
-		if !ok {
-			return c.JSON(http.StatusUnauthorized, echo.Map{"message": "invalid role in token"})
-		}
+// setClaims validates tokenString and stores its claims in the context.
+func setClaims(c echo.Context, cfg config.JWTConfig, tokenString string) error {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		return utils.GetSecretKey(cfg), nil
+	})
 
-		// Simpan ke context
-		c.Set("user_id", uint(userID))
-		c.Set("email", email)
-		c.Set("role", role)
+	if err != nil || token == nil || !token.Valid {
+		return errors.New("invalid or expired token")
+	}
+
+	// safe conversion
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return errors.New("cannot parse JWT claims")
+	}
 
-		return next(c)
+	userID, ok := claims["user_id"].(float64)
+	if !ok {
+		return errors.New("invalid user_id in token")
 	}
+
+	email, ok := claims["email"].(string)
+	if !ok {
+		return errors.New("invalid email in token")
+	}
+
+	role, ok := claims["role"].(string)
+	if !ok {
+		return errors.New("invalid role in token")
+	}
+
+	// Simpan ke context
+	c.Set("user_id", uint(userID))
+	c.Set("email", email)
+	c.Set("role", role)
+	return nil
 }
@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/utils"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenStore is a Redis client holding the access tokens by email, or
+// failing every lookup with err.
+type tokenStore struct {
+	redis.UniversalClient
+	tokens map[string]string
+	err    error
+}
+
+func (s *tokenStore) Get(ctx context.Context, key string) *redis.StringCmd {
+	if s.err != nil {
+		return redis.NewStringResult("", s.err)
+	}
+	token, ok := s.tokens[key]
+	if !ok {
+		return redis.NewStringResult("", redis.Nil)
+	}
+	return redis.NewStringResult(token, nil)
+}
+
+func TestJWTMiddlewareChecksRedis(t *testing.T) {
+	cfg := config.JWTConfig{SecretKey: "secret", Issuer: "test", AccessTTL: time.Hour}
+	token, err := utils.GenerateJWT(cfg, 7, "barista@example.com", "cashier")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newer, err := utils.GenerateJWT(cfg, 7, "barista@example.com", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		store    *tokenStore
+		optional bool
+		header   string
+		want     int
+	}{
+		{name: "current token", store: &tokenStore{tokens: map[string]string{"barista@example.com": token}}, header: "Bearer " + token, want: http.StatusOK},
+		{name: "logged out", store: &tokenStore{}, header: "Bearer " + token, want: http.StatusUnauthorized},
+		{name: "refreshed since", store: &tokenStore{tokens: map[string]string{"barista@example.com": newer}}, header: "Bearer " + token, want: http.StatusUnauthorized},
+		{name: "redis down", store: &tokenStore{err: errors.New("connection refused")}, header: "Bearer " + token, want: http.StatusServiceUnavailable},
+		{name: "optional without a token", store: &tokenStore{err: errors.New("connection refused")}, optional: true, want: http.StatusOK},
+		{name: "optional redis down", store: &tokenStore{err: errors.New("connection refused")}, optional: true, header: "Bearer " + token, want: http.StatusServiceUnavailable},
+		{name: "optional logged out", store: &tokenStore{}, optional: true, header: "Bearer " + token, want: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware := JWTMiddleware(cfg, tt.store)
+			if tt.optional {
+				middleware = OptionalJWTMiddleware(cfg, tt.store)
+			}
+			handler := middleware(func(c echo.Context) error {
+				return c.NoContent(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/user/detail", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			if err := handler(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatal(err)
+			}
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
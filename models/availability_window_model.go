@@ -0,0 +1,30 @@
+package models
+
+import "gorm.io/gorm"
+
+// AllDays is the DaysOfWeek mask of a window that applies every day.
+const AllDays = 1<<7 - 1
+
+// AvailabilityWindow limits when a menu item or a whole category can be
+// ordered. Exactly one of MenuID and CategoryID is set. Empty times and
+// dates are open ended, and a window whose end time is before its start
+// time runs past midnight.
+type AvailabilityWindow struct {
+	gorm.Model
+	MenuID     *uint `json:"menu_id"`
+	CategoryID *uint `json:"category_id"`
+	// DaysOfWeek is a bit mask indexed by time.Weekday, bit 0 is Sunday
+	DaysOfWeek int `gorm:"not null;default:127" json:"days_of_week"`
+	// StartTime and EndTime are "HH:MM" wall clock times
+	StartTime string `gorm:"not null;default:''" json:"start_time"`
+	EndTime   string `gorm:"not null;default:''" json:"end_time"`
+	// StartDate and EndDate are inclusive "YYYY-MM-DD" dates
+	StartDate string `gorm:"not null;default:''" json:"start_date"`
+	EndDate   string `gorm:"not null;default:''" json:"end_date"`
+	// Timezone is an IANA zone, empty means the shop timezone
+	Timezone string `gorm:"not null;default:''" json:"timezone"`
+}
+
+func (AvailabilityWindow) TableName() string {
+	return "availability_windows"
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Cart is what a customer or guest picked before ordering. It lives in
+// Redis as JSON, not in MySQL, and holds no prices: the cart is priced
+// again every time it is shown and at checkout.
+type Cart struct {
+	OrderType   string     `json:"order_type"`
+	Note        string     `json:"note"`
+	VoucherCode string     `json:"voucher_code"`
+	Items       []CartItem `json:"items"`
+	NextLineID  int        `json:"next_line_id"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// CartItem is one line of a cart, a menu item in one configuration.
+type CartItem struct {
+	LineID      int    `json:"line_id"`
+	MenuID      uint   `json:"menu_id"`
+	VariantID   *uint  `json:"variant_id,omitempty"`
+	ModifierIDs []uint `json:"modifier_ids,omitempty"`
+	Quantity    int    `json:"quantity"`
+}
@@ -9,6 +9,11 @@ type Category struct {
 	// DeletedAt DeletedAt `gorm:"index"`
 	gorm.Model
 	CategoriesName string `gorm:"not null" json:"categories_name"`
+	// ParentID is nil for a top-level category
+	ParentID  *uint `json:"parent_id"`
+	SortOrder int   `gorm:"not null;default:0" json:"sort_order"`
+	// TaxRateID is the tax class, nil inherits the parent's or the default rate
+	TaxRateID *uint `json:"tax_rate_id"`
 }
 
 func (Category) TableName() string {
@@ -0,0 +1,67 @@
+package models
+
+import "gorm.io/gorm"
+
+// Credit note types: a cancellation voids an order before it is handed
+// over, a refund gives money back on a completed one.
+const (
+	CreditNoteCancellation = "cancellation"
+	CreditNoteRefund       = "refund"
+)
+
+// Reason codes of a cancellation or refund, matching the enum of the
+// credit_notes.reason column.
+const (
+	ReasonCustomerRequest = "customer_request"
+	ReasonWrongOrder      = "wrong_order"
+	ReasonQualityIssue    = "quality_issue"
+	ReasonOutOfStock      = "out_of_stock"
+	ReasonDuplicateOrder  = "duplicate_order"
+	ReasonOther           = "other"
+)
+
+// Refund statuses of a credit note.
+const (
+	RefundPending  = "pending"
+	RefundRefunded = "refunded"
+	RefundFailed   = "failed"
+)
+
+// CreditNote records money going back to a customer for an order. Amount
+// is what the customer gets back, Revenue the part of it that was counted
+// as sales revenue, before exclusive tax and service charge.
+type CreditNote struct {
+	gorm.Model
+	OrderID         uint             `gorm:"not null" json:"order_id"`
+	Type            string           `gorm:"not null" json:"type"`
+	Reason          string           `gorm:"not null" json:"reason"`
+	Note            string           `json:"note"`
+	Amount          float64          `gorm:"not null;default:0" json:"amount"`
+	Revenue         float64          `gorm:"not null;default:0" json:"revenue"`
+	RefundMethod    string           `gorm:"not null" json:"refund_method"`
+	RefundStatus    string           `gorm:"not null;default:pending" json:"refund_status"`
+	RefundReference string           `json:"refund_reference"`
+	IssuedBy        *uint            `json:"issued_by"`
+	ShiftID         *uint            `json:"shift_id"`
+	Items           []CreditNoteItem `gorm:"foreignKey:CreditNoteID" json:"items"`
+}
+
+func (CreditNote) TableName() string {
+	return "credit_notes"
+}
+
+// CreditNoteItem is the part of one order line a credit note gives back.
+type CreditNoteItem struct {
+	gorm.Model
+	CreditNoteID    uint    `gorm:"not null" json:"credit_note_id"`
+	OrderMenuItemID uint    `gorm:"not null" json:"order_menu_item_id"`
+	MenuID          *uint   `json:"menu_id"`
+	Quantity        int     `gorm:"not null" json:"quantity"`
+	Amount          float64 `gorm:"not null;default:0" json:"amount"`
+	Revenue         float64 `gorm:"not null;default:0" json:"revenue"`
+	Menu            Menu
+}
+
+func (CreditNoteItem) TableName() string {
+	return "credit_note_items"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Loyalty ledger entry types.
+const (
+	LoyaltyEarn   = "earn"
+	LoyaltyRedeem = "redeem"
+	LoyaltyAdjust = "adjust"
+	LoyaltyExpire = "expire"
+	// LoyaltyRestore gives back the points spent on a reward of a canceled
+	// or fully refunded order
+	LoyaltyRestore = "restore"
+	// LoyaltyReverse takes back points earned on a refunded order
+	LoyaltyReverse = "reverse"
+)
+
+// LoyaltyEntry is one line of a user's points ledger, points are never
+// changed in place. Entries that add points keep in Remaining what was not
+// spent or expired yet; spending takes from the entries that expire first.
+type LoyaltyEntry struct {
+	gorm.Model
+	UserID    uint       `gorm:"not null" json:"user_id"`
+	Type      string     `gorm:"not null" json:"type"`
+	Points    int        `gorm:"not null" json:"points"`
+	Remaining int        `gorm:"not null;default:0" json:"remaining"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	OrderID   *uint      `json:"order_id"`
+	RewardID  *uint      `json:"reward_id"`
+	// AdjustedBy is the admin who made an adjustment, kept for the audit trail
+	AdjustedBy *uint  `json:"adjusted_by"`
+	Note       string `json:"note"`
+}
+
+func (LoyaltyEntry) TableName() string {
+	return "loyalty_entries"
+}
+
+// LoyaltyReward is what points can be redeemed for: one free unit of a
+// menu item, such as the 10th coffee of a stamp card, or an amount off
+// the order.
+type LoyaltyReward struct {
+	gorm.Model
+	Name           string  `gorm:"not null" json:"name"`
+	Points         int     `gorm:"not null" json:"points"`
+	MenuID         *uint   `json:"menu_id"`
+	DiscountAmount float64 `gorm:"not null;default:0" json:"discount_amount"`
+	Active         bool    `gorm:"not null" json:"active"`
+}
+
+func (LoyaltyReward) TableName() string {
+	return "loyalty_rewards"
+}
@@ -12,10 +12,21 @@ type Menu struct {
 	Price       float64 `gorm:"not null" json:"price"`
 	Description string  `json:"description"`
 	ImageURL    string  `json:"image_url"`
-	CategoryID  uint    `gorm:"not null" json:"category_id"`
+	CategoryID  uint    `json:"category_id"`
+	SoldOut     bool    `gorm:"not null;default:false" json:"sold_out"` // manual "86" toggle for items that ran out
 	Category    Category
+	Variants    []MenuVariant  `json:"variants"`
+	Modifiers   []MenuModifier `json:"modifiers"`
 }
 
+// Relations of a menu that can be loaded along with it, named like the
+// ?include= values of the API.
+const (
+	MenuRelationCategory  = "category"
+	MenuRelationVariants  = "variants"
+	MenuRelationModifiers = "modifiers"
+)
+
 func (Menu) TableName() string {
 	return "menu"
 }
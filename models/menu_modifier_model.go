@@ -0,0 +1,16 @@
+package models
+
+import "gorm.io/gorm"
+
+// MenuModifier is an optional add-on for a menu item, e.g. "Extra shot".
+type MenuModifier struct {
+	gorm.Model
+	MenuID       uint    `gorm:"not null" json:"menu_id"`
+	ModifierName string  `gorm:"not null" json:"modifier_name"`
+	Price        float64 `gorm:"not null;default:0" json:"price"`
+	SortOrder    int     `gorm:"not null;default:0" json:"sort_order"`
+}
+
+func (MenuModifier) TableName() string {
+	return "menu_modifiers"
+}
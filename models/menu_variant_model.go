@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// MenuVariant is a size or style of a menu item, e.g. "Large" or "Iced".
+// PriceDelta is added to the menu price.
+type MenuVariant struct {
+	gorm.Model
+	MenuID      uint    `gorm:"not null" json:"menu_id"`
+	VariantName string  `gorm:"not null" json:"variant_name"`
+	PriceDelta  float64 `gorm:"not null;default:0" json:"price_delta"`
+	SortOrder   int     `gorm:"not null;default:0" json:"sort_order"`
+}
+
+func (MenuVariant) TableName() string {
+	return "menu_variants"
+}
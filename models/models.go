@@ -0,0 +1,29 @@
+package models
+
+// All lists every model backed by a table, used by the schema drift check.
+func All() []interface{} {
+	return []interface{}{
+		&User{},
+		&Category{},
+		&Menu{},
+		&MenuVariant{},
+		&MenuModifier{},
+		&AvailabilityWindow{},
+		&Promotion{},
+		&Voucher{},
+		&VoucherRedemption{},
+		&LoyaltyReward{},
+		&LoyaltyEntry{},
+		&TaxRate{},
+		&ServiceChargeRule{},
+		&OrderTax{},
+		&OrderItemModifier{},
+		&Order{},
+		&OrderMenuItem{},
+		&OrderPayment{},
+		&Shift{},
+		&ShiftCashMovement{},
+		&CreditNote{},
+		&CreditNoteItem{},
+	}
+}
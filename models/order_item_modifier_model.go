@@ -0,0 +1,17 @@
+package models
+
+import "gorm.io/gorm"
+
+// OrderItemModifier is a modifier chosen on an order line. Name and price
+// are copied from the menu modifier as ordered.
+type OrderItemModifier struct {
+	gorm.Model
+	OrderMenuItemID uint    `gorm:"not null" json:"order_menu_item_id"`
+	ModifierID      *uint   `json:"modifier_id"`
+	Name            string  `gorm:"not null" json:"name"`
+	Price           float64 `gorm:"not null" json:"price"`
+}
+
+func (OrderItemModifier) TableName() string {
+	return "order_item_modifiers"
+}
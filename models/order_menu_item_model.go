@@ -0,0 +1,25 @@
+package models
+
+import "gorm.io/gorm"
+
+// OrderMenuItem is one line of an order. Price is the unit price with the
+// variant and modifiers included.
+type OrderMenuItem struct {
+	gorm.Model
+	OrderID     uint    `json:"order_id"`
+	MenuID      uint    `json:"menu_id"`
+	VariantID   *uint   `json:"variant_id"`
+	VariantName string  `gorm:"not null" json:"variant_name"`
+	Price       float64 `gorm:"not null" json:"price"`
+	Quantity    int     `gorm:"not null" json:"quantity"`
+	Discount    float64 `gorm:"default:0" json:"discount"`
+	PromotionID *uint   `json:"promotion_id"`
+	TaxRateID   *uint   `json:"tax_rate_id"`
+	Tax         float64 `gorm:"not null;default:0" json:"tax"`
+	Menu        Menu
+	Modifiers   []OrderItemModifier `gorm:"foreignKey:OrderMenuItemID" json:"modifiers"`
+}
+
+func (OrderMenuItem) TableName() string {
+	return "order_menu_items"
+}
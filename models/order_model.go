@@ -1,20 +1,58 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// Order statuses, matching the enum of the orders.status column.
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPreparing = "preparing"
+	OrderStatusCompleted = "completed"
+	OrderStatusCanceled  = "canceled"
+)
+
+// Order types, matching the enum of the orders.order_type column.
+const (
+	OrderTypeTakeaway = "takeaway"
+	OrderTypeDineIn   = "dine_in"
+)
+
 type Order struct {
 	// ID        uint `gorm:"primarykey"`
 	// CreatedAt time.Time
 	// UpdatedAt time.Time
 	// DeletedAt DeletedAt `gorm:"index"`
 	gorm.Model
+	OrderType string `gorm:"not null;default:takeaway" json:"order_type"`
+	// Subtotal is the sum of the lines at menu prices
+	Subtotal float64 `gorm:"not null;default:0" json:"subtotal"`
+	// TotalPrice is what is left after every discount, before exclusive tax and service charge
 	TotalPrice float64 `gorm:"not null" json:"total_price"`
-	Note       string  `json:"note"`
-	Status     string  `gorm:"not null;default:pending" json:"status"`
-	UserID     uint    `gorm:"not null" json:"user_id"`
-	User       User
+	// Discount is the order-level promotion, line discounts are on the items
+	Discount        float64    `gorm:"not null;default:0" json:"discount"`
+	PromotionID     *uint      `json:"promotion_id"`
+	VoucherID       *uint      `json:"voucher_id"`
+	VoucherDiscount float64    `gorm:"not null;default:0" json:"voucher_discount"`
+	LoyaltyRewardID *uint      `json:"loyalty_reward_id"`
+	LoyaltyDiscount float64    `gorm:"not null;default:0" json:"loyalty_discount"`
+	DiscountTotal   float64    `gorm:"not null;default:0" json:"discount_total"`
+	Tax             float64    `gorm:"not null;default:0" json:"tax"`
+	ServiceCharge   float64    `gorm:"not null;default:0" json:"service_charge"`
+	Rounding        float64    `gorm:"not null;default:0" json:"rounding"`
+	GrandTotal      float64    `gorm:"not null;default:0" json:"grand_total"`
+	Note            string     `json:"note"`
+	Status          string     `gorm:"default:pending" json:"status"`
+	CanceledAt      *time.Time `json:"canceled_at"`
+	UserID          uint       `json:"user_id"` // 0 for an anonymous walk-in, stored as NULL
+	CashierID       *uint      `json:"cashier_id"`
+	ShiftID         *uint      `json:"shift_id"`
+	User            User
+	Items           []OrderMenuItem `gorm:"foreignKey:OrderID" json:"items"`
+	Taxes           []OrderTax      `gorm:"foreignKey:OrderID" json:"taxes"`
+	Payments        []OrderPayment  `gorm:"foreignKey:OrderID" json:"payments"`
 }
 
 func (Order) TableName() string {
@@ -0,0 +1,30 @@
+package models
+
+import "gorm.io/gorm"
+
+// Payment methods, matching the enums of the order_payments.method and
+// credit_notes.refund_method columns.
+const (
+	PaymentCash    = "cash"
+	PaymentCard    = "card"
+	PaymentEWallet = "ewallet"
+)
+
+// OrderPayment is one payment taken for an order at the till, an order
+// can be split across several. Amount is what goes to the order; for cash
+// Tendered is what the customer handed over and ChangeDue what they got
+// back.
+type OrderPayment struct {
+	gorm.Model
+	OrderID   uint    `gorm:"not null" json:"order_id"`
+	Method    string  `gorm:"not null" json:"method"`
+	Amount    float64 `gorm:"not null" json:"amount"`
+	Tendered  float64 `gorm:"not null;default:0" json:"tendered"`
+	ChangeDue float64 `gorm:"not null;default:0" json:"change_due"`
+	// Reference is the approval code of a card or e-wallet payment
+	Reference string `json:"reference"`
+}
+
+func (OrderPayment) TableName() string {
+	return "order_payments"
+}
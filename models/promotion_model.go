@@ -0,0 +1,42 @@
+package models
+
+import "gorm.io/gorm"
+
+// Promotion types.
+const (
+	PromotionPercentage = "percentage"
+	PromotionFixed      = "fixed"
+	PromotionBuyXGetY   = "buy_x_get_y"
+)
+
+// Promotion is a discount rule evaluated when an order is priced. A
+// promotion with a MenuID or CategoryID discounts matching lines, one
+// without discounts the whole order; buy-X-get-Y always works per line.
+// The schedule fields follow AvailabilityWindow and are read in the shop
+// timezone, which is how happy hours are expressed.
+type Promotion struct {
+	gorm.Model
+	Name string `gorm:"not null" json:"name"`
+	Type string `gorm:"not null" json:"type"`
+	// Value is a percentage for percentage promotions and an amount for
+	// fixed ones: per unit on lines, once on the order
+	Value       float64 `gorm:"not null;default:0" json:"value"`
+	BuyQuantity int     `gorm:"not null;default:0" json:"buy_quantity"`
+	GetQuantity int     `gorm:"not null;default:0" json:"get_quantity"`
+	MenuID      *uint   `json:"menu_id"`
+	CategoryID  *uint   `json:"category_id"`
+	// MinSpend is compared with the order subtotal before any discount
+	MinSpend   float64 `gorm:"not null;default:0" json:"min_spend"`
+	DaysOfWeek int     `gorm:"not null;default:127" json:"days_of_week"`
+	StartTime  string  `gorm:"not null;default:''" json:"start_time"`
+	EndTime    string  `gorm:"not null;default:''" json:"end_time"`
+	StartDate  string  `gorm:"not null;default:''" json:"start_date"`
+	EndDate    string  `gorm:"not null;default:''" json:"end_date"`
+	// Priority breaks ties between promotions worth the same discount
+	Priority int  `gorm:"not null;default:0" json:"priority"`
+	Active   bool `gorm:"not null" json:"active"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Shift statuses, matching the enum of the shifts.status column.
+const (
+	ShiftOpen   = "open"
+	ShiftClosed = "closed"
+)
+
+// Cash movement types: cash put into the drawer, cash paid out of it for
+// expenses, and cash dropped from it into the safe.
+const (
+	CashIn      = "cash_in"
+	CashPaidOut = "paid_out"
+	CashDrop    = "drop"
+)
+
+// Shift is a cashier's session at the till, from counting the opening
+// float into the drawer to counting the cash at the end. The expected
+// cash, what was counted and the difference are set when it closes.
+type Shift struct {
+	gorm.Model
+	CashierID    uint                `gorm:"not null" json:"cashier_id"`
+	Status       string              `gorm:"not null;default:open" json:"status"`
+	OpeningFloat float64             `gorm:"not null;default:0" json:"opening_float"`
+	OpenedAt     time.Time           `gorm:"not null" json:"opened_at"`
+	ClosedAt     *time.Time          `json:"closed_at"`
+	ExpectedCash *float64            `json:"expected_cash"`
+	CountedCash  *float64            `json:"counted_cash"`
+	Discrepancy  *float64            `json:"discrepancy"`
+	ClosingNote  string              `json:"closing_note"`
+	Movements    []ShiftCashMovement `gorm:"foreignKey:ShiftID" json:"movements"`
+}
+
+func (Shift) TableName() string {
+	return "shifts"
+}
+
+// ShiftCashMovement is cash going into or out of the drawer other than for
+// orders. Amount is always positive, Type tells the direction.
+type ShiftCashMovement struct {
+	gorm.Model
+	ShiftID    uint    `gorm:"not null" json:"shift_id"`
+	Type       string  `gorm:"not null" json:"type"`
+	Amount     float64 `gorm:"not null" json:"amount"`
+	Note       string  `json:"note"`
+	RecordedBy *uint   `json:"recorded_by"`
+}
+
+func (ShiftCashMovement) TableName() string {
+	return "shift_cash_movements"
+}
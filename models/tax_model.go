@@ -0,0 +1,52 @@
+package models
+
+import "gorm.io/gorm"
+
+// TaxRate is a named tax such as PPN 11%. Inclusive rates are already part
+// of menu prices, exclusive ones are added on top. Categories pick their
+// rate as their tax class, the default rate covers everything else.
+type TaxRate struct {
+	gorm.Model
+	Name      string  `gorm:"not null" json:"name"`
+	Rate      float64 `gorm:"not null" json:"rate"`
+	Inclusive bool    `gorm:"not null" json:"inclusive"`
+	IsDefault bool    `gorm:"not null" json:"is_default"`
+}
+
+func (TaxRate) TableName() string {
+	return "tax_rates"
+}
+
+// ServiceChargeRule charges a percentage of what is left to pay after
+// discounts and before tax on orders of one type, typically dine-in.
+type ServiceChargeRule struct {
+	gorm.Model
+	Name      string  `gorm:"not null" json:"name"`
+	OrderType string  `gorm:"not null" json:"order_type"`
+	Rate      float64 `gorm:"not null" json:"rate"`
+	// Taxable charges the default tax rate on the service charge too
+	Taxable bool `gorm:"not null" json:"taxable"`
+	Active  bool `gorm:"not null" json:"active"`
+}
+
+func (ServiceChargeRule) TableName() string {
+	return "service_charge_rules"
+}
+
+// OrderTax is one line of the tax breakdown of an order. Name, rate and
+// inclusiveness are copied from the tax rate, so receipts stay the same
+// when the rate changes later.
+type OrderTax struct {
+	gorm.Model
+	OrderID       uint    `json:"order_id"`
+	TaxRateID     *uint   `json:"tax_rate_id"`
+	Name          string  `gorm:"not null" json:"name"`
+	Rate          float64 `gorm:"not null" json:"rate"`
+	Inclusive     bool    `gorm:"not null" json:"inclusive"`
+	TaxableAmount float64 `gorm:"not null" json:"taxable_amount"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+}
+
+func (OrderTax) TableName() string {
+	return "order_taxes"
+}
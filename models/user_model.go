@@ -9,10 +9,10 @@ type User struct {
 	// DeletedAt DeletedAt `gorm:"index"`
 	gorm.Model
 	Name        string `gorm:"not null" json:"name"`
-	Email       string `gorm:"unique;not null" json:"email"`
+	Email       string `gorm:"not null" json:"email"` // unique index dropped in 000007, uniqueness is checked among non-deleted users
 	Password    string `gorm:"not null" json:"password"`
-	Role        string `gorm:"not null;default:customer" json:"role"` // enum('admin','cashier','customer')
-	PhoneNumber string `gorm:"unique;not null" json:"phone_number"`
+	Role        string `gorm:"default:customer" json:"role"` // enum('admin','cashier','customer')
+	PhoneNumber string `json:"phone_number"`
 }
 
 func (User) TableName() string {
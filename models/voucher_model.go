@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Voucher is a code a customer enters at checkout. Its discount is
+// applied after promotions, on the lines of its categories when it has
+// any and on the whole order otherwise.
+type Voucher struct {
+	gorm.Model
+	Code        string  `gorm:"not null;unique" json:"code"` // uppercase, deleted vouchers keep their codes
+	Description string  `json:"description"`
+	Type        string  `gorm:"not null" json:"type"` // PromotionPercentage or PromotionFixed
+	Value       float64 `gorm:"not null;default:0" json:"value"`
+	// MaxDiscount caps a percentage discount, 0 means no cap
+	MaxDiscount float64 `gorm:"not null;default:0" json:"max_discount"`
+	// MinSpend is compared with the order subtotal before any discount
+	MinSpend   float64    `gorm:"not null;default:0" json:"min_spend"`
+	ValidFrom  *time.Time `json:"valid_from"`
+	ValidUntil *time.Time `json:"valid_until"`
+	// MaxRedemptions and MaxPerUser are 0 when unlimited
+	MaxRedemptions  int        `gorm:"not null;default:0" json:"max_redemptions"`
+	MaxPerUser      int        `gorm:"not null;default:0" json:"max_per_user"`
+	RedemptionCount int        `gorm:"not null;default:0" json:"redemption_count"`
+	FirstOrderOnly  bool       `gorm:"not null;default:false" json:"first_order_only"`
+	Active          bool       `gorm:"not null" json:"active"`
+	Categories      []Category `gorm:"many2many:voucher_categories" json:"categories"`
+}
+
+func (Voucher) TableName() string {
+	return "vouchers"
+}
+
+// VoucherRedemption records one use of a voucher by an order.
+type VoucherRedemption struct {
+	gorm.Model
+	VoucherID uint    `gorm:"not null" json:"voucher_id"`
+	UserID    uint    `gorm:"not null" json:"user_id"`
+	OrderID   uint    `gorm:"not null" json:"order_id"`
+	Discount  float64 `gorm:"not null" json:"discount"`
+}
+
+func (VoucherRedemption) TableName() string {
+	return "voucher_redemptions"
+}
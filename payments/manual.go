@@ -0,0 +1,17 @@
+package payments
+
+import "context"
+
+// ManualGateway is for refunds staff pay out themselves: cash from the
+// till, or a refund on the card terminal or e-wallet app. Nothing is sent
+// anywhere, the refund counts as done and its reference is our own.
+type ManualGateway struct{}
+
+func NewManualGateway() *ManualGateway {
+	return &ManualGateway{}
+}
+
+// Refund implements Gateway.
+func (m *ManualGateway) Refund(ctx context.Context, refund Refund) (string, error) {
+	return refund.Reference, nil
+}
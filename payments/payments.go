@@ -0,0 +1,18 @@
+package payments
+
+import "context"
+
+// Refund asks for money to go back to a customer.
+type Refund struct {
+	// Reference identifies the refund on our side, the credit note number
+	Reference string
+	OrderID   uint
+	Method    string
+	Amount    float64
+}
+
+// Gateway is the payment layer refunds go through. Refund returns the
+// reference of the refund on the side of the gateway.
+type Gateway interface {
+	Refund(ctx context.Context, refund Refund) (string, error)
+}
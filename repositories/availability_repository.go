@@ -0,0 +1,87 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+
+	"gorm.io/gorm"
+)
+
+type AvailabilityRepository interface {
+	GetAllWindows() ([]models.AvailabilityWindow, error)
+	FindByMenuID(menuID uint) ([]models.AvailabilityWindow, error)
+	FindByCategoryID(categoryID uint) ([]models.AvailabilityWindow, error)
+	ReplaceMenuWindows(menuID uint, windows []models.AvailabilityWindow) error
+	ReplaceCategoryWindows(categoryID uint, windows []models.AvailabilityWindow) error
+}
+
+type availabilityRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetAllWindows implements AvailabilityRepository.
+func (a *availabilityRepositoryImpl) GetAllWindows() ([]models.AvailabilityWindow, error) {
+	var windows []models.AvailabilityWindow
+	result := a.DB.Where("deleted_at IS NULL").Order("id").Find(&windows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return windows, nil
+}
+
+// FindByMenuID implements AvailabilityRepository.
+func (a *availabilityRepositoryImpl) FindByMenuID(menuID uint) ([]models.AvailabilityWindow, error) {
+	var windows []models.AvailabilityWindow
+	result := a.DB.Where("menu_id = ?", menuID).Where("deleted_at IS NULL").Order("id").Find(&windows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return windows, nil
+}
+
+// FindByCategoryID implements AvailabilityRepository.
+func (a *availabilityRepositoryImpl) FindByCategoryID(categoryID uint) ([]models.AvailabilityWindow, error) {
+	var windows []models.AvailabilityWindow
+	result := a.DB.Where("category_id = ?", categoryID).Where("deleted_at IS NULL").Order("id").Find(&windows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return windows, nil
+}
+
+// ReplaceMenuWindows implements AvailabilityRepository.
+func (a *availabilityRepositoryImpl) ReplaceMenuWindows(menuID uint, windows []models.AvailabilityWindow) error {
+	for i := range windows {
+		windows[i].MenuID = &menuID
+		windows[i].CategoryID = nil
+	}
+	return a.replace("menu_id", menuID, windows)
+}
+
+// ReplaceCategoryWindows implements AvailabilityRepository.
+func (a *availabilityRepositoryImpl) ReplaceCategoryWindows(categoryID uint, windows []models.AvailabilityWindow) error {
+	for i := range windows {
+		windows[i].MenuID = nil
+		windows[i].CategoryID = &categoryID
+	}
+	return a.replace("category_id", categoryID, windows)
+}
+
+// replace swaps the whole schedule of one menu or category in a single
+// transaction, so readers never see it half written.
+func (a *availabilityRepositoryImpl) replace(column string, id uint, windows []models.AvailabilityWindow) error {
+	return a.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where(column+" = ?", id).Where("deleted_at IS NULL").Delete(&models.AvailabilityWindow{}).Error; err != nil {
+			return err
+		}
+		if len(windows) == 0 {
+			return nil
+		}
+		return tx.Create(&windows).Error
+	})
+}
+
+func NewAvailabilityRepository(db *gorm.DB) AvailabilityRepository {
+	return &availabilityRepositoryImpl{
+		DB: db,
+	}
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"coffee_shop/cache"
+	"coffee_shop/models"
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cachedAvailabilityRepository caches the schedules every menu read
+// evaluates. They live in the menu namespace, a schedule change is a menu
+// change for every client.
+type cachedAvailabilityRepository struct {
+	AvailabilityRepository
+	rdb redis.UniversalClient
+	ttl time.Duration
+}
+
+// GetAllWindows implements AvailabilityRepository.
+func (a *cachedAvailabilityRepository) GetAllWindows() ([]models.AvailabilityWindow, error) {
+	return cache.ReadThrough(context.Background(), a.rdb, cache.MenuNamespace, "availability", a.ttl, a.AvailabilityRepository.GetAllWindows)
+}
+
+// ReplaceMenuWindows implements AvailabilityRepository.
+func (a *cachedAvailabilityRepository) ReplaceMenuWindows(menuID uint, windows []models.AvailabilityWindow) error {
+	if err := a.AvailabilityRepository.ReplaceMenuWindows(menuID, windows); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, a.rdb)
+	return nil
+}
+
+// ReplaceCategoryWindows implements AvailabilityRepository.
+func (a *cachedAvailabilityRepository) ReplaceCategoryWindows(categoryID uint, windows []models.AvailabilityWindow) error {
+	if err := a.AvailabilityRepository.ReplaceCategoryWindows(categoryID, windows); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, a.rdb)
+	return nil
+}
+
+// NewCachedAvailabilityRepository wraps repo with a Redis read cache. A
+// ttl of 0 disables caching and returns repo unchanged.
+func NewCachedAvailabilityRepository(repo AvailabilityRepository, rdb redis.UniversalClient, ttl time.Duration) AvailabilityRepository {
+	if ttl <= 0 {
+		return repo
+	}
+	return &cachedAvailabilityRepository{
+		AvailabilityRepository: repo,
+		rdb:                    rdb,
+		ttl:                    ttl,
+	}
+}
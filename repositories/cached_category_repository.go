@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"coffee_shop/cache"
+	"coffee_shop/models"
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cachedCategoryRepository is a read-through cache in front of a
+// CategoryRepository, see cachedMenuRepository. Cached menus can embed
+// their category, so every category write drops the menu cache too.
+type cachedCategoryRepository struct {
+	CategoryRepository
+	rdb redis.UniversalClient
+	ttl time.Duration
+}
+
+// GetAllCategories implements CategoryRepository.
+func (c *cachedCategoryRepository) GetAllCategories() ([]models.Category, error) {
+	return cache.ReadThrough(context.Background(), c.rdb, cache.CategoryNamespace, "all", c.ttl, c.CategoryRepository.GetAllCategories)
+}
+
+// CreateCategory implements CategoryRepository.
+func (c *cachedCategoryRepository) CreateCategory(category *models.Category) error {
+	if err := c.CategoryRepository.CreateCategory(category); err != nil {
+		return err
+	}
+	invalidate(cache.CategoryNamespace, c.rdb)
+	invalidate(cache.MenuNamespace, c.rdb)
+	return nil
+}
+
+// UpdateCategory implements CategoryRepository.
+func (c *cachedCategoryRepository) UpdateCategory(category *models.Category) error {
+	if err := c.CategoryRepository.UpdateCategory(category); err != nil {
+		return err
+	}
+	invalidate(cache.CategoryNamespace, c.rdb)
+	invalidate(cache.MenuNamespace, c.rdb)
+	return nil
+}
+
+// DeleteCategory implements CategoryRepository.
+// Menu items may move or go with the category, so both caches are dropped.
+func (c *cachedCategoryRepository) DeleteCategory(id uint, reassignTo *uint, cascade bool) (CategoryDeletion, error) {
+	deletion, err := c.CategoryRepository.DeleteCategory(id, reassignTo, cascade)
+	if err != nil {
+		return deletion, err
+	}
+	invalidate(cache.CategoryNamespace, c.rdb)
+	invalidate(cache.MenuNamespace, c.rdb)
+	return deletion, nil
+}
+
+// Reorder implements CategoryRepository.
+func (c *cachedCategoryRepository) Reorder(categories []models.Category) error {
+	if err := c.CategoryRepository.Reorder(categories); err != nil {
+		return err
+	}
+	invalidate(cache.CategoryNamespace, c.rdb)
+	invalidate(cache.MenuNamespace, c.rdb)
+	return nil
+}
+
+// NewCachedCategoryRepository wraps repo with a Redis read cache. A ttl of
+// 0 disables caching and returns repo unchanged.
+func NewCachedCategoryRepository(repo CategoryRepository, rdb redis.UniversalClient, ttl time.Duration) CategoryRepository {
+	if ttl <= 0 {
+		return repo
+	}
+	return &cachedCategoryRepository{
+		CategoryRepository: repo,
+		rdb:                rdb,
+		ttl:                ttl,
+	}
+}
@@ -0,0 +1,135 @@
+package repositories
+
+import (
+	"coffee_shop/cache"
+	"coffee_shop/models"
+	"context"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// cachedMenuRepository is a read-through cache in front of a
+// MenuRepository. Only the list reads every client polls are cached;
+// writes invalidate them by bumping the namespace version.
+type cachedMenuRepository struct {
+	MenuRepository
+	rdb redis.UniversalClient
+	ttl time.Duration
+}
+
+// GetAllMenus implements MenuRepository.
+// Every combination of includes is cached under its own key.
+func (m *cachedMenuRepository) GetAllMenus(includes ...string) ([]models.Menu, error) {
+	name := "all"
+	if len(includes) > 0 {
+		sorted := slices.Clone(includes)
+		slices.Sort(sorted)
+		name += ":" + strings.Join(slices.Compact(sorted), ",")
+	}
+	return cache.ReadThrough(context.Background(), m.rdb, cache.MenuNamespace, name, m.ttl, func() ([]models.Menu, error) {
+		return m.MenuRepository.GetAllMenus(includes...)
+	})
+}
+
+// CountByCategory implements MenuRepository.
+func (m *cachedMenuRepository) CountByCategory() (map[uint]int64, error) {
+	return cache.ReadThrough(context.Background(), m.rdb, cache.MenuNamespace, "count_by_category", m.ttl, m.MenuRepository.CountByCategory)
+}
+
+// CreateMenu implements MenuRepository.
+func (m *cachedMenuRepository) CreateMenu(menu *models.Menu) error {
+	if err := m.MenuRepository.CreateMenu(menu); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// UpdateMenu implements MenuRepository.
+func (m *cachedMenuRepository) UpdateMenu(id uint, menu *models.Menu) error {
+	if err := m.MenuRepository.UpdateMenu(id, menu); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// DeleteMenu implements MenuRepository.
+func (m *cachedMenuRepository) DeleteMenu(id uint) error {
+	if err := m.MenuRepository.DeleteMenu(id); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// SetSoldOut implements MenuRepository.
+func (m *cachedMenuRepository) SetSoldOut(id uint, soldOut bool) error {
+	if err := m.MenuRepository.SetSoldOut(id, soldOut); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// SaveVariant implements MenuRepository.
+func (m *cachedMenuRepository) SaveVariant(variant *models.MenuVariant) error {
+	if err := m.MenuRepository.SaveVariant(variant); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// DeleteVariant implements MenuRepository.
+func (m *cachedMenuRepository) DeleteVariant(menuID uint, id uint) error {
+	if err := m.MenuRepository.DeleteVariant(menuID, id); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// SaveModifier implements MenuRepository.
+func (m *cachedMenuRepository) SaveModifier(modifier *models.MenuModifier) error {
+	if err := m.MenuRepository.SaveModifier(modifier); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// DeleteModifier implements MenuRepository.
+func (m *cachedMenuRepository) DeleteModifier(menuID uint, id uint) error {
+	if err := m.MenuRepository.DeleteModifier(menuID, id); err != nil {
+		return err
+	}
+	invalidate(cache.MenuNamespace, m.rdb)
+	return nil
+}
+
+// invalidate drops a cached namespace after a write. The write has
+// already happened, so a Redis failure is only logged and the stale
+// entry lives until its TTL.
+func invalidate(namespace string, rdb redis.UniversalClient) {
+	if err := cache.Invalidate(context.Background(), rdb, namespace); err != nil {
+		log.Default().Println("Failed to invalidate " + namespace + " cache: " + err.Error())
+	}
+}
+
+// NewCachedMenuRepository wraps repo with a Redis read cache. A ttl of 0
+// disables caching and returns repo unchanged.
+func NewCachedMenuRepository(repo MenuRepository, rdb redis.UniversalClient, ttl time.Duration) MenuRepository {
+	if ttl <= 0 {
+		return repo
+	}
+	return &cachedMenuRepository{
+		MenuRepository: repo,
+		rdb:            rdb,
+		ttl:            ttl,
+	}
+}
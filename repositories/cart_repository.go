@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Carts are not under cache.KeyPrefix, flushing the cache keeps them.
+const cartKeyPrefix = "cart:"
+
+// cartMaxRetries bounds the optimistic retries of a cart update.
+const cartMaxRetries = 5
+
+// ErrCartBusy is returned when a cart kept changing under an update.
+var ErrCartBusy = errors.New("cart is being changed by another request, try again")
+
+type CartRepository interface {
+	GetCart(ctx context.Context, owner string) (*models.Cart, error)
+	UpdateCart(ctx context.Context, owner string, update func(cart *models.Cart) error) (*models.Cart, error)
+	DeleteCart(ctx context.Context, owner string) error
+}
+
+type cartRepositoryImpl struct {
+	rdb redis.UniversalClient
+	ttl time.Duration
+}
+
+// GetCart implements CartRepository.
+// A cart that does not exist is returned empty.
+func (r *cartRepositoryImpl) GetCart(ctx context.Context, owner string) (*models.Cart, error) {
+	return readCart(ctx, r.rdb, cartKeyPrefix+owner)
+}
+
+// UpdateCart implements CartRepository.
+// update runs on the current cart and the result is written back only if
+// nobody changed the cart in between, otherwise update runs again on the
+// new cart. An error from update leaves the cart as it was. Every write
+// extends the TTL of the cart.
+func (r *cartRepositoryImpl) UpdateCart(ctx context.Context, owner string, update func(cart *models.Cart) error) (*models.Cart, error) {
+	key := cartKeyPrefix + owner
+	var cart *models.Cart
+	for range cartMaxRetries {
+		err := r.rdb.Watch(ctx, func(tx *redis.Tx) error {
+			var err error
+			if cart, err = readCart(ctx, tx, key); err != nil {
+				return err
+			}
+			if err := update(cart); err != nil {
+				return err
+			}
+			cart.UpdatedAt = time.Now()
+			data, err := json.Marshal(cart)
+			if err != nil {
+				return err
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				pipe.Set(ctx, key, data, r.ttl)
+				return nil
+			})
+			return err
+		}, key)
+		if errors.Is(err, redis.TxFailedErr) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return cart, nil
+	}
+	return nil, ErrCartBusy
+}
+
+// DeleteCart implements CartRepository.
+func (r *cartRepositoryImpl) DeleteCart(ctx context.Context, owner string) error {
+	return r.rdb.Del(ctx, cartKeyPrefix+owner).Err()
+}
+
+func readCart(ctx context.Context, rdb redis.Cmdable, key string) (*models.Cart, error) {
+	data, err := rdb.Get(ctx, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return &models.Cart{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var cart models.Cart
+	if err := json.Unmarshal(data, &cart); err != nil {
+		return nil, errors.New("failed to decode cart: " + err.Error())
+	}
+	return &cart, nil
+}
+
+// NewCartRepository stores carts in rdb, each for ttl after its last
+// change.
+func NewCartRepository(rdb redis.UniversalClient, ttl time.Duration) CartRepository {
+	return &cartRepositoryImpl{
+		rdb: rdb,
+		ttl: ttl,
+	}
+}
@@ -2,18 +2,38 @@ package repositories
 
 import (
 	"coffee_shop/models"
+	"errors"
+	"slices"
 
 	"gorm.io/gorm"
 )
 
+var (
+	// ErrCategoryInUse is returned when a category that still has menu
+	// items or subcategories is deleted without reassignment or cascade.
+	ErrCategoryInUse = errors.New("category still has menu items or subcategories")
+	// ErrInvalidCategoryTarget is returned when the reassignment target
+	// does not exist or is the deleted category or one below it.
+	ErrInvalidCategoryTarget = errors.New("invalid reassignment target category")
+)
+
+// CategoryDeletion reports what deleting a category changed.
+type CategoryDeletion struct {
+	MovedItems        int64
+	MovedCategories   int64
+	DeletedItems      int64
+	DeletedCategories int64
+}
+
 type CategoryRepository interface {
 	// Define category-related data access methods here
 	CreateCategory(category *models.Category) error
 	GetCategoryByID(id uint) (models.Category, error)
 	UpdateCategory(category *models.Category) error
-	DeleteCategory(id uint) error
+	DeleteCategory(id uint, reassignTo *uint, cascade bool) (CategoryDeletion, error)
 	FindByName(name string) (*models.Category, error)
 	GetAllCategories() ([]models.Category, error)
+	Reorder(categories []models.Category) error
 }
 
 type categoryRepositoryImpl struct {
@@ -21,9 +41,10 @@ type categoryRepositoryImpl struct {
 }
 
 // GetAllCategories implements CategoryRepository.
+// Categories come back in display order.
 func (c *categoryRepositoryImpl) GetAllCategories() ([]models.Category, error) {
 	var categories []models.Category
-	result := c.DB.Where("deleted_at is NULL").Find(&categories)
+	result := c.DB.Where("deleted_at is NULL").Order("sort_order, id").Find(&categories)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -46,12 +67,89 @@ func (c *categoryRepositoryImpl) CreateCategory(category *models.Category) error
 }
 
 // DeleteCategory implements CategoryRepository.
-func (c *categoryRepositoryImpl) DeleteCategory(id uint) error {
-	result := c.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.Category{})
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
+// A category that still has menu items or subcategories is only deleted
+// when reassignTo is set, which moves both below the target category, or
+// when cascade is set, which deletes the whole subtree with its items.
+// Everything runs in one transaction.
+func (c *categoryRepositoryImpl) DeleteCategory(id uint, reassignTo *uint, cascade bool) (CategoryDeletion, error) {
+	var deletion CategoryDeletion
+	err := c.DB.Transaction(func(tx *gorm.DB) error {
+		var category models.Category
+		if err := tx.Where("id = ?", id).Where("deleted_at IS NULL").First(&category).Error; err != nil {
+			return err
+		}
+		subtree, err := subtreeIDs(tx, id)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case reassignTo != nil:
+			if slices.Contains(subtree, *reassignTo) {
+				return ErrInvalidCategoryTarget
+			}
+			var target models.Category
+			err := tx.Where("id = ?", *reassignTo).Where("deleted_at IS NULL").First(&target).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrInvalidCategoryTarget
+			}
+			if err != nil {
+				return err
+			}
+
+			result := tx.Model(&models.Menu{}).Where("category_id = ?", id).Where("deleted_at IS NULL").Update("category_id", target.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			deletion.MovedItems = result.RowsAffected
+
+			result = tx.Model(&models.Category{}).Where("parent_id = ?", id).Where("deleted_at IS NULL").Update("parent_id", target.ID)
+			if result.Error != nil {
+				return result.Error
+			}
+			deletion.MovedCategories = result.RowsAffected
+			subtree = []uint{id}
+
+		case cascade:
+			result := tx.Where("category_id IN ?", subtree).Where("deleted_at IS NULL").Delete(&models.Menu{})
+			if result.Error != nil {
+				return result.Error
+			}
+			deletion.DeletedItems = result.RowsAffected
+
+		default:
+			var items int64
+			if err := tx.Model(&models.Menu{}).Where("category_id = ?", id).Where("deleted_at IS NULL").Count(&items).Error; err != nil {
+				return err
+			}
+			if items > 0 || len(subtree) > 1 {
+				return ErrCategoryInUse
+			}
+		}
+
+		result := tx.Where("id IN ?", subtree).Where("deleted_at IS NULL").Delete(&models.Category{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deletion.DeletedCategories = result.RowsAffected
+		return nil
+	})
+	return deletion, err
+}
+
+// subtreeIDs returns id followed by the IDs of every category below it.
+func subtreeIDs(tx *gorm.DB, id uint) ([]uint, error) {
+	ids := []uint{id}
+	for frontier := ids; len(frontier) > 0; {
+		var children []uint
+		err := tx.Model(&models.Category{}).Where("parent_id IN ?", frontier).Where("deleted_at IS NULL").Pluck("id", &children).Error
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, children...)
+		frontier = children
 	}
-	return result.Error
+	return ids, nil
 }
 
 // GetCategoryByID implements CategoryRepository.
@@ -69,6 +167,24 @@ func (c *categoryRepositoryImpl) UpdateCategory(category *models.Category) error
 	return c.DB.Save(category).Error
 }
 
+// Reorder implements CategoryRepository.
+// It saves the parent and position of every given category in one
+// transaction, so a drag-and-drop never leaves the tree half moved.
+func (c *categoryRepositoryImpl) Reorder(categories []models.Category) error {
+	return c.DB.Transaction(func(tx *gorm.DB) error {
+		for _, category := range categories {
+			result := tx.Model(&models.Category{}).Where("id = ?", category.ID).Where("deleted_at IS NULL").Updates(map[string]interface{}{
+				"parent_id":  category.ParentID,
+				"sort_order": category.SortOrder,
+			})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+}
+
 // UpdateCategory implements CategoryRepository.
 // func (c *categoryRepositoryImpl) UpdateCategory(id uint, category *models.Category) error {
 // 	result := c.DB.Where("id = ?", id).Where("deleted_at IS NULL").Updates(category)
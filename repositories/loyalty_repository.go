@@ -0,0 +1,240 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInsufficientPoints is returned when a user spends more points than
+// they have available.
+var ErrInsufficientPoints = errors.New("not enough loyalty points")
+
+type LoyaltyRepository interface {
+	GetBalance(userID uint, now time.Time) (int, error)
+	GetEntries(userID uint, limit, offset int) ([]models.LoyaltyEntry, int64, error)
+	AddEntry(entry *models.LoyaltyEntry, now time.Time) error
+	ExpirePoints(now time.Time) (int, error)
+	GetAllRewards(activeOnly bool) ([]models.LoyaltyReward, error)
+	GetRewardByID(id uint) (models.LoyaltyReward, error)
+	CreateReward(reward *models.LoyaltyReward) error
+	UpdateReward(reward *models.LoyaltyReward) error
+	DeleteReward(id uint) error
+}
+
+type loyaltyRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetBalance implements LoyaltyRepository.
+// The balance is what is left of the entries that have not expired yet,
+// so points past their expiry are gone even before the expiry job ran.
+func (l *loyaltyRepositoryImpl) GetBalance(userID uint, now time.Time) (int, error) {
+	return availablePoints(l.DB, userID, now)
+}
+
+func availablePoints(db *gorm.DB, userID uint, now time.Time) (int, error) {
+	var balance int
+	result := db.Model(&models.LoyaltyEntry{}).
+		Select("COALESCE(SUM(remaining), 0)").
+		Where("user_id = ? AND remaining > 0", userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Where("deleted_at IS NULL").
+		Scan(&balance)
+	return balance, result.Error
+}
+
+// GetEntries implements LoyaltyRepository.
+// Entries come newest first with the total count for paging.
+func (l *loyaltyRepositoryImpl) GetEntries(userID uint, limit, offset int) ([]models.LoyaltyEntry, int64, error) {
+	var total int64
+	query := l.DB.Model(&models.LoyaltyEntry{}).Where("user_id = ?", userID).Where("deleted_at IS NULL")
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var entries []models.LoyaltyEntry
+	result := query.Order("created_at DESC, id DESC").Limit(limit).Offset(offset).Find(&entries)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return entries, total, nil
+}
+
+// AddEntry implements LoyaltyRepository.
+// Positive entries add spendable points; negative ones spend the user's
+// oldest points first and fail with ErrInsufficientPoints when the
+// balance is too low.
+func (l *loyaltyRepositoryImpl) AddEntry(entry *models.LoyaltyEntry, now time.Time) error {
+	return l.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, entry.UserID); err != nil {
+			return err
+		}
+		if entry.Points < 0 {
+			if err := spendPoints(tx, entry.UserID, -entry.Points, now); err != nil {
+				return err
+			}
+			entry.Remaining = 0
+		} else {
+			entry.Remaining = entry.Points
+		}
+		return tx.Create(entry).Error
+	})
+}
+
+// ExpirePoints implements LoyaltyRepository.
+// Every entry past its expiry with points left gets an expire entry for
+// them, user by user, and the number of points expired is returned. It is
+// safe to run on several instances at once.
+func (l *loyaltyRepositoryImpl) ExpirePoints(now time.Time) (int, error) {
+	var userIDs []uint
+	result := l.DB.Model(&models.LoyaltyEntry{}).
+		Distinct("user_id").
+		Where("remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", now).
+		Where("deleted_at IS NULL").
+		Pluck("user_id", &userIDs)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	expired := 0
+	for _, userID := range userIDs {
+		err := l.DB.Transaction(func(tx *gorm.DB) error {
+			if err := lockUser(tx, userID); err != nil {
+				return err
+			}
+			var entries []models.LoyaltyEntry
+			err := tx.Where("user_id = ? AND remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", userID, now).
+				Where("deleted_at IS NULL").
+				Find(&entries).Error
+			if err != nil {
+				return err
+			}
+			for _, entry := range entries {
+				if err := tx.Model(&models.LoyaltyEntry{}).Where("id = ?", entry.ID).Update("remaining", 0).Error; err != nil {
+					return err
+				}
+				expiry := models.LoyaltyEntry{
+					UserID: userID,
+					Type:   models.LoyaltyExpire,
+					Points: -entry.Remaining,
+					Note:   fmt.Sprintf("points of entry %d expired", entry.ID),
+				}
+				if err := tx.Create(&expiry).Error; err != nil {
+					return err
+				}
+				expired += entry.Remaining
+			}
+			return nil
+		})
+		if err != nil {
+			return expired, err
+		}
+	}
+	return expired, nil
+}
+
+// GetAllRewards implements LoyaltyRepository.
+func (l *loyaltyRepositoryImpl) GetAllRewards(activeOnly bool) ([]models.LoyaltyReward, error) {
+	var rewards []models.LoyaltyReward
+	query := l.DB.Where("deleted_at IS NULL")
+	if activeOnly {
+		query = query.Where("active = ?", true)
+	}
+	result := query.Order("points ASC, id ASC").Find(&rewards)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return rewards, nil
+}
+
+// GetRewardByID implements LoyaltyRepository.
+func (l *loyaltyRepositoryImpl) GetRewardByID(id uint) (models.LoyaltyReward, error) {
+	var reward models.LoyaltyReward
+	result := l.DB.Where("id = ?", id).Where("deleted_at IS NULL").First(&reward)
+	if result.Error != nil {
+		return models.LoyaltyReward{}, result.Error
+	}
+	return reward, nil
+}
+
+// CreateReward implements LoyaltyRepository.
+func (l *loyaltyRepositoryImpl) CreateReward(reward *models.LoyaltyReward) error {
+	return l.DB.Create(reward).Error
+}
+
+// UpdateReward implements LoyaltyRepository.
+func (l *loyaltyRepositoryImpl) UpdateReward(reward *models.LoyaltyReward) error {
+	return l.DB.Save(reward).Error
+}
+
+// DeleteReward implements LoyaltyRepository.
+func (l *loyaltyRepositoryImpl) DeleteReward(id uint) error {
+	result := l.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.LoyaltyReward{})
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// orderEntries returns the ledger entries of an order, oldest first.
+func orderEntries(db *gorm.DB, orderID uint) ([]models.LoyaltyEntry, error) {
+	var entries []models.LoyaltyEntry
+	result := db.Where("order_id = ?", orderID).Where("deleted_at IS NULL").Order("id").Find(&entries)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return entries, nil
+}
+
+// lockUser serializes the ledger changes of one user, so two spends cannot
+// both see the same balance. Like lockVoucher it must run before an order
+// referencing the user is inserted.
+func lockUser(tx *gorm.DB, userID uint) error {
+	var user models.User
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", userID).First(&user).Error
+}
+
+// spendPoints takes points from the user's entries that expire first,
+// entries that never expire last. The user must be locked.
+func spendPoints(tx *gorm.DB, userID uint, points int, now time.Time) error {
+	var entries []models.LoyaltyEntry
+	err := tx.Where("user_id = ? AND remaining > 0", userID).
+		Where("expires_at IS NULL OR expires_at > ?", now).
+		Where("deleted_at IS NULL").
+		Order("expires_at IS NULL, expires_at ASC, id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return err
+	}
+
+	available := 0
+	for _, entry := range entries {
+		available += entry.Remaining
+	}
+	if available < points {
+		return fmt.Errorf("%w: %d available, %d needed", ErrInsufficientPoints, available, points)
+	}
+
+	for _, entry := range entries {
+		if points == 0 {
+			break
+		}
+		used := min(points, entry.Remaining)
+		if err := tx.Model(&models.LoyaltyEntry{}).Where("id = ?", entry.ID).Update("remaining", entry.Remaining-used).Error; err != nil {
+			return err
+		}
+		points -= used
+	}
+	return nil
+}
+
+func NewLoyaltyRepository(db *gorm.DB) LoyaltyRepository {
+	return &loyaltyRepositoryImpl{
+		DB: db,
+	}
+}
@@ -8,23 +8,53 @@ import (
 
 type MenuRepository interface {
 	// Define menu-related data access methods here
-	GetAllMenus() ([]models.Menu, error)
+	GetAllMenus(includes ...string) ([]models.Menu, error)
 	CreateMenu(menu *models.Menu) error
 	UpdateMenu(id uint, menu *models.Menu) error
+	UpdateMenuColumns(id uint, menu *models.Menu, columns ...string) error
 	DeleteMenu(id uint) error
-	GetMenuByID(id uint) (*models.Menu, error)
+	GetMenuByID(id uint, includes ...string) (*models.Menu, error)
 	FindByName(name string) (*models.Menu, error)
 	FindByCategoryID(category_id uint) ([]models.Menu, error)
+	GetImageURLs() ([]string, error)
+	CountByCategory() (map[uint]int64, error)
+	SetSoldOut(id uint, soldOut bool) error
+	GetVariant(menuID uint, id uint) (models.MenuVariant, error)
+	SaveVariant(variant *models.MenuVariant) error
+	DeleteVariant(menuID uint, id uint) error
+	GetModifier(menuID uint, id uint) (models.MenuModifier, error)
+	SaveModifier(modifier *models.MenuModifier) error
+	DeleteModifier(menuID uint, id uint) error
 }
 
 type MenuRepositoryImpl struct {
 	DB *gorm.DB
 }
 
+// preload adds the relations named by includes, the models.MenuRelation*
+// names.
+// Unknown names are ignored, the service validates them.
+func preload(db *gorm.DB, includes []string) *gorm.DB {
+	bySortOrder := func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order, id")
+	}
+	for _, include := range includes {
+		switch include {
+		case models.MenuRelationCategory:
+			db = db.Preload("Category")
+		case models.MenuRelationVariants:
+			db = db.Preload("Variants", bySortOrder)
+		case models.MenuRelationModifiers:
+			db = db.Preload("Modifiers", bySortOrder)
+		}
+	}
+	return db
+}
+
 // GetAllMenu implements MenuRepository.
-func (m *MenuRepositoryImpl) GetAllMenus() ([]models.Menu, error) {
+func (m *MenuRepositoryImpl) GetAllMenus(includes ...string) ([]models.Menu, error) {
 	var all_menus []models.Menu
-	result := m.DB.Where("deleted_at is NULL").Find(&all_menus)
+	result := preload(m.DB, includes).Where("deleted_at is NULL").Find(&all_menus)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -45,6 +75,17 @@ func (m *MenuRepositoryImpl) UpdateMenu(id uint, menu *models.Menu) error {
 	return nil
 }
 
+// UpdateMenuColumns implements MenuRepository.
+// Unlike UpdateMenu it writes the named columns even when their value is
+// zero, so they can be cleared.
+func (m *MenuRepositoryImpl) UpdateMenuColumns(id uint, menu *models.Menu, columns ...string) error {
+	result := m.DB.Model(&models.Menu{}).Where("id = ?", id).Where("deleted_at IS NULL").Select(columns).Updates(menu)
+	if result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
+
 // DeleteMenu implements MenuRepository.
 func (m *MenuRepositoryImpl) DeleteMenu(id uint) error {
 	result := m.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.Menu{})
@@ -67,7 +108,7 @@ func (m *MenuRepositoryImpl) FindByCategoryID(category_id uint) ([]models.Menu,
 // FindByName implements MenuRepository.
 func (m *MenuRepositoryImpl) FindByName(name string) (*models.Menu, error) {
 	var menu models.Menu
-	result := m.DB.Where("name = ?", name).Where("deleted_at is NULL").First(&menu)
+	result := m.DB.Where("menu_name = ?", name).Where("deleted_at is NULL").First(&menu)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -75,15 +116,120 @@ func (m *MenuRepositoryImpl) FindByName(name string) (*models.Menu, error) {
 }
 
 // GetMenuByID implements MenuRepository.
-func (m *MenuRepositoryImpl) GetMenuByID(id uint) (*models.Menu, error) {
+func (m *MenuRepositoryImpl) GetMenuByID(id uint, includes ...string) (*models.Menu, error) {
 	var menu models.Menu
-	result := m.DB.Where("id = ?", id).Where("deleted_at is NULL").First(&menu)
+	result := preload(m.DB, includes).Where("id = ?", id).Where("deleted_at is NULL").First(&menu)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return &menu, nil
 }
 
+// GetImageURLs implements MenuRepository.
+// It returns the distinct image values of every menu that is not deleted.
+func (m *MenuRepositoryImpl) GetImageURLs() ([]string, error) {
+	var images []string
+	result := m.DB.Model(&models.Menu{}).Where("deleted_at IS NULL").Where("image_url <> ''").Distinct().Pluck("image_url", &images)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return images, nil
+}
+
+// CountByCategory implements MenuRepository.
+// It returns the number of menus that are not deleted, per category ID.
+func (m *MenuRepositoryImpl) CountByCategory() (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	result := m.DB.Model(&models.Menu{}).Select("category_id, COUNT(*) AS count").Where("deleted_at IS NULL").Group("category_id").Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
+
+// SetSoldOut implements MenuRepository.
+// It updates the flag alone, Updates with a struct would skip false.
+func (m *MenuRepositoryImpl) SetSoldOut(id uint, soldOut bool) error {
+	result := m.DB.Model(&models.Menu{}).Where("id = ?", id).Where("deleted_at IS NULL").Update("sold_out", soldOut)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		var count int64
+		if err := m.DB.Model(&models.Menu{}).Where("id = ?", id).Where("deleted_at IS NULL").Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return gorm.ErrRecordNotFound
+		}
+	}
+	return nil
+}
+
+// GetVariant implements MenuRepository.
+func (m *MenuRepositoryImpl) GetVariant(menuID uint, id uint) (models.MenuVariant, error) {
+	var variant models.MenuVariant
+	result := m.DB.Where("id = ? AND menu_id = ?", id, menuID).Where("deleted_at IS NULL").First(&variant)
+	if result.Error != nil {
+		return models.MenuVariant{}, result.Error
+	}
+	return variant, nil
+}
+
+// SaveVariant implements MenuRepository.
+// A variant without an ID is created.
+func (m *MenuRepositoryImpl) SaveVariant(variant *models.MenuVariant) error {
+	return m.DB.Save(variant).Error
+}
+
+// DeleteVariant implements MenuRepository.
+// Orders keep the name and price of a deleted variant on their lines.
+func (m *MenuRepositoryImpl) DeleteVariant(menuID uint, id uint) error {
+	result := m.DB.Where("id = ? AND menu_id = ?", id, menuID).Where("deleted_at IS NULL").Delete(&models.MenuVariant{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetModifier implements MenuRepository.
+func (m *MenuRepositoryImpl) GetModifier(menuID uint, id uint) (models.MenuModifier, error) {
+	var modifier models.MenuModifier
+	result := m.DB.Where("id = ? AND menu_id = ?", id, menuID).Where("deleted_at IS NULL").First(&modifier)
+	if result.Error != nil {
+		return models.MenuModifier{}, result.Error
+	}
+	return modifier, nil
+}
+
+// SaveModifier implements MenuRepository.
+// A modifier without an ID is created.
+func (m *MenuRepositoryImpl) SaveModifier(modifier *models.MenuModifier) error {
+	return m.DB.Save(modifier).Error
+}
+
+// DeleteModifier implements MenuRepository.
+func (m *MenuRepositoryImpl) DeleteModifier(menuID uint, id uint) error {
+	result := m.DB.Where("id = ? AND menu_id = ?", id, menuID).Where("deleted_at IS NULL").Delete(&models.MenuModifier{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 func NewMenuRepository(db *gorm.DB) MenuRepository {
 	return &MenuRepositoryImpl{
 		DB: db,
@@ -0,0 +1,436 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrOrderNotPending is returned when an order changes status but is no
+// longer pending.
+var ErrOrderNotPending = errors.New("order is not pending")
+
+// ErrOrderNotOpen is returned when an order is completed or canceled but
+// already was.
+var ErrOrderNotOpen = errors.New("order is no longer open")
+
+// ErrOrderNotCompleted is returned when a refund is made on an order that
+// was not handed over.
+var ErrOrderNotCompleted = errors.New("order is not completed")
+
+// ErrRefundConflict is returned when another credit note was issued on an
+// order while a refund was being priced.
+var ErrRefundConflict = errors.New("order was refunded by another request, try again")
+
+// OrderRefund is a credit note with what it changes besides the order.
+type OrderRefund struct {
+	Note *models.CreditNote
+	// Refunded is the quantity already refunded per order line that Note
+	// was priced against
+	Refunded map[uint]int
+	// Full marks the note that refunds the rest of the order: the voucher
+	// of the order is released and Restored, when set, gives back the
+	// points spent on its reward. Its points are filled in here.
+	Full     bool
+	Restored *models.LoyaltyEntry
+	// Reverse, when set, is given the loyalty entries of the locked order
+	// and returns the entry that takes back points earned on it, or nil.
+	// Points the customer no longer has are not taken back.
+	Reverse func(entries []models.LoyaltyEntry) *models.LoyaltyEntry
+}
+
+type OrderRepository interface {
+	// Define order-related data access methods here
+	GetOrdersBetween(start time.Time, end time.Time) ([]models.Order, error)
+	CreateOrder(order *models.Order) error
+	FindByNote(note string) (*models.Order, error)
+	CountOrdersByUser(userID uint) (int64, error)
+	GetOrderByID(id uint) (models.Order, error)
+	CompleteOrder(order *models.Order, earned *models.LoyaltyEntry) error
+	PrepareOrder(id uint) error
+	CancelOrder(order *models.Order, statuses []string, refund OrderRefund) error
+	RefundOrder(order *models.Order, refund OrderRefund) error
+	GetCreditNotes(orderID uint) ([]models.CreditNote, error)
+	GetCreditNotesBetween(start time.Time, end time.Time) ([]models.CreditNote, error)
+	UpdateRefundStatus(note *models.CreditNote) error
+}
+
+type orderRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetOrdersBetween implements OrderRepository.
+// Menus are loaded unscoped so items of a since-deleted menu keep their name.
+func (o *orderRepositoryImpl) GetOrdersBetween(start time.Time, end time.Time) ([]models.Order, error) {
+	var orders []models.Order
+	result := o.DB.
+		Preload("Items", "deleted_at IS NULL").
+		Preload("Items.Menu", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("deleted_at IS NULL").
+		Find(&orders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return orders, nil
+}
+
+// CreateOrder implements OrderRepository.
+// The order, its items and its payments are written in one transaction.
+// An order with a voucher redeems it in the same transaction, or fails
+// with ErrVoucherLimitReached when the voucher ran out, expired or was
+// deactivated in the meantime. An
+// order with a loyalty reward spends its points the same way, or fails
+// with ErrInsufficientPoints. An order taken in a shift fails with
+// ErrShiftNotOpen when the shift was closed in the meantime.
+func (o *orderRepositoryImpl) CreateOrder(order *models.Order) error {
+	return o.DB.Transaction(func(tx *gorm.DB) error {
+		if order.ShiftID != nil {
+			if err := checkShiftOpen(tx, *order.ShiftID); err != nil {
+				return err
+			}
+		}
+		var voucher *models.Voucher
+		if order.VoucherID != nil {
+			var err error
+			if voucher, err = lockVoucher(tx, order, time.Now()); err != nil {
+				return err
+			}
+		}
+		var reward models.LoyaltyReward
+		if order.LoyaltyRewardID != nil {
+			if err := lockUser(tx, order.UserID); err != nil {
+				return err
+			}
+			if err := tx.Where("id = ?", *order.LoyaltyRewardID).Where("deleted_at IS NULL").First(&reward).Error; err != nil {
+				return err
+			}
+			if err := spendPoints(tx, order.UserID, reward.Points, time.Now()); err != nil {
+				return err
+			}
+		}
+		omit := []string{"User", "Items.Menu"}
+		if order.UserID == 0 {
+			// anonymous walk-in, leave user_id NULL
+			omit = append(omit, "UserID")
+		}
+		if err := tx.Omit(omit...).Create(order).Error; err != nil {
+			return err
+		}
+		if voucher != nil {
+			if err := redeemVoucher(tx, voucher, order); err != nil {
+				return err
+			}
+		}
+		if order.LoyaltyRewardID != nil {
+			redemption := models.LoyaltyEntry{
+				UserID:   order.UserID,
+				Type:     models.LoyaltyRedeem,
+				Points:   -reward.Points,
+				OrderID:  &order.ID,
+				RewardID: &reward.ID,
+				Note:     reward.Name,
+			}
+			return tx.Create(&redemption).Error
+		}
+		return nil
+	})
+}
+
+// GetOrderByID implements OrderRepository.
+func (o *orderRepositoryImpl) GetOrderByID(id uint) (models.Order, error) {
+	var order models.Order
+	result := o.DB.
+		Preload("Items", "deleted_at IS NULL").
+		Preload("Items.Menu", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Preload("Items.Modifiers", "deleted_at IS NULL").
+		Preload("Taxes", "deleted_at IS NULL").
+		Preload("Payments", "deleted_at IS NULL").
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		First(&order)
+	if result.Error != nil {
+		return models.Order{}, result.Error
+	}
+	return order, nil
+}
+
+// CompleteOrder implements OrderRepository.
+// The order moves from pending or preparing to completed and earns its
+// points in one transaction; an order that was completed or canceled in
+// the meantime fails with ErrOrderNotOpen, so points are never earned
+// twice. earned may be nil.
+func (o *orderRepositoryImpl) CompleteOrder(order *models.Order, earned *models.LoyaltyEntry) error {
+	return o.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Order{}).
+			Where("id = ? AND status IN ?", order.ID, []string{models.OrderStatusPending, models.OrderStatusPreparing}).
+			Where("deleted_at IS NULL").
+			Update("status", models.OrderStatusCompleted)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrOrderNotOpen
+		}
+		order.Status = models.OrderStatusCompleted
+		if earned != nil {
+			earned.Remaining = earned.Points
+			return tx.Create(earned).Error
+		}
+		return nil
+	})
+}
+
+// PrepareOrder implements OrderRepository.
+// A pending order moves to preparing, or the call fails with
+// ErrOrderNotPending.
+func (o *orderRepositoryImpl) PrepareOrder(id uint) error {
+	result := o.DB.Model(&models.Order{}).
+		Where("id = ? AND status = ?", id, models.OrderStatusPending).
+		Where("deleted_at IS NULL").
+		Update("status", models.OrderStatusPreparing)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrOrderNotPending
+	}
+	return nil
+}
+
+// CancelOrder implements OrderRepository.
+// The order is canceled and its credit note issued in one transaction, if
+// its status is still one of statuses; otherwise it fails with
+// ErrOrderNotOpen.
+func (o *orderRepositoryImpl) CancelOrder(order *models.Order, statuses []string, refund OrderRefund) error {
+	return o.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockOrder(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if !slices.Contains(statuses, locked.Status) {
+			return fmt.Errorf("%w: order is %s", ErrOrderNotOpen, locked.Status)
+		}
+
+		now := time.Now()
+		err = tx.Model(&models.Order{}).Where("id = ?", order.ID).Updates(map[string]interface{}{
+			"status":      models.OrderStatusCanceled,
+			"canceled_at": now,
+		}).Error
+		if err != nil {
+			return err
+		}
+		order.Status = models.OrderStatusCanceled
+		order.CanceledAt = &now
+		return issueCreditNote(tx, order, refund, now)
+	})
+}
+
+// RefundOrder implements OrderRepository.
+// The credit note is issued only on a completed order, or it fails with
+// ErrOrderNotCompleted. The order row is locked while the quantities
+// refunded so far are checked against refund.Refunded, so two refunds can
+// never give back the same item twice, and while the points to take back
+// are worked out from the loyalty entries of the order.
+func (o *orderRepositoryImpl) RefundOrder(order *models.Order, refund OrderRefund) error {
+	return o.DB.Transaction(func(tx *gorm.DB) error {
+		locked, err := lockOrder(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if locked.Status != models.OrderStatusCompleted {
+			return fmt.Errorf("%w: order is %s", ErrOrderNotCompleted, locked.Status)
+		}
+		return issueCreditNote(tx, order, refund, time.Now())
+	})
+}
+
+// GetCreditNotes implements OrderRepository.
+func (o *orderRepositoryImpl) GetCreditNotes(orderID uint) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	result := o.DB.
+		Preload("Items", "deleted_at IS NULL").
+		Where("order_id = ?", orderID).
+		Where("deleted_at IS NULL").
+		Order("id").
+		Find(&notes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return notes, nil
+}
+
+// GetCreditNotesBetween implements OrderRepository.
+// Menus are loaded unscoped so items of a since-deleted menu keep their name.
+func (o *orderRepositoryImpl) GetCreditNotesBetween(start time.Time, end time.Time) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	result := o.DB.
+		Preload("Items", "deleted_at IS NULL").
+		Preload("Items.Menu", func(db *gorm.DB) *gorm.DB { return db.Unscoped() }).
+		Where("created_at >= ? AND created_at < ?", start, end).
+		Where("deleted_at IS NULL").
+		Find(&notes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return notes, nil
+}
+
+// UpdateRefundStatus implements OrderRepository.
+func (o *orderRepositoryImpl) UpdateRefundStatus(note *models.CreditNote) error {
+	return o.DB.Model(&models.CreditNote{}).Where("id = ?", note.ID).Updates(map[string]interface{}{
+		"refund_status":    note.RefundStatus,
+		"refund_reference": note.RefundReference,
+	}).Error
+}
+
+// lockOrder locks an order row for the rest of the transaction.
+func lockOrder(tx *gorm.DB, id uint) (models.Order, error) {
+	var order models.Order
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Where("deleted_at IS NULL").First(&order).Error
+	return order, err
+}
+
+// issueCreditNote writes the credit note of a locked order with the
+// loyalty and voucher changes that go with it.
+func issueCreditNote(tx *gorm.DB, order *models.Order, refund OrderRefund, now time.Time) error {
+	refunded, err := refundedQuantities(tx, order.ID)
+	if err != nil {
+		return err
+	}
+	if !maps.Equal(refunded, refund.Refunded) {
+		return ErrRefundConflict
+	}
+	if refund.Note.ShiftID != nil {
+		if err := checkShiftOpen(tx, *refund.Note.ShiftID); err != nil {
+			return err
+		}
+	}
+	if err := tx.Omit("Items.Menu").Create(refund.Note).Error; err != nil {
+		return err
+	}
+
+	if refund.Reverse != nil {
+		entries, err := orderEntries(tx, order.ID)
+		if err != nil {
+			return err
+		}
+		if err := reversePoints(tx, order.UserID, refund.Reverse(entries), now); err != nil {
+			return err
+		}
+	}
+	if !refund.Full {
+		return nil
+	}
+
+	if order.VoucherID != nil {
+		result := tx.Where("order_id = ?", order.ID).Where("deleted_at IS NULL").Delete(&models.VoucherRedemption{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			err := tx.Model(&models.Voucher{}).
+				Where("id = ? AND redemption_count > 0", *order.VoucherID).
+				Update("redemption_count", gorm.Expr("redemption_count - ?", result.RowsAffected)).Error
+			if err != nil {
+				return err
+			}
+		}
+	}
+	if refund.Restored != nil {
+		var redemption models.LoyaltyEntry
+		err := tx.Where("order_id = ? AND type = ?", order.ID, models.LoyaltyRedeem).Where("deleted_at IS NULL").First(&redemption).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		refund.Restored.Points = -redemption.Points
+		refund.Restored.Remaining = -redemption.Points
+		refund.Restored.RewardID = redemption.RewardID
+		return tx.Create(refund.Restored).Error
+	}
+	return nil
+}
+
+// reversePoints takes back the points of reversed, at most what the user
+// has left, and records what was taken back. A nil entry is a no-op.
+func reversePoints(tx *gorm.DB, userID uint, reversed *models.LoyaltyEntry, now time.Time) error {
+	if reversed == nil {
+		return nil
+	}
+	if err := lockUser(tx, userID); err != nil {
+		return err
+	}
+	available, err := availablePoints(tx, userID, now)
+	if err != nil {
+		return err
+	}
+	points := min(-reversed.Points, max(available, 0))
+	if points <= 0 {
+		return nil
+	}
+	if err := spendPoints(tx, userID, points, now); err != nil {
+		return err
+	}
+	reversed.Points = -points
+	return tx.Create(reversed).Error
+}
+
+// refundedQuantities sums the quantity credited so far per order line.
+func refundedQuantities(tx *gorm.DB, orderID uint) (map[uint]int, error) {
+	var rows []struct {
+		OrderMenuItemID uint
+		Quantity        int
+	}
+	err := tx.Table("credit_note_items").
+		Select("credit_note_items.order_menu_item_id, SUM(credit_note_items.quantity) AS quantity").
+		Joins("JOIN credit_notes ON credit_notes.id = credit_note_items.credit_note_id").
+		Where("credit_notes.order_id = ?", orderID).
+		Where("credit_notes.deleted_at IS NULL AND credit_note_items.deleted_at IS NULL").
+		Group("credit_note_items.order_menu_item_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	refunded := make(map[uint]int, len(rows))
+	for _, row := range rows {
+		refunded[row.OrderMenuItemID] = row.Quantity
+	}
+	return refunded, nil
+}
+
+// CountOrdersByUser implements OrderRepository.
+// Canceled orders are not counted.
+func (o *orderRepositoryImpl) CountOrdersByUser(userID uint) (int64, error) {
+	return countOrdersByUser(o.DB, userID)
+}
+
+func countOrdersByUser(db *gorm.DB, userID uint) (int64, error) {
+	var count int64
+	result := db.Model(&models.Order{}).Where("user_id = ?", userID).Where("status <> ?", models.OrderStatusCanceled).Where("deleted_at IS NULL").Count(&count)
+	return count, result.Error
+}
+
+// FindByNote implements OrderRepository.
+func (o *orderRepositoryImpl) FindByNote(note string) (*models.Order, error) {
+	var order models.Order
+	result := o.DB.Where("note = ?", note).Where("deleted_at IS NULL").First(&order)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &order, nil
+}
+
+func NewOrderRepository(db *gorm.DB) OrderRepository {
+	return &orderRepositoryImpl{
+		DB: db,
+	}
+}
@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+
+	"gorm.io/gorm"
+)
+
+type PromotionRepository interface {
+	GetAllPromotions() ([]models.Promotion, error)
+	GetActivePromotions() ([]models.Promotion, error)
+	GetPromotionByID(id uint) (models.Promotion, error)
+	CreatePromotion(promotion *models.Promotion) error
+	UpdatePromotion(promotion *models.Promotion) error
+	DeletePromotion(id uint) error
+}
+
+type promotionRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetAllPromotions implements PromotionRepository.
+func (p *promotionRepositoryImpl) GetAllPromotions() ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	result := p.DB.Where("deleted_at IS NULL").Order("id").Find(&promotions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return promotions, nil
+}
+
+// GetActivePromotions implements PromotionRepository.
+// Schedules and minimum spend are checked by the caller.
+func (p *promotionRepositoryImpl) GetActivePromotions() ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	result := p.DB.Where("active = ?", true).Where("deleted_at IS NULL").Order("id").Find(&promotions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return promotions, nil
+}
+
+// GetPromotionByID implements PromotionRepository.
+func (p *promotionRepositoryImpl) GetPromotionByID(id uint) (models.Promotion, error) {
+	var promotion models.Promotion
+	result := p.DB.Where("id = ?", id).Where("deleted_at IS NULL").First(&promotion)
+	if result.Error != nil {
+		return models.Promotion{}, result.Error
+	}
+	return promotion, nil
+}
+
+// CreatePromotion implements PromotionRepository.
+func (p *promotionRepositoryImpl) CreatePromotion(promotion *models.Promotion) error {
+	return p.DB.Create(promotion).Error
+}
+
+// UpdatePromotion implements PromotionRepository.
+func (p *promotionRepositoryImpl) UpdatePromotion(promotion *models.Promotion) error {
+	return p.DB.Save(promotion).Error
+}
+
+// DeletePromotion implements PromotionRepository.
+func (p *promotionRepositoryImpl) DeletePromotion(id uint) error {
+	result := p.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.Promotion{})
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func NewPromotionRepository(db *gorm.DB) PromotionRepository {
+	return &promotionRepositoryImpl{
+		DB: db,
+	}
+}
@@ -0,0 +1,276 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrShiftAlreadyOpen is returned when a cashier opens a second shift.
+var ErrShiftAlreadyOpen = errors.New("cashier already has an open shift")
+
+// ErrShiftNotOpen is returned when something is recorded on a shift that
+// was closed.
+var ErrShiftNotOpen = errors.New("shift is not open")
+
+// ShiftTotals sums up what went through the till during a shift.
+type ShiftTotals struct {
+	Orders int64
+	// Payments is what the orders of the shift were paid, per method
+	Payments map[string]float64
+	// Refunds is what the credit notes of the shift paid back, per method.
+	// Only orders paid at the till had money taken in, so only their
+	// notes take money out.
+	Refunds map[string]float64
+	// Movements is the cash moved in and out of the drawer, per type
+	Movements map[string]float64
+}
+
+type ShiftRepository interface {
+	OpenShift(shift *models.Shift) error
+	GetShiftByID(id uint) (models.Shift, error)
+	GetOpenShift(cashierID uint) (models.Shift, error)
+	GetShiftsBetween(start time.Time, end time.Time) ([]models.Shift, error)
+	AddMovement(movement *models.ShiftCashMovement, check func(shift *models.Shift, totals ShiftTotals) error) error
+	GetShiftTotals(shiftID uint) (ShiftTotals, error)
+	CloseShift(id uint, close func(shift *models.Shift, totals ShiftTotals) error) (models.Shift, error)
+}
+
+type shiftRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// OpenShift implements ShiftRepository.
+// The cashier is locked while their open shifts are counted, so two
+// requests cannot both open one; the second fails with
+// ErrShiftAlreadyOpen.
+func (s *shiftRepositoryImpl) OpenShift(shift *models.Shift) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := lockUser(tx, shift.CashierID); err != nil {
+			return err
+		}
+		var open int64
+		err := tx.Model(&models.Shift{}).
+			Where("cashier_id = ? AND status = ?", shift.CashierID, models.ShiftOpen).
+			Where("deleted_at IS NULL").
+			Count(&open).Error
+		if err != nil {
+			return err
+		}
+		if open > 0 {
+			return ErrShiftAlreadyOpen
+		}
+		return tx.Create(shift).Error
+	})
+}
+
+// GetShiftByID implements ShiftRepository.
+func (s *shiftRepositoryImpl) GetShiftByID(id uint) (models.Shift, error) {
+	var shift models.Shift
+	result := s.DB.
+		Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Where("deleted_at IS NULL").Order("id") }).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		First(&shift)
+	if result.Error != nil {
+		return models.Shift{}, result.Error
+	}
+	return shift, nil
+}
+
+// GetOpenShift implements ShiftRepository.
+func (s *shiftRepositoryImpl) GetOpenShift(cashierID uint) (models.Shift, error) {
+	var shift models.Shift
+	result := s.DB.
+		Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Where("deleted_at IS NULL").Order("id") }).
+		Where("cashier_id = ? AND status = ?", cashierID, models.ShiftOpen).
+		Where("deleted_at IS NULL").
+		First(&shift)
+	if result.Error != nil {
+		return models.Shift{}, result.Error
+	}
+	return shift, nil
+}
+
+// GetShiftsBetween implements ShiftRepository.
+// Shifts are matched on when they were opened.
+func (s *shiftRepositoryImpl) GetShiftsBetween(start time.Time, end time.Time) ([]models.Shift, error) {
+	var shifts []models.Shift
+	result := s.DB.
+		Preload("Movements", func(db *gorm.DB) *gorm.DB { return db.Where("deleted_at IS NULL").Order("id") }).
+		Where("opened_at >= ? AND opened_at < ?", start, end).
+		Where("deleted_at IS NULL").
+		Order("opened_at").
+		Find(&shifts)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return shifts, nil
+}
+
+// AddMovement implements ShiftRepository.
+// The shift row is locked while check, when set, looks at the totals so
+// far, so the orders, refunds and movements recorded on the shift cannot
+// change before the movement is added. It fails with ErrShiftNotOpen once
+// the shift was closed.
+func (s *shiftRepositoryImpl) AddMovement(movement *models.ShiftCashMovement, check func(shift *models.Shift, totals ShiftTotals) error) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		shift, err := lockShift(tx, movement.ShiftID)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			totals, err := shiftTotals(tx, shift.ID)
+			if err != nil {
+				return err
+			}
+			if err := check(&shift, totals); err != nil {
+				return err
+			}
+		}
+		return tx.Create(movement).Error
+	})
+}
+
+// GetShiftTotals implements ShiftRepository.
+func (s *shiftRepositoryImpl) GetShiftTotals(shiftID uint) (ShiftTotals, error) {
+	return shiftTotals(s.DB, shiftID)
+}
+
+// CloseShift implements ShiftRepository.
+// The shift row is locked while close fills in the closing count from
+// the totals, so no order or refund can be added to the shift in between;
+// those check the shift is still open under the same lock. A shift that
+// is already closed fails with ErrShiftNotOpen.
+func (s *shiftRepositoryImpl) CloseShift(id uint, close func(shift *models.Shift, totals ShiftTotals) error) (models.Shift, error) {
+	var shift models.Shift
+	err := s.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if shift, err = lockShift(tx, id); err != nil {
+			return err
+		}
+
+		totals, err := shiftTotals(tx, id)
+		if err != nil {
+			return err
+		}
+		if err := close(&shift, totals); err != nil {
+			return err
+		}
+		shift.Status = models.ShiftClosed
+		return tx.Model(&models.Shift{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"status":        shift.Status,
+			"closed_at":     shift.ClosedAt,
+			"expected_cash": shift.ExpectedCash,
+			"counted_cash":  shift.CountedCash,
+			"discrepancy":   shift.Discrepancy,
+			"closing_note":  shift.ClosingNote,
+		}).Error
+	})
+	if err != nil {
+		return models.Shift{}, err
+	}
+	return s.GetShiftByID(id)
+}
+
+// lockShift locks an open shift for the rest of the transaction, or fails
+// with ErrShiftNotOpen when it was closed.
+func lockShift(tx *gorm.DB, id uint) (models.Shift, error) {
+	var shift models.Shift
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).Where("deleted_at IS NULL").First(&shift).Error
+	if err != nil {
+		return models.Shift{}, err
+	}
+	if shift.Status != models.ShiftOpen {
+		return models.Shift{}, ErrShiftNotOpen
+	}
+	return shift, nil
+}
+
+// checkShiftOpen takes a shared lock on a shift and fails with
+// ErrShiftNotOpen when it is closed. Recording anything on a shift goes
+// through it, so it waits for a shift being closed.
+func checkShiftOpen(tx *gorm.DB, shiftID uint) error {
+	var shift models.Shift
+	err := tx.Clauses(clause.Locking{Strength: "SHARE"}).Where("id = ?", shiftID).Where("deleted_at IS NULL").First(&shift).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrShiftNotOpen
+	}
+	if err != nil {
+		return err
+	}
+	if shift.Status != models.ShiftOpen {
+		return ErrShiftNotOpen
+	}
+	return nil
+}
+
+func shiftTotals(db *gorm.DB, shiftID uint) (ShiftTotals, error) {
+	totals := ShiftTotals{
+		Payments:  map[string]float64{},
+		Refunds:   map[string]float64{},
+		Movements: map[string]float64{},
+	}
+	var sums []struct {
+		Key    string
+		Amount float64
+	}
+
+	err := db.Model(&models.Order{}).Where("shift_id = ?", shiftID).Where("deleted_at IS NULL").Count(&totals.Orders).Error
+	if err != nil {
+		return ShiftTotals{}, err
+	}
+
+	err = db.Table("order_payments").
+		Select("order_payments.method AS `key`, SUM(order_payments.amount) AS amount").
+		Joins("JOIN orders ON orders.id = order_payments.order_id").
+		Where("orders.shift_id = ?", shiftID).
+		Where("orders.deleted_at IS NULL AND order_payments.deleted_at IS NULL").
+		Group("order_payments.method").
+		Scan(&sums).Error
+	if err != nil {
+		return ShiftTotals{}, err
+	}
+	for _, sum := range sums {
+		totals.Payments[sum.Key] = sum.Amount
+	}
+
+	sums = nil
+	err = db.Table("credit_notes").
+		Select("refund_method AS `key`, SUM(amount) AS amount").
+		Where("shift_id = ? AND refund_status = ? AND amount > 0", shiftID, models.RefundRefunded).
+		Where("EXISTS (SELECT 1 FROM order_payments WHERE order_payments.order_id = credit_notes.order_id AND order_payments.deleted_at IS NULL)").
+		Where("deleted_at IS NULL").
+		Group("refund_method").
+		Scan(&sums).Error
+	if err != nil {
+		return ShiftTotals{}, err
+	}
+	for _, sum := range sums {
+		totals.Refunds[sum.Key] = sum.Amount
+	}
+
+	sums = nil
+	err = db.Table("shift_cash_movements").
+		Select("type AS `key`, SUM(amount) AS amount").
+		Where("shift_id = ?", shiftID).
+		Where("deleted_at IS NULL").
+		Group("type").
+		Scan(&sums).Error
+	if err != nil {
+		return ShiftTotals{}, err
+	}
+	for _, sum := range sums {
+		totals.Movements[sum.Key] = sum.Amount
+	}
+	return totals, nil
+}
+
+func NewShiftRepository(db *gorm.DB) ShiftRepository {
+	return &shiftRepositoryImpl{
+		DB: db,
+	}
+}
@@ -0,0 +1,139 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+
+	"gorm.io/gorm"
+)
+
+type TaxRepository interface {
+	GetAllTaxRates() ([]models.TaxRate, error)
+	GetTaxRateByID(id uint) (models.TaxRate, error)
+	CreateTaxRate(rate *models.TaxRate) error
+	UpdateTaxRate(rate *models.TaxRate) error
+	DeleteTaxRate(id uint) error
+	GetAllServiceCharges() ([]models.ServiceChargeRule, error)
+	GetActiveServiceCharges(orderType string) ([]models.ServiceChargeRule, error)
+	GetServiceChargeByID(id uint) (models.ServiceChargeRule, error)
+	CreateServiceCharge(rule *models.ServiceChargeRule) error
+	UpdateServiceCharge(rule *models.ServiceChargeRule) error
+	DeleteServiceCharge(id uint) error
+}
+
+type taxRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetAllTaxRates implements TaxRepository.
+func (t *taxRepositoryImpl) GetAllTaxRates() ([]models.TaxRate, error) {
+	var rates []models.TaxRate
+	result := t.DB.Where("deleted_at IS NULL").Order("id").Find(&rates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return rates, nil
+}
+
+// GetTaxRateByID implements TaxRepository.
+func (t *taxRepositoryImpl) GetTaxRateByID(id uint) (models.TaxRate, error) {
+	var rate models.TaxRate
+	result := t.DB.Where("id = ?", id).Where("deleted_at IS NULL").First(&rate)
+	if result.Error != nil {
+		return models.TaxRate{}, result.Error
+	}
+	return rate, nil
+}
+
+// CreateTaxRate implements TaxRepository.
+// A new default rate replaces the previous one.
+func (t *taxRepositoryImpl) CreateTaxRate(rate *models.TaxRate) error {
+	return t.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(rate).Error; err != nil {
+			return err
+		}
+		return clearOtherDefaults(tx, rate)
+	})
+}
+
+// UpdateTaxRate implements TaxRepository.
+// A rate made the default replaces the previous one.
+func (t *taxRepositoryImpl) UpdateTaxRate(rate *models.TaxRate) error {
+	return t.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rate).Error; err != nil {
+			return err
+		}
+		return clearOtherDefaults(tx, rate)
+	})
+}
+
+// clearOtherDefaults keeps a single default tax rate.
+func clearOtherDefaults(tx *gorm.DB, rate *models.TaxRate) error {
+	if !rate.IsDefault {
+		return nil
+	}
+	return tx.Model(&models.TaxRate{}).Where("id <> ? AND is_default = ?", rate.ID, true).Update("is_default", false).Error
+}
+
+// DeleteTaxRate implements TaxRepository.
+func (t *taxRepositoryImpl) DeleteTaxRate(id uint) error {
+	result := t.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.TaxRate{})
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// GetAllServiceCharges implements TaxRepository.
+func (t *taxRepositoryImpl) GetAllServiceCharges() ([]models.ServiceChargeRule, error) {
+	var rules []models.ServiceChargeRule
+	result := t.DB.Where("deleted_at IS NULL").Order("id").Find(&rules)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return rules, nil
+}
+
+// GetActiveServiceCharges implements TaxRepository.
+func (t *taxRepositoryImpl) GetActiveServiceCharges(orderType string) ([]models.ServiceChargeRule, error) {
+	var rules []models.ServiceChargeRule
+	result := t.DB.Where("order_type = ? AND active = ?", orderType, true).Where("deleted_at IS NULL").Order("id").Find(&rules)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return rules, nil
+}
+
+// GetServiceChargeByID implements TaxRepository.
+func (t *taxRepositoryImpl) GetServiceChargeByID(id uint) (models.ServiceChargeRule, error) {
+	var rule models.ServiceChargeRule
+	result := t.DB.Where("id = ?", id).Where("deleted_at IS NULL").First(&rule)
+	if result.Error != nil {
+		return models.ServiceChargeRule{}, result.Error
+	}
+	return rule, nil
+}
+
+// CreateServiceCharge implements TaxRepository.
+func (t *taxRepositoryImpl) CreateServiceCharge(rule *models.ServiceChargeRule) error {
+	return t.DB.Create(rule).Error
+}
+
+// UpdateServiceCharge implements TaxRepository.
+func (t *taxRepositoryImpl) UpdateServiceCharge(rule *models.ServiceChargeRule) error {
+	return t.DB.Save(rule).Error
+}
+
+// DeleteServiceCharge implements TaxRepository.
+func (t *taxRepositoryImpl) DeleteServiceCharge(id uint) error {
+	result := t.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.ServiceChargeRule{})
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func NewTaxRepository(db *gorm.DB) TaxRepository {
+	return &taxRepositoryImpl{
+		DB: db,
+	}
+}
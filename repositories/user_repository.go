@@ -14,6 +14,7 @@ type UserRepository interface {
 	GetUserByID(user_id uint) (*models.User, error)
 	UpdateUser(user_id uint, user *models.User) error
 	DeleteUser(user_id uint) (int, error)
+	FindByPhone(phone string) ([]models.User, error)
 }
 
 type userRepository struct {
@@ -60,6 +61,23 @@ func (u *userRepository) GetUserByID(user_id uint) (*models.User, error) {
 	return &user, nil
 }
 
+// FindByPhone implements UserRepository.
+// phone is compared with spaces and dashes removed on both sides, so
+// numbers match however they were typed in. Several users can share a
+// number.
+func (u *userRepository) FindByPhone(phone string) ([]models.User, error) {
+	var users []models.User
+	result := u.DB.
+		Where("REPLACE(REPLACE(phone_number, ' ', ''), '-', '') = ?", phone).
+		Where("deleted_at IS NULL").
+		Order("id").
+		Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
 // RegisterUser implements UserRepository.
 func (u *userRepository) RegisterUser(user *models.User) error {
 	return u.DB.Create(&user).Error
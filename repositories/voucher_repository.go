@@ -0,0 +1,179 @@
+package repositories
+
+import (
+	"coffee_shop/models"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrVoucherLimitReached is returned by OrderRepository.CreateOrder when
+// the voucher of the order can no longer be redeemed.
+var ErrVoucherLimitReached = errors.New("voucher limit reached")
+
+// ErrDuplicateVoucherCode is returned when a voucher is saved with a code
+// another voucher, deleted or not, already has.
+var ErrDuplicateVoucherCode = errors.New("voucher code already exists")
+
+type VoucherRepository interface {
+	GetAllVouchers() ([]models.Voucher, error)
+	GetVoucherByID(id uint) (models.Voucher, error)
+	FindByCode(code string) (*models.Voucher, error)
+	CreateVoucher(voucher *models.Voucher) error
+	UpdateVoucher(voucher *models.Voucher) error
+	DeleteVoucher(id uint) error
+	CountRedemptions(voucherID uint, userID uint) (int64, error)
+}
+
+type voucherRepositoryImpl struct {
+	DB *gorm.DB
+}
+
+// GetAllVouchers implements VoucherRepository.
+func (v *voucherRepositoryImpl) GetAllVouchers() ([]models.Voucher, error) {
+	var vouchers []models.Voucher
+	result := v.DB.Preload("Categories").Where("deleted_at IS NULL").Order("id").Find(&vouchers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return vouchers, nil
+}
+
+// GetVoucherByID implements VoucherRepository.
+func (v *voucherRepositoryImpl) GetVoucherByID(id uint) (models.Voucher, error) {
+	var voucher models.Voucher
+	result := v.DB.Preload("Categories").Where("id = ?", id).Where("deleted_at IS NULL").First(&voucher)
+	if result.Error != nil {
+		return models.Voucher{}, result.Error
+	}
+	return voucher, nil
+}
+
+// FindByCode implements VoucherRepository.
+func (v *voucherRepositoryImpl) FindByCode(code string) (*models.Voucher, error) {
+	var voucher models.Voucher
+	result := v.DB.Preload("Categories").Where("code = ?", code).Where("deleted_at IS NULL").First(&voucher)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &voucher, nil
+}
+
+// CreateVoucher implements VoucherRepository.
+// Only the links to the categories are written, never the categories.
+func (v *voucherRepositoryImpl) CreateVoucher(voucher *models.Voucher) error {
+	return duplicateVoucherCode(v.DB.Omit("Categories.*").Create(voucher).Error)
+}
+
+// UpdateVoucher implements VoucherRepository.
+// The voucher and its category links are replaced in one transaction. The
+// redemption count is left alone, orders update it concurrently.
+func (v *voucherRepositoryImpl) UpdateVoucher(voucher *models.Voucher) error {
+	return v.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories", "RedemptionCount").Save(voucher).Error; err != nil {
+			return duplicateVoucherCode(err)
+		}
+		return tx.Model(voucher).Omit("Categories.*").Association("Categories").Replace(voucher.Categories)
+	})
+}
+
+// DeleteVoucher implements VoucherRepository.
+func (v *voucherRepositoryImpl) DeleteVoucher(id uint) error {
+	result := v.DB.Where("id = ?", id).Where("deleted_at IS NULL").Delete(&models.Voucher{})
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// CountRedemptions implements VoucherRepository.
+func (v *voucherRepositoryImpl) CountRedemptions(voucherID uint, userID uint) (int64, error) {
+	var count int64
+	result := v.DB.Model(&models.VoucherRedemption{}).Where("voucher_id = ? AND user_id = ?", voucherID, userID).Where("deleted_at IS NULL").Count(&count)
+	return count, result.Error
+}
+
+// duplicateVoucherCode turns a duplicate key error from the unique index
+// on the code into ErrDuplicateVoucherCode, two admins saving the same
+// code at once both pass the check in the service.
+func duplicateVoucherCode(err error) error {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+		return fmt.Errorf("%w: %s", ErrDuplicateVoucherCode, mysqlErr.Message)
+	}
+	return err
+}
+
+// lockVoucher locks the voucher of an order being created and checks it
+// again as of now: an admin may have deactivated it or changed its dates,
+// and it may have run out of redemptions. Concurrent orders with the same voucher wait for the lock,
+// so the counts they check cannot go stale before the order commits.
+//
+// It must run before the order is inserted: the insert takes a shared
+// lock on the voucher row for the foreign key, and two transactions that
+// both upgrade it would deadlock.
+func lockVoucher(tx *gorm.DB, order *models.Order, now time.Time) (*models.Voucher, error) {
+	var voucher models.Voucher
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", *order.VoucherID).Where("deleted_at IS NULL").First(&voucher).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: voucher no longer exists", ErrVoucherLimitReached)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !voucher.Active:
+		return nil, fmt.Errorf("%w: voucher is not active", ErrVoucherLimitReached)
+	case voucher.ValidFrom != nil && now.Before(*voucher.ValidFrom):
+		return nil, fmt.Errorf("%w: voucher is not valid yet", ErrVoucherLimitReached)
+	case voucher.ValidUntil != nil && now.After(*voucher.ValidUntil):
+		return nil, fmt.Errorf("%w: voucher has expired", ErrVoucherLimitReached)
+	case voucher.MaxRedemptions > 0 && voucher.RedemptionCount >= voucher.MaxRedemptions:
+		return nil, fmt.Errorf("%w: voucher has been fully redeemed", ErrVoucherLimitReached)
+	}
+	if voucher.MaxPerUser > 0 {
+		var used int64
+		err := tx.Model(&models.VoucherRedemption{}).Where("voucher_id = ? AND user_id = ?", voucher.ID, order.UserID).Where("deleted_at IS NULL").Count(&used).Error
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(voucher.MaxPerUser) {
+			return nil, fmt.Errorf("%w: voucher was already used the maximum number of times", ErrVoucherLimitReached)
+		}
+	}
+	if voucher.FirstOrderOnly {
+		previous, err := countOrdersByUser(tx, order.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if previous > 0 {
+			return nil, fmt.Errorf("%w: voucher is only valid on a first order", ErrVoucherLimitReached)
+		}
+	}
+	return &voucher, nil
+}
+
+// redeemVoucher records the redemption of a locked voucher by a saved order.
+func redeemVoucher(tx *gorm.DB, voucher *models.Voucher, order *models.Order) error {
+	redemption := models.VoucherRedemption{
+		VoucherID: voucher.ID,
+		UserID:    order.UserID,
+		OrderID:   order.ID,
+		Discount:  order.VoucherDiscount,
+	}
+	if err := tx.Create(&redemption).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Voucher{}).Where("id = ?", voucher.ID).Update("redemption_count", gorm.Expr("redemption_count + 1")).Error
+}
+
+func NewVoucherRepository(db *gorm.DB) VoucherRepository {
+	return &voucherRepositoryImpl{
+		DB: db,
+	}
+}
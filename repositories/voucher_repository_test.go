@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestDuplicateVoucherCode(t *testing.T) {
+	duplicate := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'COFFEE10' for key 'idx_vouchers_code'"}
+	other := &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil},
+		{name: "duplicate key", err: duplicate, want: true},
+		{name: "wrapped duplicate key", err: fmt.Errorf("insert: %w", duplicate), want: true},
+		{name: "other mysql error", err: other},
+		{name: "other error", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := duplicateVoucherCode(tt.err)
+			if got := errors.Is(err, ErrDuplicateVoucherCode); got != tt.want {
+				t.Errorf("errors.Is(%v, ErrDuplicateVoucherCode) = %v, want %v", err, got, tt.want)
+			}
+			if !tt.want && err != tt.err {
+				t.Errorf("duplicateVoucherCode(%v) = %v, want it unchanged", tt.err, err)
+			}
+		})
+	}
+}
@@ -3,77 +3,169 @@ package routes
 import (
 	"coffee_shop/config"
 	"coffee_shop/controllers"
-	"coffee_shop/dto"
+	"coffee_shop/lifecycle"
 	"coffee_shop/middlewares"
-	"context"
-	"log"
-	"net/http"
+	"coffee_shop/storage"
+	"net/url"
+	"strings"
 
 	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
-func redisPing(c echo.Context) error {
-	ctx := context.Background()
+func ApiRoutes(e *echo.Echo, cfg *config.Config, db *gorm.DB, rdb redis.UniversalClient, store storage.BlobStore, lc *lifecycle.Manager) {
+	// HEALTH ROUTES
+	HealthController := controllers.NewHealthController(db, rdb, store, lc)
+	e.GET("/livez", HealthController.Livez)
+	e.GET("/readyz", HealthController.Readyz)
 
-	redisClient, err := config.RedisClient()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Error Connecting to Redis | " + redisClient.Ping(ctx).Err().Error(),
-		})
-	}
-
-	pong, err := redisClient.Ping(ctx).Result()
-	if err != nil {
-		return c.JSON(http.StatusInternalServerError, dto.ApiResponse{
-			Status:  http.StatusInternalServerError,
-			Message: "Failed to ping Redis | " + redisClient.Ping(ctx).Err().Error(),
-		})
-	}
-
-	ApiResponse := dto.ApiResponse{
-		Status:  http.StatusOK,
-		Message: "Coffee Shop API is running || Redis = " + pong,
-	}
-
-	return c.JSON(http.StatusOK, ApiResponse)
-}
-
-func ApiRoutes(e *echo.Echo) {
-	db, err := config.InitDB()
-	if err != nil {
-		log.Fatal("Failed Connect to Database")
-	}
+	// IMAGE ROUTES
+	ImageController := controllers.NewImageController(store)
+	imagePath := imageRoutePath(cfg.Storage.PublicBaseURL)
+	e.GET(imagePath+"/*", ImageController.ServeImage)
+	e.HEAD(imagePath+"/*", ImageController.ServeImage)
 
 	g := e.Group("/api/v1")
 
-	g.GET("/health", redisPing)
+	g.GET("/health", HealthController.Readyz)
+
+	auth := middlewares.JWTMiddleware(cfg.JWT, rdb)
+	optionalAuth := middlewares.OptionalJWTMiddleware(cfg.JWT, rdb)
 
 	// USER ROUTES
-	UserController := controllers.NewUserController(db)
+	UserController := controllers.NewUserController(db, cfg, rdb)
 	g.POST("/register", UserController.Register)
 	g.POST("/login", UserController.Login)
-	g.POST("/logout", UserController.Logout, middlewares.JWTMiddleware)
-	g.PATCH("/user/update", UserController.UpdateUser, middlewares.JWTMiddleware)
-	g.DELETE("/user/delete", UserController.DeleteUser, middlewares.JWTMiddleware)
-	g.GET("/user/detail", UserController.GetUserByID, middlewares.JWTMiddleware)
+	g.POST("/logout", UserController.Logout, auth)
+	g.PATCH("/user/update", UserController.UpdateUser, auth)
+	g.DELETE("/user/delete", UserController.DeleteUser, auth)
+	g.GET("/user/detail", UserController.GetUserByID, auth)
+
+	// LOYALTY ROUTES
+	LoyaltyController := controllers.NewLoyaltyController(db, cfg, rdb)
+	g.GET("/user/loyalty", LoyaltyController.GetMyLoyalty, auth)
+	g.GET("/loyalty/users/:id", LoyaltyController.GetUserLoyalty, auth)
+	g.POST("/loyalty/adjustments", LoyaltyController.AdjustPoints, auth)
+	g.GET("/loyalty/rewards", LoyaltyController.GetAllRewards, auth)
+	g.POST("/loyalty/rewards", LoyaltyController.CreateReward, auth)
+	g.PUT("/loyalty/rewards/:id", LoyaltyController.UpdateReward, auth)
+	g.DELETE("/loyalty/rewards/:id", LoyaltyController.DeleteReward, auth)
 
 	// REFRESH TOKEN
 	g.POST("/refresh-token", UserController.RefreshToken)
 
 	// CATEGORY ROUTES
-	CategoryController := controllers.NewCategoryController(db)
+	CategoryController := controllers.NewCategoryController(db, cfg, rdb)
 	g.GET("/categories", CategoryController.GetAllCategories)
-	g.POST("/categories", CategoryController.CreateCategory, middlewares.JWTMiddleware)
-	g.GET("/categories/:id", CategoryController.GetCategoryByID, middlewares.JWTMiddleware)
-	g.PUT("/categories/:id", CategoryController.UpdateCategory, middlewares.JWTMiddleware)
-	g.DELETE("/categories/:id", CategoryController.DeleteCategory, middlewares.JWTMiddleware)
+	g.GET("/categories/tree", CategoryController.GetCategoryTree)
+	g.PUT("/categories/reorder", CategoryController.ReorderCategories, auth)
+	g.POST("/categories", CategoryController.CreateCategory, auth)
+	g.GET("/categories/:id", CategoryController.GetCategoryByID, auth)
+	g.PUT("/categories/:id", CategoryController.UpdateCategory, auth)
+	g.DELETE("/categories/:id", CategoryController.DeleteCategory, auth)
+
+	// AVAILABILITY ROUTES
+	AvailabilityController := controllers.NewAvailabilityController(db, cfg, rdb)
+	g.GET("/categories/:id/availability", AvailabilityController.GetCategoryAvailability, auth)
+	g.PUT("/categories/:id/availability", AvailabilityController.SetCategoryAvailability, auth)
+	g.GET("/menu/:id/availability", AvailabilityController.GetMenuAvailability, auth)
+	g.PUT("/menu/:id/availability", AvailabilityController.SetMenuAvailability, auth)
 
 	// MENU ROUTES
-	MenuController := controllers.NewMenuController(db)
+	MenuController := controllers.NewMenuController(db, cfg, rdb, store)
 	g.GET("/menu", MenuController.GetAllMenus)
-	g.POST("/menu", MenuController.CreateMenu, middlewares.JWTMiddleware)
+	g.GET("/menu/export", MenuController.ExportMenus, auth)
+	g.POST("/menu/import", MenuController.ImportMenus, auth)
+	g.POST("/menu", MenuController.CreateMenu, auth)
 	g.GET("/menu/:id", MenuController.GetMenuByID)
-	g.PATCH("/menu/:id", MenuController.UpdateMenu, middlewares.JWTMiddleware)
-	g.DELETE("/menu/:id", MenuController.DeleteMenu, middlewares.JWTMiddleware)
+	g.PATCH("/menu/:id", MenuController.UpdateMenu, auth)
+	g.DELETE("/menu/:id", MenuController.DeleteMenu, auth)
+	g.PATCH("/menu/:id/sold-out", MenuController.SetSoldOut, auth)
+
+	// MENU OPTION ROUTES
+	MenuOptionController := controllers.NewMenuOptionController(db, cfg, rdb)
+	g.POST("/menu/:id/variants", MenuOptionController.CreateVariant, auth)
+	g.PUT("/menu/:id/variants/:variant_id", MenuOptionController.UpdateVariant, auth)
+	g.DELETE("/menu/:id/variants/:variant_id", MenuOptionController.DeleteVariant, auth)
+	g.POST("/menu/:id/modifiers", MenuOptionController.CreateModifier, auth)
+	g.PUT("/menu/:id/modifiers/:modifier_id", MenuOptionController.UpdateModifier, auth)
+	g.DELETE("/menu/:id/modifiers/:modifier_id", MenuOptionController.DeleteModifier, auth)
+
+	// PROMOTION ROUTES
+	PromotionController := controllers.NewPromotionController(db, cfg, rdb)
+	g.GET("/promotions", PromotionController.GetAllPromotions, auth)
+	g.POST("/promotions", PromotionController.CreatePromotion, auth)
+	g.GET("/promotions/:id", PromotionController.GetPromotionByID, auth)
+	g.PUT("/promotions/:id", PromotionController.UpdatePromotion, auth)
+	g.DELETE("/promotions/:id", PromotionController.DeletePromotion, auth)
+
+	// VOUCHER ROUTES
+	VoucherController := controllers.NewVoucherController(db, cfg, rdb)
+	g.GET("/vouchers", VoucherController.GetAllVouchers, auth)
+	g.POST("/vouchers", VoucherController.CreateVoucher, auth)
+	g.POST("/vouchers/validate", VoucherController.ValidateVoucher, auth)
+	g.GET("/vouchers/:id", VoucherController.GetVoucherByID, auth)
+	g.PUT("/vouchers/:id", VoucherController.UpdateVoucher, auth)
+	g.DELETE("/vouchers/:id", VoucherController.DeleteVoucher, auth)
+
+	// TAX ROUTES
+	TaxController := controllers.NewTaxController(db, cfg, rdb)
+	g.GET("/tax-rates", TaxController.GetAllTaxRates, auth)
+	g.POST("/tax-rates", TaxController.CreateTaxRate, auth)
+	g.PUT("/tax-rates/:id", TaxController.UpdateTaxRate, auth)
+	g.DELETE("/tax-rates/:id", TaxController.DeleteTaxRate, auth)
+	g.GET("/service-charges", TaxController.GetAllServiceCharges, auth)
+	g.POST("/service-charges", TaxController.CreateServiceCharge, auth)
+	g.PUT("/service-charges/:id", TaxController.UpdateServiceCharge, auth)
+	g.DELETE("/service-charges/:id", TaxController.DeleteServiceCharge, auth)
+
+	// ORDER ROUTES
+	OrderController := controllers.NewOrderController(db, cfg, rdb)
+	g.POST("/orders", OrderController.CreateOrder, auth)
+	g.PATCH("/orders/:id/prepare", OrderController.PrepareOrder, auth)
+	g.PATCH("/orders/:id/complete", OrderController.CompleteOrder, auth)
+	g.PATCH("/orders/:id/cancel", OrderController.CancelOrder, auth)
+	g.POST("/orders/:id/refunds", OrderController.RefundOrder, auth)
+	g.GET("/orders/:id/credit-notes", OrderController.GetCreditNotes, auth)
+
+	// POS ROUTES
+	POSController := controllers.NewPOSController(db, cfg, rdb)
+	g.GET("/pos/customers", POSController.FindCustomers, auth)
+	g.POST("/pos/orders/preview", POSController.PreviewOrder, auth)
+	g.POST("/pos/orders", POSController.CreateOrder, auth)
+	g.GET("/pos/orders/:id/receipt", POSController.GetReceipt, auth)
+
+	// SHIFT ROUTES
+	ShiftController := controllers.NewShiftController(db, cfg)
+	g.GET("/shifts", ShiftController.GetShifts, auth)
+	g.POST("/shifts/open", ShiftController.OpenShift, auth)
+	g.GET("/shifts/current", ShiftController.GetCurrentShift, auth)
+	g.POST("/shifts/current/movements", ShiftController.RecordMovement, auth)
+	g.POST("/shifts/current/close", ShiftController.CloseShift, auth)
+	g.GET("/shifts/:id/report", ShiftController.GetShiftReport, auth)
+
+	// CART ROUTES
+	CartController := controllers.NewCartController(db, cfg, rdb)
+	g.GET("/cart", CartController.GetCart, optionalAuth)
+	g.PATCH("/cart", CartController.UpdateCart, optionalAuth)
+	g.DELETE("/cart", CartController.ClearCart, optionalAuth)
+	g.POST("/cart/merge", CartController.MergeCart, auth)
+	g.POST("/cart/items", CartController.AddItem, optionalAuth)
+	g.PATCH("/cart/items/:line", CartController.UpdateItem, optionalAuth)
+	g.DELETE("/cart/items/:line", CartController.RemoveItem, optionalAuth)
+	g.PUT("/cart/voucher", CartController.SetVoucher, optionalAuth)
+	g.DELETE("/cart/voucher", CartController.RemoveVoucher, optionalAuth)
+	g.POST("/cart/checkout", CartController.Checkout, auth)
+}
+
+// imageRoutePath is the path part of STORAGE_PUBLIC_BASE_URL, so the URLs
+// the local store hands out are served by this instance even when the base
+// URL is absolute.
+func imageRoutePath(publicBaseURL string) string {
+	u, err := url.Parse(publicBaseURL)
+	if err != nil || strings.Trim(u.Path, "/") == "" {
+		return "/images"
+	}
+	return "/" + strings.Trim(u.Path, "/")
 }
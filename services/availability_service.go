@@ -0,0 +1,239 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidAvailability marks a schedule that fails validation.
+var ErrInvalidAvailability = errors.New("invalid availability")
+
+type AvailabilityService interface {
+	Snapshot(now time.Time) (*AvailabilitySnapshot, error)
+	GetMenuWindows(menuID uint) ([]dto.AvailabilityWindowResponse, error)
+	GetCategoryWindows(categoryID uint) ([]dto.AvailabilityWindowResponse, error)
+	SetMenuWindows(menuID uint, windows []dto.AvailabilityWindowRequest) ([]dto.AvailabilityWindowResponse, error)
+	SetCategoryWindows(categoryID uint, windows []dto.AvailabilityWindowRequest) ([]dto.AvailabilityWindowResponse, error)
+}
+
+type AvailabilityServiceImpl struct {
+	AvailabilityRepo repositories.AvailabilityRepository
+	MenuRepo         repositories.MenuRepository
+	CategoryRepo     repositories.CategoryRepository
+	Location         *time.Location
+}
+
+// AvailabilitySnapshot holds every schedule as of one instant, so a whole
+// menu listing or order is checked against the same clock and data.
+type AvailabilitySnapshot struct {
+	now             time.Time
+	shopLocation    *time.Location
+	locations       map[string]*time.Location
+	menuWindows     map[uint][]models.AvailabilityWindow
+	categoryWindows map[uint][]models.AvailabilityWindow
+	parents         map[uint]*uint
+}
+
+// Snapshot implements AvailabilityService.
+func (a *AvailabilityServiceImpl) Snapshot(now time.Time) (*AvailabilitySnapshot, error) {
+	windows, err := a.AvailabilityRepo.GetAllWindows()
+	if err != nil {
+		return nil, errors.New("failed to read availability: " + err.Error())
+	}
+	categories, err := a.CategoryRepo.GetAllCategories()
+	if err != nil {
+		return nil, errors.New("failed to read categories: " + err.Error())
+	}
+
+	snapshot := &AvailabilitySnapshot{
+		now:             now,
+		shopLocation:    a.Location,
+		locations:       make(map[string]*time.Location),
+		menuWindows:     make(map[uint][]models.AvailabilityWindow),
+		categoryWindows: make(map[uint][]models.AvailabilityWindow),
+		parents:         make(map[uint]*uint, len(categories)),
+	}
+	for _, window := range windows {
+		switch {
+		case window.MenuID != nil:
+			snapshot.menuWindows[*window.MenuID] = append(snapshot.menuWindows[*window.MenuID], window)
+		case window.CategoryID != nil:
+			snapshot.categoryWindows[*window.CategoryID] = append(snapshot.categoryWindows[*window.CategoryID], window)
+		}
+	}
+	for _, category := range categories {
+		snapshot.parents[category.ID] = category.ParentID
+	}
+	return snapshot, nil
+}
+
+// MenuAvailable reports whether a menu item can be ordered. It must not
+// be sold out, one of its own windows must match when it has any, and so
+// must one window of its category and of every parent category.
+func (s *AvailabilitySnapshot) MenuAvailable(menu *models.Menu) bool {
+	if menu.SoldOut || !s.anyMatches(s.menuWindows[menu.ID]) {
+		return false
+	}
+
+	seen := make(map[uint]bool)
+	for id := &menu.CategoryID; id != nil && *id != 0 && !seen[*id]; id = s.parents[*id] {
+		seen[*id] = true
+		if !s.anyMatches(s.categoryWindows[*id]) {
+			return false
+		}
+	}
+	return true
+}
+
+// anyMatches is true when there is no restriction or one window matches.
+func (s *AvailabilitySnapshot) anyMatches(windows []models.AvailabilityWindow) bool {
+	if len(windows) == 0 {
+		return true
+	}
+	for _, window := range windows {
+		if s.matches(window) {
+			return true
+		}
+	}
+	return false
+}
+
+// matches checks one window against the snapshot time, read as wall clock
+// time in the window's timezone.
+func (s *AvailabilitySnapshot) matches(window models.AvailabilityWindow) bool {
+	local := s.now.In(s.location(window.Timezone))
+	return scheduleOpen(local, window.DaysOfWeek, window.StartTime, window.EndTime, window.StartDate, window.EndDate)
+}
+
+// location loads a window timezone once per snapshot. Zones were validated
+// when the window was saved, one that fails to load falls back to the shop.
+func (s *AvailabilitySnapshot) location(name string) *time.Location {
+	if name == "" {
+		return s.shopLocation
+	}
+	if loc, ok := s.locations[name]; ok {
+		return loc
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		loc = s.shopLocation
+	}
+	s.locations[name] = loc
+	return loc
+}
+
+// GetMenuWindows implements AvailabilityService.
+func (a *AvailabilityServiceImpl) GetMenuWindows(menuID uint) ([]dto.AvailabilityWindowResponse, error) {
+	if _, err := a.MenuRepo.GetMenuByID(menuID); err != nil {
+		return nil, fmt.Errorf("failed to get menu: %w", err)
+	}
+	windows, err := a.AvailabilityRepo.FindByMenuID(menuID)
+	if err != nil {
+		return nil, errors.New("failed to get availability: " + err.Error())
+	}
+	return toAvailabilityResponses(windows), nil
+}
+
+// GetCategoryWindows implements AvailabilityService.
+func (a *AvailabilityServiceImpl) GetCategoryWindows(categoryID uint) ([]dto.AvailabilityWindowResponse, error) {
+	if _, err := a.CategoryRepo.GetCategoryByID(categoryID); err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	windows, err := a.AvailabilityRepo.FindByCategoryID(categoryID)
+	if err != nil {
+		return nil, errors.New("failed to get availability: " + err.Error())
+	}
+	return toAvailabilityResponses(windows), nil
+}
+
+// SetMenuWindows implements AvailabilityService.
+func (a *AvailabilityServiceImpl) SetMenuWindows(menuID uint, requests []dto.AvailabilityWindowRequest) ([]dto.AvailabilityWindowResponse, error) {
+	if _, err := a.MenuRepo.GetMenuByID(menuID); err != nil {
+		return nil, fmt.Errorf("failed to get menu: %w", err)
+	}
+	windows, err := toAvailabilityWindows(requests)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.AvailabilityRepo.ReplaceMenuWindows(menuID, windows); err != nil {
+		return nil, errors.New("failed to save availability: " + err.Error())
+	}
+	return toAvailabilityResponses(windows), nil
+}
+
+// SetCategoryWindows implements AvailabilityService.
+func (a *AvailabilityServiceImpl) SetCategoryWindows(categoryID uint, requests []dto.AvailabilityWindowRequest) ([]dto.AvailabilityWindowResponse, error) {
+	if _, err := a.CategoryRepo.GetCategoryByID(categoryID); err != nil {
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+	windows, err := toAvailabilityWindows(requests)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.AvailabilityRepo.ReplaceCategoryWindows(categoryID, windows); err != nil {
+		return nil, errors.New("failed to save availability: " + err.Error())
+	}
+	return toAvailabilityResponses(windows), nil
+}
+
+// toAvailabilityWindows validates requests and converts them to models.
+func toAvailabilityWindows(requests []dto.AvailabilityWindowRequest) ([]models.AvailabilityWindow, error) {
+	windows := make([]models.AvailabilityWindow, 0, len(requests))
+	for i, request := range requests {
+		invalid := func(format string, args ...any) error {
+			return fmt.Errorf("%w: window %d: %s", ErrInvalidAvailability, i+1, fmt.Sprintf(format, args...))
+		}
+
+		days, err := scheduleDays(request.Days)
+		if err != nil {
+			return nil, invalid("%s", err)
+		}
+		if err := validateSchedule(request.StartTime, request.EndTime, request.StartDate, request.EndDate); err != nil {
+			return nil, invalid("%s", err)
+		}
+		if request.Timezone != "" {
+			if _, err := time.LoadLocation(request.Timezone); err != nil {
+				return nil, invalid("unknown timezone %q", request.Timezone)
+			}
+		}
+
+		windows = append(windows, models.AvailabilityWindow{
+			DaysOfWeek: days,
+			StartTime:  scheduleClock(request.StartTime),
+			EndTime:    scheduleClock(request.EndTime),
+			StartDate:  request.StartDate,
+			EndDate:    request.EndDate,
+			Timezone:   request.Timezone,
+		})
+	}
+	return windows, nil
+}
+
+func toAvailabilityResponses(windows []models.AvailabilityWindow) []dto.AvailabilityWindowResponse {
+	responses := make([]dto.AvailabilityWindowResponse, 0, len(windows))
+	for _, window := range windows {
+		responses = append(responses, dto.AvailabilityWindowResponse{
+			ID:        window.ID,
+			Days:      scheduleDayNames(window.DaysOfWeek),
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+			StartDate: window.StartDate,
+			EndDate:   window.EndDate,
+			Timezone:  window.Timezone,
+		})
+	}
+	return responses
+}
+
+func NewAvailabilityService(availabilityRepo repositories.AvailabilityRepository, menuRepo repositories.MenuRepository, categoryRepo repositories.CategoryRepository, location *time.Location) AvailabilityService {
+	return &AvailabilityServiceImpl{
+		AvailabilityRepo: availabilityRepo,
+		MenuRepo:         menuRepo,
+		CategoryRepo:     categoryRepo,
+		Location:         location,
+	}
+}
@@ -0,0 +1,459 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strconv"
+)
+
+// ErrInvalidCart marks a cart request that fails validation.
+var ErrInvalidCart = errors.New("invalid cart request")
+
+// ErrCartLineNotFound is returned for a line ID that is not in the cart.
+var ErrCartLineNotFound = errors.New("cart line not found")
+
+// CartChangedError refuses a checkout when the cart can no longer be
+// ordered as the customer last saw it. Cart is the cart as it is now.
+type CartChangedError struct {
+	Reason string
+	Cart   dto.CartResponse
+}
+
+func (e *CartChangedError) Error() string {
+	return "cart changed: " + e.Reason
+}
+
+// CartOwner identifies a cart: a signed-in user's, or else a guest's
+// session.
+type CartOwner struct {
+	UserID    uint
+	SessionID string
+}
+
+func (o CartOwner) key() string {
+	if o.UserID != 0 {
+		return "user:" + strconv.FormatUint(uint64(o.UserID), 10)
+	}
+	return "session:" + o.SessionID
+}
+
+type CartService interface {
+	GetCart(owner CartOwner) (dto.CartResponse, error)
+	AddItem(owner CartOwner, request dto.OrderItemRequest) (dto.CartResponse, error)
+	UpdateItem(owner CartOwner, lineID int, request dto.OrderItemRequest) (dto.CartResponse, error)
+	RemoveItem(owner CartOwner, lineID int) (dto.CartResponse, error)
+	UpdateCart(owner CartOwner, request dto.CartUpdateRequest) (dto.CartResponse, error)
+	SetVoucher(owner CartOwner, code string) (dto.CartResponse, error)
+	RemoveVoucher(owner CartOwner) (dto.CartResponse, error)
+	ClearCart(owner CartOwner) error
+	MergeGuestCart(sessionID string, userID uint) error
+	Checkout(owner CartOwner, request dto.CartCheckoutRequest) (dto.OrderResponse, error)
+}
+
+type CartServiceImpl struct {
+	CartRepo repositories.CartRepository
+	Orders   OrderService
+	Config   config.CartConfig
+}
+
+// GetCart implements CartService.
+func (s *CartServiceImpl) GetCart(owner CartOwner) (dto.CartResponse, error) {
+	cart, err := s.CartRepo.GetCart(context.Background(), owner.key())
+	if err != nil {
+		return dto.CartResponse{}, errors.New("failed to get cart: " + err.Error())
+	}
+	return s.priceCart(owner, cart)
+}
+
+// AddItem implements CartService.
+// Adding a menu item in a configuration the cart already has adds to the
+// quantity of that line.
+func (s *CartServiceImpl) AddItem(owner CartOwner, request dto.OrderItemRequest) (dto.CartResponse, error) {
+	item, err := s.validateItem(owner, request)
+	if err != nil {
+		return dto.CartResponse{}, err
+	}
+
+	return s.update(owner, func(cart *models.Cart) error {
+		for i := range cart.Items {
+			if sameConfiguration(cart.Items[i], item) {
+				if cart.Items[i].Quantity+item.Quantity > s.Config.MaxQuantity {
+					return fmt.Errorf("%w: at most %d of an item per line", ErrInvalidCart, s.Config.MaxQuantity)
+				}
+				cart.Items[i].Quantity += item.Quantity
+				return nil
+			}
+		}
+		if len(cart.Items) >= s.Config.MaxLines {
+			return fmt.Errorf("%w: a cart holds at most %d lines", ErrInvalidCart, s.Config.MaxLines)
+		}
+		cart.NextLineID++
+		item.LineID = cart.NextLineID
+		cart.Items = append(cart.Items, item)
+		return nil
+	})
+}
+
+// UpdateItem implements CartService.
+// The request replaces the line; a quantity of 0 removes it.
+func (s *CartServiceImpl) UpdateItem(owner CartOwner, lineID int, request dto.OrderItemRequest) (dto.CartResponse, error) {
+	if request.Quantity == 0 {
+		return s.RemoveItem(owner, lineID)
+	}
+	item, err := s.validateItem(owner, request)
+	if err != nil {
+		return dto.CartResponse{}, err
+	}
+
+	return s.update(owner, func(cart *models.Cart) error {
+		index := slices.IndexFunc(cart.Items, func(line models.CartItem) bool { return line.LineID == lineID })
+		if index < 0 {
+			return ErrCartLineNotFound
+		}
+		item.LineID = lineID
+		cart.Items[index] = item
+		return nil
+	})
+}
+
+// RemoveItem implements CartService.
+func (s *CartServiceImpl) RemoveItem(owner CartOwner, lineID int) (dto.CartResponse, error) {
+	return s.update(owner, func(cart *models.Cart) error {
+		index := slices.IndexFunc(cart.Items, func(line models.CartItem) bool { return line.LineID == lineID })
+		if index < 0 {
+			return ErrCartLineNotFound
+		}
+		cart.Items = slices.Delete(cart.Items, index, index+1)
+		return nil
+	})
+}
+
+// UpdateCart implements CartService.
+func (s *CartServiceImpl) UpdateCart(owner CartOwner, request dto.CartUpdateRequest) (dto.CartResponse, error) {
+	if request.OrderType != nil && *request.OrderType != models.OrderTypeTakeaway && *request.OrderType != models.OrderTypeDineIn {
+		return dto.CartResponse{}, fmt.Errorf("%w: order_type must be %s or %s", ErrInvalidCart, models.OrderTypeTakeaway, models.OrderTypeDineIn)
+	}
+
+	return s.update(owner, func(cart *models.Cart) error {
+		if request.OrderType != nil {
+			cart.OrderType = *request.OrderType
+		}
+		if request.Note != nil {
+			cart.Note = *request.Note
+		}
+		return nil
+	})
+}
+
+// SetVoucher implements CartService.
+// A code that cannot be used on the cart as it is is refused with a
+// VoucherRejectedError and not saved. On an empty cart the code is saved
+// as is and checked once there is something to price.
+func (s *CartServiceImpl) SetVoucher(owner CartOwner, code string) (dto.CartResponse, error) {
+	code = NormalizeVoucherCode(code)
+	if code == "" {
+		return dto.CartResponse{}, fmt.Errorf("%w: a voucher code is required", ErrInvalidCart)
+	}
+
+	cart, err := s.CartRepo.GetCart(context.Background(), owner.key())
+	if err != nil {
+		return dto.CartResponse{}, errors.New("failed to get cart: " + err.Error())
+	}
+	cart.VoucherCode = code
+	priced, err := s.priceCart(owner, cart)
+	if err != nil {
+		return dto.CartResponse{}, err
+	}
+	if priced.VoucherError != "" {
+		return dto.CartResponse{}, &VoucherRejectedError{Reason: priced.VoucherError}
+	}
+
+	return s.update(owner, func(cart *models.Cart) error {
+		cart.VoucherCode = code
+		return nil
+	})
+}
+
+// RemoveVoucher implements CartService.
+func (s *CartServiceImpl) RemoveVoucher(owner CartOwner) (dto.CartResponse, error) {
+	return s.update(owner, func(cart *models.Cart) error {
+		cart.VoucherCode = ""
+		return nil
+	})
+}
+
+// ClearCart implements CartService.
+func (s *CartServiceImpl) ClearCart(owner CartOwner) error {
+	if err := s.CartRepo.DeleteCart(context.Background(), owner.key()); err != nil {
+		return errors.New("failed to clear cart: " + err.Error())
+	}
+	return nil
+}
+
+// MergeGuestCart implements CartService.
+// It moves a guest's cart into the user's when a signed-in client asks for
+// it. Lines the user's cart already has add up, the others are appended
+// while there is room; the user's voucher and order details win over the
+// guest's.
+func (s *CartServiceImpl) MergeGuestCart(sessionID string, userID uint) error {
+	ctx := context.Background()
+	guestKey := CartOwner{SessionID: sessionID}.key()
+	guest, err := s.CartRepo.GetCart(ctx, guestKey)
+	if err != nil {
+		return errors.New("failed to get guest cart: " + err.Error())
+	}
+	if len(guest.Items) == 0 && guest.VoucherCode == "" {
+		return nil
+	}
+
+	_, err = s.CartRepo.UpdateCart(ctx, CartOwner{UserID: userID}.key(), func(cart *models.Cart) error {
+		for _, item := range guest.Items {
+			index := slices.IndexFunc(cart.Items, func(line models.CartItem) bool { return sameConfiguration(line, item) })
+			switch {
+			case index >= 0:
+				cart.Items[index].Quantity = min(cart.Items[index].Quantity+item.Quantity, s.Config.MaxQuantity)
+			case len(cart.Items) < s.Config.MaxLines:
+				cart.NextLineID++
+				item.LineID = cart.NextLineID
+				cart.Items = append(cart.Items, item)
+			}
+		}
+		if cart.VoucherCode == "" {
+			cart.VoucherCode = guest.VoucherCode
+		}
+		if cart.OrderType == "" {
+			cart.OrderType = guest.OrderType
+		}
+		if cart.Note == "" {
+			cart.Note = guest.Note
+		}
+		return nil
+	})
+	if err != nil {
+		return errors.New("failed to merge guest cart: " + err.Error())
+	}
+	if err := s.CartRepo.DeleteCart(ctx, guestKey); err != nil {
+		return errors.New("failed to delete guest cart: " + err.Error())
+	}
+	return nil
+}
+
+// Checkout implements CartService.
+// The cart is priced again from the menu first. Lines that can no longer
+// be ordered, a voucher that no longer applies or a total other than
+// ExpectedTotal refuse the checkout with a CartChangedError, so the
+// customer never pays for something they were not shown. The cart is
+// emptied once the order is placed.
+func (s *CartServiceImpl) Checkout(owner CartOwner, request dto.CartCheckoutRequest) (dto.OrderResponse, error) {
+	if owner.UserID == 0 {
+		return dto.OrderResponse{}, fmt.Errorf("%w: sign in to check out", ErrInvalidCart)
+	}
+
+	ctx := context.Background()
+	cart, err := s.CartRepo.GetCart(ctx, owner.key())
+	if err != nil {
+		return dto.OrderResponse{}, errors.New("failed to get cart: " + err.Error())
+	}
+	if len(cart.Items) == 0 {
+		return dto.OrderResponse{}, fmt.Errorf("%w: the cart is empty", ErrInvalidCart)
+	}
+
+	priced, err := s.priceCart(owner, cart)
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	for _, item := range priced.Items {
+		if item.Unavailable != "" {
+			return dto.OrderResponse{}, &CartChangedError{Reason: "some items cannot be ordered right now", Cart: priced}
+		}
+	}
+	if priced.VoucherError != "" {
+		return dto.OrderResponse{}, &CartChangedError{Reason: "voucher cannot be used: " + priced.VoucherError, Cart: priced}
+	}
+	if request.ExpectedTotal != nil && priced.Pricing != nil && math.Abs(*request.ExpectedTotal-priced.Pricing.GrandTotal) >= 0.005 {
+		return dto.OrderResponse{}, &CartChangedError{Reason: "the total of the cart has changed", Cart: priced}
+	}
+
+	orderRequest := cartOrderRequest(cart, cart.Items)
+	orderRequest.RewardID = request.RewardID
+	order, err := s.Orders.CreateOrder(owner.UserID, orderRequest)
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	if err := s.CartRepo.DeleteCart(ctx, owner.key()); err != nil {
+		log.Default().Println("Failed to clear cart after checkout: " + err.Error())
+	}
+	return order, nil
+}
+
+// validateItem checks a line on its own, like an order of just that line
+// would be, and returns it as a cart item.
+func (s *CartServiceImpl) validateItem(owner CartOwner, request dto.OrderItemRequest) (models.CartItem, error) {
+	if request.Quantity < 1 || request.Quantity > s.Config.MaxQuantity {
+		return models.CartItem{}, fmt.Errorf("%w: quantity must be between 1 and %d", ErrInvalidCart, s.Config.MaxQuantity)
+	}
+	modifierIDs := slices.Clone(request.ModifierIDs)
+	slices.Sort(modifierIDs)
+	if len(slices.Compact(slices.Clone(modifierIDs))) != len(modifierIDs) {
+		return models.CartItem{}, fmt.Errorf("%w: a modifier is listed twice", ErrInvalidCart)
+	}
+
+	request.ModifierIDs = modifierIDs
+	if _, err := s.Orders.PreviewOrder(owner.UserID, dto.OrderRequest{Items: []dto.OrderItemRequest{request}}); err != nil {
+		return models.CartItem{}, err
+	}
+	return models.CartItem{
+		MenuID:      request.MenuID,
+		VariantID:   request.VariantID,
+		ModifierIDs: modifierIDs,
+		Quantity:    request.Quantity,
+	}, nil
+}
+
+// update changes the cart of owner and prices the result.
+func (s *CartServiceImpl) update(owner CartOwner, change func(cart *models.Cart) error) (dto.CartResponse, error) {
+	cart, err := s.CartRepo.UpdateCart(context.Background(), owner.key(), change)
+	if errors.Is(err, ErrInvalidCart) || errors.Is(err, ErrCartLineNotFound) {
+		return dto.CartResponse{}, err
+	}
+	if err != nil {
+		return dto.CartResponse{}, fmt.Errorf("failed to update cart: %w", err)
+	}
+	return s.priceCart(owner, cart)
+}
+
+// priceCart prices a cart through the order pipeline. Lines that cannot
+// be ordered and a voucher that cannot be used are reported on the cart
+// and left out, so the rest is still priced.
+func (s *CartServiceImpl) priceCart(owner CartOwner, cart *models.Cart) (dto.CartResponse, error) {
+	response := dto.CartResponse{
+		OrderType:   cart.OrderType,
+		Note:        cart.Note,
+		VoucherCode: cart.VoucherCode,
+		Items:       make([]dto.CartItemResponse, 0, len(cart.Items)),
+		UpdatedAt:   cart.UpdatedAt,
+	}
+	if response.OrderType == "" {
+		response.OrderType = models.OrderTypeTakeaway
+	}
+	for _, item := range cart.Items {
+		response.Items = append(response.Items, dto.CartItemResponse{
+			LineID:      item.LineID,
+			MenuID:      item.MenuID,
+			VariantID:   item.VariantID,
+			ModifierIDs: item.ModifierIDs,
+			Quantity:    item.Quantity,
+		})
+	}
+
+	// indexes maps the lines of the order request to the cart lines
+	indexes := make([]int, 0, len(cart.Items))
+	for i := range cart.Items {
+		indexes = append(indexes, i)
+	}
+	voucherCode := cart.VoucherCode
+	for len(indexes) > 0 {
+		lines := make([]models.CartItem, 0, len(indexes))
+		for _, i := range indexes {
+			lines = append(lines, cart.Items[i])
+		}
+		request := cartOrderRequest(cart, lines)
+		request.VoucherCode = voucherCode
+
+		order, err := s.Orders.PreviewOrder(owner.UserID, request)
+		var unavailable *UnavailableItemsError
+		var rejected *VoucherRejectedError
+		switch {
+		case errors.As(err, &unavailable):
+			refused := make(map[int]bool, len(unavailable.Items))
+			for _, item := range unavailable.Items {
+				response.Items[indexes[item.Line-1]].Unavailable = item.Reason
+				refused[item.Line-1] = true
+			}
+			var kept []int
+			for line, i := range indexes {
+				if !refused[line] {
+					kept = append(kept, i)
+				}
+			}
+			indexes = kept
+			continue
+		case errors.As(err, &rejected):
+			response.VoucherError = rejected.Reason
+			voucherCode = ""
+			continue
+		case err != nil:
+			return dto.CartResponse{}, err
+		}
+
+		for line, i := range indexes {
+			response.Items[i].Priced = &order.Items[line]
+		}
+		response.Pricing = &order
+		break
+	}
+	return response, nil
+}
+
+// cartOrderRequest turns lines of a cart into an order request.
+func cartOrderRequest(cart *models.Cart, lines []models.CartItem) dto.OrderRequest {
+	request := dto.OrderRequest{
+		OrderType:   cart.OrderType,
+		Note:        cart.Note,
+		VoucherCode: cart.VoucherCode,
+		Items:       make([]dto.OrderItemRequest, 0, len(lines)),
+	}
+	for _, line := range lines {
+		request.Items = append(request.Items, dto.OrderItemRequest{
+			MenuID:      line.MenuID,
+			VariantID:   line.VariantID,
+			ModifierIDs: line.ModifierIDs,
+			Quantity:    line.Quantity,
+		})
+	}
+	return request
+}
+
+// sameConfiguration reports whether two cart lines hold the same menu
+// item with the same options. Modifier IDs are kept sorted.
+func sameConfiguration(a, b models.CartItem) bool {
+	sameVariant := (a.VariantID == nil && b.VariantID == nil) ||
+		(a.VariantID != nil && b.VariantID != nil && *a.VariantID == *b.VariantID)
+	return a.MenuID == b.MenuID && sameVariant && slices.Equal(a.ModifierIDs, b.ModifierIDs)
+}
+
+// NewCartSessionID returns a random session ID for a guest cart.
+func NewCartSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", errors.New("failed to create cart session: " + err.Error())
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// ValidCartSessionID reports whether id looks like a NewCartSessionID.
+func ValidCartSessionID(id string) bool {
+	if len(id) != 32 {
+		return false
+	}
+	_, err := hex.DecodeString(id)
+	return err == nil
+}
+
+func NewCartService(cartRepo repositories.CartRepository, orders OrderService, cfg config.CartConfig) CartService {
+	return &CartServiceImpl{
+		CartRepo: cartRepo,
+		Orders:   orders,
+		Config:   cfg,
+	}
+}
@@ -5,18 +5,24 @@ import (
 	"coffee_shop/models"
 	"coffee_shop/repositories"
 	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
 )
 
 type CategoryService interface {
 	CreateCategory(categoryRequest models.Category) (dto.CategoryResponse, error)
 	GetCategoryByID(id uint) (dto.CategoryResponse, error)
-	UpdateCategory(id uint, categoryReq models.Category) (dto.CategoryResponse, error)
-	DeleteCategory(id uint) error
+	UpdateCategory(id uint, request dto.CategoryUpdateRequest) (dto.CategoryResponse, error)
+	DeleteCategory(id uint, reassignTo *uint, cascade bool) (dto.CategoryDeleteResponse, error)
 	GetAllCategories() ([]dto.CategoryResponse, error)
+	GetCategoryTree() ([]dto.CategoryResponse, error)
+	Reorder(positions []dto.CategoryPosition) error
 }
 
 type CategoryServiceImpl struct {
 	CategoryRepo repositories.CategoryRepository
+	MenuRepo     repositories.MenuRepository
 }
 
 // GetAllCategories implements CategoryService.
@@ -25,14 +31,121 @@ func (c *CategoryServiceImpl) GetAllCategories() ([]dto.CategoryResponse, error)
 	if err != nil {
 		return nil, errors.New("failed to get categories: " + err.Error())
 	}
+	counts, err := c.MenuRepo.CountByCategory()
+	if err != nil {
+		return nil, errors.New("failed to count menu items: " + err.Error())
+	}
 
 	var CategoryResponse []dto.CategoryResponse
 	for _, category := range categories {
-		CategoryResponse = append(CategoryResponse, dto.ToCategoryResponse(&category))
+		response := dto.ToCategoryResponse(&category)
+		response.ItemCount = counts[category.ID]
+		CategoryResponse = append(CategoryResponse, response)
 	}
 	return CategoryResponse, nil
 }
 
+// GetCategoryTree implements CategoryService.
+// Siblings keep the display order of GetAllCategories. A category whose
+// parent was deleted is shown at the top level instead of disappearing.
+func (c *CategoryServiceImpl) GetCategoryTree() ([]dto.CategoryResponse, error) {
+	categories, err := c.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		exists[category.ID] = true
+	}
+	children := make(map[uint][]dto.CategoryResponse)
+	var roots []dto.CategoryResponse
+	for _, category := range categories {
+		if category.ParentID == nil || !exists[*category.ParentID] {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(nodes []dto.CategoryResponse) []dto.CategoryResponse
+	build = func(nodes []dto.CategoryResponse) []dto.CategoryResponse {
+		for i := range nodes {
+			nodes[i].Children = build(children[nodes[i].ID])
+			nodes[i].TotalItemCount = nodes[i].ItemCount
+			for _, child := range nodes[i].Children {
+				nodes[i].TotalItemCount += child.TotalItemCount
+			}
+		}
+		return nodes
+	}
+	return build(roots), nil
+}
+
+// Reorder implements CategoryService.
+func (c *CategoryServiceImpl) Reorder(positions []dto.CategoryPosition) error {
+	if len(positions) == 0 {
+		return errors.New("no categories to reorder")
+	}
+
+	moved := make(map[uint]*uint, len(positions))
+	categories := make([]models.Category, 0, len(positions))
+	for _, position := range positions {
+		if _, ok := moved[position.ID]; ok {
+			return fmt.Errorf("category %d is listed twice", position.ID)
+		}
+		moved[position.ID] = position.ParentID
+		categories = append(categories, models.Category{
+			Model:     gorm.Model{ID: position.ID},
+			ParentID:  position.ParentID,
+			SortOrder: position.SortOrder,
+		})
+	}
+
+	if err := c.validateParents(moved); err != nil {
+		return err
+	}
+	if err := c.CategoryRepo.Reorder(categories); err != nil {
+		return errors.New("failed to reorder categories: " + err.Error())
+	}
+	return nil
+}
+
+// validateParents checks that applying the given parent changes keeps the
+// categories a tree: every category and parent exists and no category
+// ends up below itself.
+func (c *CategoryServiceImpl) validateParents(changes map[uint]*uint) error {
+	categories, err := c.CategoryRepo.GetAllCategories()
+	if err != nil {
+		return errors.New("failed to get categories: " + err.Error())
+	}
+
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	for id, parentID := range changes {
+		if _, ok := parents[id]; !ok && id != 0 {
+			return fmt.Errorf("category %d not found", id)
+		}
+		parents[id] = parentID
+	}
+
+	for id := range changes {
+		seen := map[uint]bool{id: true}
+		for parentID := parents[id]; parentID != nil; parentID = parents[*parentID] {
+			if _, ok := parents[*parentID]; !ok {
+				return fmt.Errorf("parent category %d not found", *parentID)
+			}
+			if seen[*parentID] {
+				return fmt.Errorf("category %d cannot be moved below itself", id)
+			}
+			seen[*parentID] = true
+		}
+	}
+	return nil
+}
+
 // CreateCategory implements CategoryService.
 func (c *CategoryServiceImpl) CreateCategory(categoryRequest models.Category) (dto.CategoryResponse, error) {
 	// Check if category already exists
@@ -41,8 +154,18 @@ func (c *CategoryServiceImpl) CreateCategory(categoryRequest models.Category) (d
 		return dto.CategoryResponse{}, errors.New("category already exists: " + findCategory.CategoriesName)
 	}
 
+	if categoryRequest.ParentID != nil {
+		// A new category has no ID yet, 0 stands in for it
+		if err := c.validateParents(map[uint]*uint{0: categoryRequest.ParentID}); err != nil {
+			return dto.CategoryResponse{}, err
+		}
+	}
+
 	categoryRequest = models.Category{
 		CategoriesName: categoryRequest.CategoriesName,
+		ParentID:       categoryRequest.ParentID,
+		SortOrder:      categoryRequest.SortOrder,
+		TaxRateID:      categoryRequest.TaxRateID,
 	}
 
 	err = c.CategoryRepo.CreateCategory(&categoryRequest)
@@ -53,12 +176,23 @@ func (c *CategoryServiceImpl) CreateCategory(categoryRequest models.Category) (d
 }
 
 // DeleteCategory implements CategoryService.
-func (c *CategoryServiceImpl) DeleteCategory(id uint) error {
-	err := c.CategoryRepo.DeleteCategory(id)
+// Menu items deleted by a cascade keep their image files until the image
+// GC runs, like any other deleted row outside MenuService.
+func (c *CategoryServiceImpl) DeleteCategory(id uint, reassignTo *uint, cascade bool) (dto.CategoryDeleteResponse, error) {
+	if reassignTo != nil && cascade {
+		return dto.CategoryDeleteResponse{}, errors.New("reassign_to and cascade cannot be combined")
+	}
+
+	deletion, err := c.CategoryRepo.DeleteCategory(id, reassignTo, cascade)
 	if err != nil {
-		return errors.New("failed to delete category: " + err.Error())
+		return dto.CategoryDeleteResponse{}, fmt.Errorf("failed to delete category: %w", err)
 	}
-	return nil
+	return dto.CategoryDeleteResponse{
+		MovedItems:        deletion.MovedItems,
+		MovedCategories:   deletion.MovedCategories,
+		DeletedItems:      deletion.DeletedItems,
+		DeletedCategories: deletion.DeletedCategories,
+	}, nil
 }
 
 // GetCategoryByID implements CategoryService.
@@ -71,21 +205,34 @@ func (c *CategoryServiceImpl) GetCategoryByID(id uint) (dto.CategoryResponse, er
 }
 
 // UpdateCategory implements CategoryService.
-func (c *CategoryServiceImpl) UpdateCategory(id uint, categoryReq models.Category) (dto.CategoryResponse, error) {
+// Fields left out of the request keep their stored value, so a rename
+// does not move the category or reset its position.
+func (c *CategoryServiceImpl) UpdateCategory(id uint, request dto.CategoryUpdateRequest) (dto.CategoryResponse, error) {
 	// Get existing category
 	category, err := c.CategoryRepo.GetCategoryByID(id)
 	if err != nil {
 		return dto.CategoryResponse{}, errors.New("category not found: " + err.Error())
 	}
 
-	// Check if category already exists
-	findCategory, err := c.CategoryRepo.FindByName(categoryReq.CategoriesName)
-	if err == nil && findCategory != nil {
-		return dto.CategoryResponse{}, errors.New("category already exists: " + findCategory.CategoriesName)
+	if request.CategoriesName != "" {
+		// Check if another category already has the name
+		findCategory, err := c.CategoryRepo.FindByName(request.CategoriesName)
+		if err == nil && findCategory != nil && findCategory.ID != category.ID {
+			return dto.CategoryResponse{}, errors.New("category already exists: " + findCategory.CategoriesName)
+		}
+		category.CategoriesName = request.CategoriesName
 	}
 
-	// Update category fields
-	category.CategoriesName = categoryReq.CategoriesName
+	if request.ParentID.Set {
+		if err := c.validateParents(map[uint]*uint{category.ID: request.ParentID.Value}); err != nil {
+			return dto.CategoryResponse{}, err
+		}
+		category.ParentID = request.ParentID.Value
+	}
+	if request.SortOrder != nil {
+		category.SortOrder = *request.SortOrder
+	}
+	category.TaxRateID = request.TaxRateID
 
 	// Save updated category
 	err = c.CategoryRepo.UpdateCategory(&category)
@@ -95,8 +242,9 @@ func (c *CategoryServiceImpl) UpdateCategory(id uint, categoryReq models.Categor
 	return dto.ToCategoryResponse(&category), nil
 }
 
-func NewCategoryService(categoryRepo repositories.CategoryRepository) CategoryService {
+func NewCategoryService(categoryRepo repositories.CategoryRepository, menuRepo repositories.MenuRepository) CategoryService {
 	return &CategoryServiceImpl{
 		CategoryRepo: categoryRepo,
+		MenuRepo:     menuRepo,
 	}
 }
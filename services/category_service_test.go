@@ -0,0 +1,106 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"encoding/json"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+// memoryCategories is a CategoryRepository over a map, for the parts of
+// the interface the category service uses when updating.
+type memoryCategories struct {
+	repositories.CategoryRepository
+	categories map[uint]models.Category
+}
+
+func (m *memoryCategories) GetCategoryByID(id uint) (models.Category, error) {
+	category, ok := m.categories[id]
+	if !ok {
+		return models.Category{}, gorm.ErrRecordNotFound
+	}
+	return category, nil
+}
+
+func (m *memoryCategories) FindByName(name string) (*models.Category, error) {
+	for _, category := range m.categories {
+		if category.CategoriesName == name {
+			return &category, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *memoryCategories) GetAllCategories() ([]models.Category, error) {
+	var categories []models.Category
+	for _, category := range m.categories {
+		categories = append(categories, category)
+	}
+	return categories, nil
+}
+
+func (m *memoryCategories) UpdateCategory(category *models.Category) error {
+	m.categories[category.ID] = *category
+	return nil
+}
+
+func TestUpdateCategoryKeepsOmittedFields(t *testing.T) {
+	parentID := uint(1)
+	tests := []struct {
+		name       string
+		body       string
+		wantName   string
+		wantParent *uint
+		wantSort   int
+	}{
+		{"rename only", `{"categories_name":"ICED TEA"}`, "ICED TEA", &parentID, 3},
+		{"sort order only", `{"sort_order":0}`, "TEA", &parentID, 0},
+		{"move to the top level", `{"parent_id":null}`, "TEA", nil, 3},
+		{"empty body", `{}`, "TEA", &parentID, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memoryCategories{categories: map[uint]models.Category{
+				1: {Model: gorm.Model{ID: 1}, CategoriesName: "DRINKS"},
+				2: {Model: gorm.Model{ID: 2}, CategoriesName: "TEA", ParentID: &parentID, SortOrder: 3},
+			}}
+			var request dto.CategoryUpdateRequest
+			if err := json.Unmarshal([]byte(tt.body), &request); err != nil {
+				t.Fatal(err)
+			}
+
+			service := NewCategoryService(repo, nil)
+			if _, err := service.UpdateCategory(2, request); err != nil {
+				t.Fatal(err)
+			}
+			got := repo.categories[2]
+			if got.CategoriesName != tt.wantName || got.SortOrder != tt.wantSort {
+				t.Errorf("got name %q sort %d, want %q %d", got.CategoriesName, got.SortOrder, tt.wantName, tt.wantSort)
+			}
+			if (got.ParentID == nil) != (tt.wantParent == nil) || (got.ParentID != nil && *got.ParentID != *tt.wantParent) {
+				t.Errorf("got parent %v, want %v", got.ParentID, tt.wantParent)
+			}
+		})
+	}
+}
+
+func TestUpdateCategoryRejectsCycles(t *testing.T) {
+	parentID := uint(1)
+	repo := &memoryCategories{categories: map[uint]models.Category{
+		1: {Model: gorm.Model{ID: 1}, CategoriesName: "DRINKS"},
+		2: {Model: gorm.Model{ID: 2}, CategoriesName: "TEA", ParentID: &parentID},
+	}}
+	var request dto.CategoryUpdateRequest
+	if err := json.Unmarshal([]byte(`{"parent_id":2}`), &request); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCategoryService(repo, nil).UpdateCategory(1, request); err == nil {
+		t.Error("moving a category below its own child should fail")
+	}
+	if repo.categories[1].ParentID != nil {
+		t.Error("a rejected move must not be saved")
+	}
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"coffee_shop/db/migrations"
+	"coffee_shop/dto"
+	"coffee_shop/lifecycle"
+	"coffee_shop/storage"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Each dependency gets its own deadline so one hung check cannot hold the
+// whole readiness probe past the orchestrator's timeout.
+const readinessCheckTimeout = 2 * time.Second
+
+type HealthService interface {
+	Liveness() dto.LivenessReport
+	Readiness(ctx context.Context) dto.ReadinessReport
+}
+
+type HealthServiceImpl struct {
+	DB        *gorm.DB
+	Redis     redis.UniversalClient
+	Store     storage.BlobStore
+	Lifecycle *lifecycle.Manager
+	startedAt time.Time
+}
+
+// Liveness implements HealthService.
+func (h *HealthServiceImpl) Liveness() dto.LivenessReport {
+	return dto.LivenessReport{
+		Status: dto.HealthStatusUp,
+		Uptime: time.Since(h.startedAt).Round(time.Second).String(),
+	}
+}
+
+// Readiness implements HealthService.
+func (h *HealthServiceImpl) Readiness(ctx context.Context) dto.ReadinessReport {
+	checks := map[string]func(ctx context.Context) (string, error){
+		"mysql":      h.checkMySQL,
+		"redis":      h.checkRedis,
+		"storage":    h.checkStorage,
+		"migrations": h.checkMigrations,
+	}
+
+	report := dto.ReadinessReport{
+		Status: dto.HealthStatusUp,
+		Checks: make(map[string]dto.DependencyCheck, len(checks)+1),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result := runCheck(ctx, check)
+			mu.Lock()
+			report.Checks[name] = result
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if h.Lifecycle.Draining() {
+		report.Checks["lifecycle"] = dto.DependencyCheck{
+			Status: dto.HealthStatusDown,
+			Error:  "shutting down",
+		}
+	}
+
+	for _, check := range report.Checks {
+		if check.Status != dto.HealthStatusUp {
+			report.Status = dto.HealthStatusDown
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, check func(ctx context.Context) (string, error)) dto.DependencyCheck {
+	ctx, cancel := context.WithTimeout(ctx, readinessCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	result := dto.DependencyCheck{
+		Status:    dto.HealthStatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+		Detail:    detail,
+	}
+	if err != nil {
+		result.Status = dto.HealthStatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+func (h *HealthServiceImpl) checkMySQL(ctx context.Context) (string, error) {
+	sqlDB, err := h.DB.DB()
+	if err != nil {
+		return "", err
+	}
+	return "", sqlDB.PingContext(ctx)
+}
+
+func (h *HealthServiceImpl) checkRedis(ctx context.Context) (string, error) {
+	return "", h.Redis.Ping(ctx).Err()
+}
+
+// checkStorage proves the image store is writable by writing and deleting
+// a probe blob, which also catches read-only volumes and bad credentials.
+func (h *HealthServiceImpl) checkStorage(ctx context.Context) (string, error) {
+	const key = ".readyz/probe"
+	if err := h.Store.Put(ctx, key, strings.NewReader("ok"), 2, "text/plain"); err != nil {
+		return "", err
+	}
+	return "", h.Store.Delete(ctx, key)
+}
+
+// checkMigrations compares the version recorded by golang-migrate in
+// schema_migrations against the newest migration embedded in the binary.
+func (h *HealthServiceImpl) checkMigrations(ctx context.Context) (string, error) {
+	latest, err := migrations.LatestVersion()
+	if err != nil {
+		return "", err
+	}
+
+	var row struct {
+		Version uint
+		Dirty   bool
+	}
+	err = h.DB.WithContext(ctx).Raw("SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&row).Error
+	if err != nil {
+		return "", errors.New("failed to read schema_migrations: " + err.Error())
+	}
+
+	detail := fmt.Sprintf("database version %d, binary version %d", row.Version, latest)
+	if row.Dirty {
+		return detail, fmt.Errorf("migration %d is dirty", row.Version)
+	}
+	if row.Version < latest {
+		return detail, fmt.Errorf("%d pending migration(s)", latest-row.Version)
+	}
+	return detail, nil
+}
+
+func NewHealthService(db *gorm.DB, rdb redis.UniversalClient, store storage.BlobStore, lc *lifecycle.Manager) HealthService {
+	return &HealthServiceImpl{
+		DB:        db,
+		Redis:     rdb,
+		Store:     store,
+		Lifecycle: lc,
+		startedAt: time.Now(),
+	}
+}
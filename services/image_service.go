@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/imaging"
+	"coffee_shop/repositories"
+	"coffee_shop/storage"
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"time"
+)
+
+// MenuImagePrefix is the blob store prefix for menu images.
+const MenuImagePrefix = "menu_img"
+
+type ImageService interface {
+	StoreMenuImage(ctx context.Context, content []byte) (string, error)
+	URL(ctx context.Context, stored string) string
+	Renditions(ctx context.Context, stored string) map[string]dto.ImageRendition
+	ReleaseMenuImage(ctx context.Context, stored string) error
+	CollectGarbage(ctx context.Context, grace time.Duration, dryRun bool) (ImageGCResult, error)
+}
+
+// ImageGCResult reports what a garbage collection run found.
+type ImageGCResult struct {
+	Scanned int
+	Deleted []string
+	Bytes   int64
+}
+
+// ImageServiceImpl stores every rendition of an upload under one
+// content-addressed directory:
+//
+//	menu_img/<sha256>/thumbnail.jpg, thumbnail.webp, card.jpg, ..., full.webp
+//
+// The database keeps only the key of the full rendition; the others are
+// derived from it. Menu items without an image get the default image.
+type ImageServiceImpl struct {
+	MenuRepo     repositories.MenuRepository
+	Store        storage.BlobStore
+	Upload       config.UploadConfig
+	DefaultImage string
+}
+
+// StoreMenuImage implements ImageService.
+func (i *ImageServiceImpl) StoreMenuImage(ctx context.Context, content []byte) (string, error) {
+	outputs, err := imaging.Process(content, i.Upload.AllowedTypes)
+	if err != nil {
+		return "", err
+	}
+
+	dir := storage.ContentKey(MenuImagePrefix, content, "")
+	fullKey := ""
+	for _, output := range outputs {
+		key := path.Join(dir, output.Rendition+output.Ext)
+		err := i.Store.Put(ctx, key, bytes.NewReader(output.Data), int64(len(output.Data)), output.ContentType)
+		if err != nil {
+			return "", errors.New("failed to store " + output.Rendition + " image: " + err.Error())
+		}
+		if output.Rendition == imaging.Full && output.Ext != ".webp" {
+			fullKey = key
+		}
+	}
+	return fullKey, nil
+}
+
+// URL implements ImageService.
+func (i *ImageServiceImpl) URL(ctx context.Context, stored string) string {
+	if stored == "" {
+		stored = i.DefaultImage
+	}
+	return storage.ResolveURL(ctx, i.Store, stored)
+}
+
+// Renditions implements ImageService.
+// Images uploaded before renditions existed, and the default image, only
+// have a single file, which is returned as the full rendition.
+func (i *ImageServiceImpl) Renditions(ctx context.Context, stored string) map[string]dto.ImageRendition {
+	if stored == "" {
+		stored = i.DefaultImage
+	}
+	if stored == "" {
+		return nil
+	}
+
+	dir, ext, ok := splitFullKey(storage.NormalizeKey(stored))
+	if !ok {
+		return map[string]dto.ImageRendition{
+			imaging.Full: {URL: storage.ResolveURL(ctx, i.Store, stored)},
+		}
+	}
+
+	renditions := make(map[string]dto.ImageRendition, len(imaging.Renditions))
+	for _, r := range imaging.Renditions {
+		renditions[r.Name] = dto.ImageRendition{
+			URL:     storage.ResolveURL(ctx, i.Store, path.Join(dir, r.Name+ext)),
+			WebPURL: storage.ResolveURL(ctx, i.Store, path.Join(dir, r.Name+".webp")),
+		}
+	}
+	return renditions
+}
+
+// ReleaseMenuImage implements ImageService.
+// It deletes every file of an image once no menu refers to it any more.
+// Keys are content-addressed, so two menus with the same picture share
+// the files and the last one to let go removes them.
+func (i *ImageServiceImpl) ReleaseMenuImage(ctx context.Context, stored string) error {
+	keys := blobKeys(stored)
+	if len(keys) == 0 || storage.NormalizeKey(stored) == storage.NormalizeKey(i.DefaultImage) {
+		return nil
+	}
+
+	referenced, err := i.referencedKeys()
+	if err != nil {
+		return err
+	}
+	if referenced[keys[0]] {
+		return nil
+	}
+
+	var errs []error
+	for _, key := range keys {
+		if err := i.Store.Delete(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return errors.New("failed to delete image: " + err.Error())
+	}
+	return nil
+}
+
+// CollectGarbage implements ImageService.
+// It removes menu images no menu refers to, which are left behind when a
+// release fails or a menu row is removed by hand. Blobs newer than grace
+// are kept: an upload is stored before its menu row is saved.
+func (i *ImageServiceImpl) CollectGarbage(ctx context.Context, grace time.Duration, dryRun bool) (ImageGCResult, error) {
+	var result ImageGCResult
+
+	// Read the references before listing, so an image saved in between is
+	// protected by the grace period instead of being collected
+	referenced, err := i.referencedKeys()
+	if err != nil {
+		return result, err
+	}
+	blobs, err := i.Store.List(ctx, MenuImagePrefix)
+	if err != nil {
+		return result, errors.New("failed to list images: " + err.Error())
+	}
+
+	cutoff := time.Now().Add(-grace)
+	for _, blob := range blobs {
+		result.Scanned++
+		if referenced[blob.Key] || blob.LastModified.After(cutoff) || strings.HasPrefix(path.Base(blob.Key), ".") {
+			continue
+		}
+		if !dryRun {
+			if err := i.Store.Delete(ctx, blob.Key); err != nil {
+				return result, errors.New("failed to delete " + blob.Key + ": " + err.Error())
+			}
+		}
+		result.Deleted = append(result.Deleted, blob.Key)
+		result.Bytes += blob.Size
+	}
+	return result, nil
+}
+
+// referencedKeys returns the store key of every file used by a menu.
+func (i *ImageServiceImpl) referencedKeys() (map[string]bool, error) {
+	images, err := i.MenuRepo.GetImageURLs()
+	if err != nil {
+		return nil, errors.New("failed to read menu images: " + err.Error())
+	}
+	referenced := make(map[string]bool)
+	for _, image := range images {
+		for _, key := range blobKeys(image) {
+			referenced[key] = true
+		}
+	}
+	return referenced, nil
+}
+
+// blobKeys returns the store keys of every file behind a stored image
+// value, the full rendition first. External URLs have none.
+func blobKeys(stored string) []string {
+	if stored == "" || strings.HasPrefix(stored, "http://") || strings.HasPrefix(stored, "https://") {
+		return nil
+	}
+	key := storage.NormalizeKey(stored)
+	dir, ext, ok := splitFullKey(key)
+	if !ok {
+		return []string{key}
+	}
+
+	keys := []string{key, path.Join(dir, imaging.Full+".webp")}
+	for _, r := range imaging.Renditions {
+		if r.Name != imaging.Full {
+			keys = append(keys, path.Join(dir, r.Name+ext), path.Join(dir, r.Name+".webp"))
+		}
+	}
+	return keys
+}
+
+// splitFullKey matches keys written by StoreMenuImage, such as
+// "menu_img/<sha256>/full.jpg", and returns the directory and extension.
+func splitFullKey(key string) (dir, ext string, ok bool) {
+	dir, file := path.Split(key)
+	ext = path.Ext(file)
+	if !strings.HasPrefix(dir, MenuImagePrefix+"/") || strings.TrimSuffix(file, ext) != imaging.Full {
+		return "", "", false
+	}
+	return strings.TrimSuffix(dir, "/"), ext, true
+}
+
+func NewImageService(menuRepo repositories.MenuRepository, store storage.BlobStore, cfg *config.Config) ImageService {
+	return &ImageServiceImpl{
+		MenuRepo:     menuRepo,
+		Store:        store,
+		Upload:       cfg.Upload,
+		DefaultImage: cfg.Storage.DefaultImage,
+	}
+}
@@ -0,0 +1,362 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidLoyalty marks a loyalty request that fails validation.
+var ErrInvalidLoyalty = errors.New("invalid loyalty request")
+
+// RewardRejectedError tells why a loyalty reward cannot be used on an order.
+type RewardRejectedError struct {
+	Reason string
+}
+
+func (e *RewardRejectedError) Error() string {
+	return "reward rejected: " + e.Reason
+}
+
+type LoyaltyService interface {
+	GetLoyalty(userID uint, limit, offset int) (dto.LoyaltyResponse, error)
+	AdjustPoints(adminID uint, request dto.LoyaltyAdjustRequest) (dto.LoyaltyEntryResponse, error)
+	ExpirePoints(now time.Time) (int, error)
+	GetAllRewards(activeOnly bool) ([]dto.LoyaltyRewardResponse, error)
+	CreateReward(request dto.LoyaltyRewardRequest) (dto.LoyaltyRewardResponse, error)
+	UpdateReward(id uint, request dto.LoyaltyRewardRequest) (dto.LoyaltyRewardResponse, error)
+	DeleteReward(id uint) error
+	ApplyReward(order *models.Order, rewardID uint, now time.Time) error
+	EarnedPoints(order *models.Order, now time.Time) *models.LoyaltyEntry
+	ReversedPoints(order *models.Order, entries []models.LoyaltyEntry, refunded float64, full bool) *models.LoyaltyEntry
+	RestoredPoints(order *models.Order, now time.Time) *models.LoyaltyEntry
+}
+
+type LoyaltyServiceImpl struct {
+	LoyaltyRepo repositories.LoyaltyRepository
+	UserRepo    repositories.UserRepository
+	MenuRepo    repositories.MenuRepository
+	Config      config.LoyaltyConfig
+}
+
+// GetLoyalty implements LoyaltyService.
+func (l *LoyaltyServiceImpl) GetLoyalty(userID uint, limit, offset int) (dto.LoyaltyResponse, error) {
+	balance, err := l.LoyaltyRepo.GetBalance(userID, time.Now())
+	if err != nil {
+		return dto.LoyaltyResponse{}, errors.New("failed to get balance: " + err.Error())
+	}
+	entries, total, err := l.LoyaltyRepo.GetEntries(userID, limit, offset)
+	if err != nil {
+		return dto.LoyaltyResponse{}, errors.New("failed to get history: " + err.Error())
+	}
+
+	response := dto.LoyaltyResponse{
+		UserID:  userID,
+		Balance: balance,
+		Total:   total,
+		Entries: make([]dto.LoyaltyEntryResponse, 0, len(entries)),
+	}
+	for _, entry := range entries {
+		response.Entries = append(response.Entries, toLoyaltyEntryResponse(&entry))
+	}
+	return response, nil
+}
+
+// AdjustPoints implements LoyaltyService.
+// The entry records the admin who made it and why. Added points expire
+// like earned ones; removing more than the balance is refused.
+func (l *LoyaltyServiceImpl) AdjustPoints(adminID uint, request dto.LoyaltyAdjustRequest) (dto.LoyaltyEntryResponse, error) {
+	note := strings.TrimSpace(request.Note)
+	switch {
+	case request.Points == 0:
+		return dto.LoyaltyEntryResponse{}, fmt.Errorf("%w: points cannot be 0", ErrInvalidLoyalty)
+	case note == "":
+		return dto.LoyaltyEntryResponse{}, fmt.Errorf("%w: a note is required for adjustments", ErrInvalidLoyalty)
+	}
+	if _, err := l.UserRepo.GetUserByID(request.UserID); err != nil {
+		return dto.LoyaltyEntryResponse{}, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	now := time.Now()
+	entry := models.LoyaltyEntry{
+		UserID:     request.UserID,
+		Type:       models.LoyaltyAdjust,
+		Points:     request.Points,
+		AdjustedBy: &adminID,
+		Note:       note,
+	}
+	if request.Points > 0 {
+		entry.ExpiresAt = l.expiry(now)
+	}
+	err := l.LoyaltyRepo.AddEntry(&entry, now)
+	if errors.Is(err, repositories.ErrInsufficientPoints) {
+		return dto.LoyaltyEntryResponse{}, fmt.Errorf("%w: %s", ErrInvalidLoyalty, err.Error())
+	}
+	if err != nil {
+		return dto.LoyaltyEntryResponse{}, errors.New("failed to adjust points: " + err.Error())
+	}
+	return toLoyaltyEntryResponse(&entry), nil
+}
+
+// ExpirePoints implements LoyaltyService.
+func (l *LoyaltyServiceImpl) ExpirePoints(now time.Time) (int, error) {
+	expired, err := l.LoyaltyRepo.ExpirePoints(now)
+	if err != nil {
+		return expired, errors.New("failed to expire points: " + err.Error())
+	}
+	return expired, nil
+}
+
+// EarnedPoints implements LoyaltyService.
+// A completed order earns PointsPerOrder, plus a point for every
+// SpendPerPoint of its total. It returns nil when the order earns nothing.
+func (l *LoyaltyServiceImpl) EarnedPoints(order *models.Order, now time.Time) *models.LoyaltyEntry {
+	points := l.Config.PointsPerOrder
+	if l.Config.SpendPerPoint > 0 {
+		points += int(math.Floor(order.TotalPrice / l.Config.SpendPerPoint))
+	}
+	if points <= 0 || order.UserID == 0 {
+		return nil
+	}
+	return &models.LoyaltyEntry{
+		UserID:    order.UserID,
+		Type:      models.LoyaltyEarn,
+		Points:    points,
+		ExpiresAt: l.expiry(now),
+		OrderID:   &order.ID,
+	}
+}
+
+// ReversedPoints implements LoyaltyService.
+// Points earned on an order, according to its ledger entries, are taken
+// back in the share of its grand total refunded so far, refunded included
+// the refund being made; a full refund takes back all of them. It returns
+// nil when there is nothing left to take back.
+func (l *LoyaltyServiceImpl) ReversedPoints(order *models.Order, entries []models.LoyaltyEntry, refunded float64, full bool) *models.LoyaltyEntry {
+	earned, reversed := 0, 0
+	for _, entry := range entries {
+		switch entry.Type {
+		case models.LoyaltyEarn:
+			earned += entry.Points
+		case models.LoyaltyReverse:
+			reversed -= entry.Points
+		}
+	}
+
+	target := earned
+	if !full && order.GrandTotal > 0 {
+		target = int(math.Floor(float64(earned) * min(refunded/order.GrandTotal, 1)))
+	}
+	if target <= reversed {
+		return nil
+	}
+	return &models.LoyaltyEntry{
+		UserID:  order.UserID,
+		Type:    models.LoyaltyReverse,
+		Points:  reversed - target,
+		OrderID: &order.ID,
+	}
+}
+
+// RestoredPoints implements LoyaltyService.
+// It returns the entry that gives back the points spent on the reward of
+// a canceled or fully refunded order, or nil when the order used none.
+// The points are those of the redemption, filled in when it is saved, and
+// expire like newly earned ones.
+func (l *LoyaltyServiceImpl) RestoredPoints(order *models.Order, now time.Time) *models.LoyaltyEntry {
+	if order.LoyaltyRewardID == nil {
+		return nil
+	}
+	return &models.LoyaltyEntry{
+		UserID:    order.UserID,
+		Type:      models.LoyaltyRestore,
+		ExpiresAt: l.expiry(now),
+		OrderID:   &order.ID,
+	}
+}
+
+// ApplyReward implements LoyaltyService.
+// It runs last on a priced order. A free item reward takes one unit of
+// that item off, an amount reward takes its amount off, both at most what
+// is left to pay. The balance is checked again when the order is saved.
+func (l *LoyaltyServiceImpl) ApplyReward(order *models.Order, rewardID uint, now time.Time) error {
+	reward, err := l.LoyaltyRepo.GetRewardByID(rewardID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &RewardRejectedError{Reason: "unknown reward"}
+	}
+	if err != nil {
+		return errors.New("failed to get reward: " + err.Error())
+	}
+	if !reward.Active {
+		return &RewardRejectedError{Reason: "reward is not active"}
+	}
+
+	balance, err := l.LoyaltyRepo.GetBalance(order.UserID, now)
+	if err != nil {
+		return errors.New("failed to get balance: " + err.Error())
+	}
+	if balance < reward.Points {
+		return &RewardRejectedError{Reason: fmt.Sprintf("reward needs %d points, %d available", reward.Points, balance)}
+	}
+
+	discount := reward.DiscountAmount
+	if reward.MenuID != nil {
+		discount = 0
+		for _, item := range order.Items {
+			if item.MenuID == *reward.MenuID {
+				discount = math.Min(item.Price, item.Price*float64(item.Quantity)-item.Discount)
+				break
+			}
+		}
+		if discount <= 0 {
+			return &RewardRejectedError{Reason: "the order does not contain the reward item"}
+		}
+	}
+	discount = roundMoney(math.Max(0, math.Min(discount, order.TotalPrice)))
+
+	order.LoyaltyRewardID = &reward.ID
+	order.LoyaltyDiscount = discount
+	order.TotalPrice = roundMoney(order.TotalPrice - discount)
+	return nil
+}
+
+// GetAllRewards implements LoyaltyService.
+func (l *LoyaltyServiceImpl) GetAllRewards(activeOnly bool) ([]dto.LoyaltyRewardResponse, error) {
+	rewards, err := l.LoyaltyRepo.GetAllRewards(activeOnly)
+	if err != nil {
+		return nil, errors.New("failed to get rewards: " + err.Error())
+	}
+	responses := make([]dto.LoyaltyRewardResponse, 0, len(rewards))
+	for _, reward := range rewards {
+		responses = append(responses, toLoyaltyRewardResponse(&reward))
+	}
+	return responses, nil
+}
+
+// CreateReward implements LoyaltyService.
+func (l *LoyaltyServiceImpl) CreateReward(request dto.LoyaltyRewardRequest) (dto.LoyaltyRewardResponse, error) {
+	var reward models.LoyaltyReward
+	if err := l.fillReward(&reward, request); err != nil {
+		return dto.LoyaltyRewardResponse{}, err
+	}
+	if err := l.LoyaltyRepo.CreateReward(&reward); err != nil {
+		return dto.LoyaltyRewardResponse{}, errors.New("failed to create reward: " + err.Error())
+	}
+	return toLoyaltyRewardResponse(&reward), nil
+}
+
+// UpdateReward implements LoyaltyService.
+// The request replaces every field of the reward.
+func (l *LoyaltyServiceImpl) UpdateReward(id uint, request dto.LoyaltyRewardRequest) (dto.LoyaltyRewardResponse, error) {
+	reward, err := l.LoyaltyRepo.GetRewardByID(id)
+	if err != nil {
+		return dto.LoyaltyRewardResponse{}, fmt.Errorf("failed to get reward: %w", err)
+	}
+	if err := l.fillReward(&reward, request); err != nil {
+		return dto.LoyaltyRewardResponse{}, err
+	}
+	if err := l.LoyaltyRepo.UpdateReward(&reward); err != nil {
+		return dto.LoyaltyRewardResponse{}, errors.New("failed to update reward: " + err.Error())
+	}
+	return toLoyaltyRewardResponse(&reward), nil
+}
+
+// DeleteReward implements LoyaltyService.
+// The ledger keeps the ID of the reward points were redeemed for.
+func (l *LoyaltyServiceImpl) DeleteReward(id uint) error {
+	if err := l.LoyaltyRepo.DeleteReward(id); err != nil {
+		return fmt.Errorf("failed to delete reward: %w", err)
+	}
+	return nil
+}
+
+// fillReward validates request and copies it onto reward.
+func (l *LoyaltyServiceImpl) fillReward(reward *models.LoyaltyReward, request dto.LoyaltyRewardRequest) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidLoyalty, fmt.Sprintf(format, args...))
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return invalid("name is required")
+	}
+	if request.Points < 1 {
+		return invalid("points must be at least 1")
+	}
+	switch {
+	case request.MenuID != nil && request.DiscountAmount != 0:
+		return invalid("menu_id and discount_amount cannot both be set")
+	case request.MenuID == nil && request.DiscountAmount <= 0:
+		return invalid("either menu_id or a discount_amount above 0 is required")
+	}
+	if request.MenuID != nil {
+		_, err := l.MenuRepo.GetMenuByID(*request.MenuID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid("menu %d does not exist", *request.MenuID)
+		}
+		if err != nil {
+			return errors.New("failed to get menu: " + err.Error())
+		}
+	}
+
+	reward.Name = name
+	reward.Points = request.Points
+	reward.MenuID = request.MenuID
+	reward.DiscountAmount = request.DiscountAmount
+	reward.Active = request.Active == nil || *request.Active
+	return nil
+}
+
+// expiry is when points added at now expire, nil when they never do.
+func (l *LoyaltyServiceImpl) expiry(now time.Time) *time.Time {
+	if l.Config.PointExpiry <= 0 {
+		return nil
+	}
+	expiresAt := now.Add(l.Config.PointExpiry)
+	return &expiresAt
+}
+
+func toLoyaltyEntryResponse(entry *models.LoyaltyEntry) dto.LoyaltyEntryResponse {
+	return dto.LoyaltyEntryResponse{
+		ID:         entry.ID,
+		Type:       entry.Type,
+		Points:     entry.Points,
+		Remaining:  entry.Remaining,
+		ExpiresAt:  entry.ExpiresAt,
+		OrderID:    entry.OrderID,
+		RewardID:   entry.RewardID,
+		AdjustedBy: entry.AdjustedBy,
+		Note:       entry.Note,
+		CreatedAt:  entry.CreatedAt,
+	}
+}
+
+func toLoyaltyRewardResponse(reward *models.LoyaltyReward) dto.LoyaltyRewardResponse {
+	return dto.LoyaltyRewardResponse{
+		ID:             reward.ID,
+		Name:           reward.Name,
+		Points:         reward.Points,
+		MenuID:         reward.MenuID,
+		DiscountAmount: reward.DiscountAmount,
+		Active:         reward.Active,
+		CreatedAt:      reward.CreatedAt,
+		UpdatedAt:      reward.UpdatedAt,
+	}
+}
+
+func NewLoyaltyService(loyaltyRepo repositories.LoyaltyRepository, userRepo repositories.UserRepository, menuRepo repositories.MenuRepository, cfg config.LoyaltyConfig) LoyaltyService {
+	return &LoyaltyServiceImpl{
+		LoyaltyRepo: loyaltyRepo,
+		UserRepo:    userRepo,
+		MenuRepo:    menuRepo,
+		Config:      cfg,
+	}
+}
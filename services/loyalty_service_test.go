@@ -0,0 +1,84 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/models"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestReversedPoints(t *testing.T) {
+	earn := models.LoyaltyEntry{Type: models.LoyaltyEarn, Points: 25}
+	reverse := func(points int) models.LoyaltyEntry {
+		return models.LoyaltyEntry{Type: models.LoyaltyReverse, Points: -points}
+	}
+	redeem := models.LoyaltyEntry{Type: models.LoyaltyRedeem, Points: -50}
+
+	tests := []struct {
+		name       string
+		grandTotal float64
+		entries    []models.LoyaltyEntry
+		refunded   float64
+		full       bool
+		want       int // points of the entry, 0 for none
+	}{
+		{name: "first partial refund", grandTotal: 100, entries: []models.LoyaltyEntry{earn}, refunded: 40, want: -10},
+		{name: "second partial refund", grandTotal: 100, entries: []models.LoyaltyEntry{earn, reverse(10)}, refunded: 60, want: -5},
+		{name: "rounds down", grandTotal: 100, entries: []models.LoyaltyEntry{earn}, refunded: 3, want: 0},
+		{name: "full refund takes the rest", grandTotal: 100, entries: []models.LoyaltyEntry{earn, reverse(15)}, refunded: 100, full: true, want: -10},
+		{name: "already taken back", grandTotal: 100, entries: []models.LoyaltyEntry{earn, reverse(25)}, refunded: 100, full: true, want: 0},
+		{name: "refunded capped at grand total", grandTotal: 100, entries: []models.LoyaltyEntry{earn}, refunded: 120, want: -25},
+		{name: "other entries ignored", grandTotal: 100, entries: []models.LoyaltyEntry{redeem, earn}, refunded: 40, want: -10},
+		{name: "nothing earned", grandTotal: 100, entries: []models.LoyaltyEntry{redeem}, refunded: 100, full: true, want: 0},
+		{name: "free order", grandTotal: 0, entries: []models.LoyaltyEntry{earn}, refunded: 0, want: -25},
+	}
+	loyalty := &LoyaltyServiceImpl{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Model: gorm.Model{ID: 9}, UserID: 3, GrandTotal: tt.grandTotal}
+			entry := loyalty.ReversedPoints(order, tt.entries, tt.refunded, tt.full)
+			if tt.want == 0 {
+				if entry != nil {
+					t.Fatalf("entry = %+v, want nil", entry)
+				}
+				return
+			}
+			if entry == nil {
+				t.Fatalf("entry = nil, want %d points", tt.want)
+			}
+			if entry.Points != tt.want || entry.Type != models.LoyaltyReverse || entry.UserID != 3 || *entry.OrderID != 9 {
+				t.Errorf("entry = %+v, want %d points reversed on order 9 of user 3", entry, tt.want)
+			}
+		})
+	}
+}
+
+func TestEarnedPoints(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		config config.LoyaltyConfig
+		order  models.Order
+		want   int
+	}{
+		{name: "per order", config: config.LoyaltyConfig{PointsPerOrder: 1}, order: models.Order{UserID: 1, TotalPrice: 99000}, want: 1},
+		{name: "per spend", config: config.LoyaltyConfig{SpendPerPoint: 10000}, order: models.Order{UserID: 1, TotalPrice: 99000}, want: 9},
+		{name: "both", config: config.LoyaltyConfig{PointsPerOrder: 1, SpendPerPoint: 10000}, order: models.Order{UserID: 1, TotalPrice: 20000}, want: 3},
+		{name: "below one point", config: config.LoyaltyConfig{SpendPerPoint: 10000}, order: models.Order{UserID: 1, TotalPrice: 9999}, want: 0},
+		{name: "walk-in", config: config.LoyaltyConfig{PointsPerOrder: 1}, order: models.Order{TotalPrice: 20000}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := (&LoyaltyServiceImpl{Config: tt.config}).EarnedPoints(&tt.order, now)
+			got := 0
+			if entry != nil {
+				got = entry.Points
+			}
+			if got != tt.want {
+				t.Errorf("points = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidMenuOption marks variants and modifiers that cannot be saved.
+var ErrInvalidMenuOption = errors.New("invalid menu option")
+
+// MenuOptionService manages the variants and modifiers of menu items,
+// which orders pick by ID.
+type MenuOptionService interface {
+	CreateVariant(menuID uint, request dto.MenuVariantRequest) (dto.MenuVariantResponse, error)
+	UpdateVariant(menuID uint, id uint, request dto.MenuVariantRequest) (dto.MenuVariantResponse, error)
+	DeleteVariant(menuID uint, id uint) error
+	CreateModifier(menuID uint, request dto.MenuModifierRequest) (dto.MenuModifierResponse, error)
+	UpdateModifier(menuID uint, id uint, request dto.MenuModifierRequest) (dto.MenuModifierResponse, error)
+	DeleteModifier(menuID uint, id uint) error
+}
+
+type MenuOptionServiceImpl struct {
+	MenuRepo repositories.MenuRepository
+}
+
+// CreateVariant implements MenuOptionService.
+func (m *MenuOptionServiceImpl) CreateVariant(menuID uint, request dto.MenuVariantRequest) (dto.MenuVariantResponse, error) {
+	menu, err := m.menu(menuID)
+	if err != nil {
+		return dto.MenuVariantResponse{}, err
+	}
+	variant := models.MenuVariant{MenuID: menu.ID}
+	if err := applyVariant(menu, &variant, request); err != nil {
+		return dto.MenuVariantResponse{}, err
+	}
+	if err := m.MenuRepo.SaveVariant(&variant); err != nil {
+		return dto.MenuVariantResponse{}, errors.New("failed to create variant: " + err.Error())
+	}
+	return dto.ToMenuVariantResponse(&variant), nil
+}
+
+// UpdateVariant implements MenuOptionService.
+// Orders already placed keep the name and price the variant had.
+func (m *MenuOptionServiceImpl) UpdateVariant(menuID uint, id uint, request dto.MenuVariantRequest) (dto.MenuVariantResponse, error) {
+	menu, err := m.menu(menuID)
+	if err != nil {
+		return dto.MenuVariantResponse{}, err
+	}
+	variant, err := m.MenuRepo.GetVariant(menuID, id)
+	if err != nil {
+		return dto.MenuVariantResponse{}, fmt.Errorf("failed to get variant: %w", err)
+	}
+	if err := applyVariant(menu, &variant, request); err != nil {
+		return dto.MenuVariantResponse{}, err
+	}
+	if err := m.MenuRepo.SaveVariant(&variant); err != nil {
+		return dto.MenuVariantResponse{}, errors.New("failed to update variant: " + err.Error())
+	}
+	return dto.ToMenuVariantResponse(&variant), nil
+}
+
+// DeleteVariant implements MenuOptionService.
+func (m *MenuOptionServiceImpl) DeleteVariant(menuID uint, id uint) error {
+	if err := m.MenuRepo.DeleteVariant(menuID, id); err != nil {
+		return fmt.Errorf("failed to delete variant: %w", err)
+	}
+	return nil
+}
+
+// CreateModifier implements MenuOptionService.
+func (m *MenuOptionServiceImpl) CreateModifier(menuID uint, request dto.MenuModifierRequest) (dto.MenuModifierResponse, error) {
+	menu, err := m.menu(menuID)
+	if err != nil {
+		return dto.MenuModifierResponse{}, err
+	}
+	modifier := models.MenuModifier{MenuID: menu.ID}
+	if err := applyModifier(menu, &modifier, request); err != nil {
+		return dto.MenuModifierResponse{}, err
+	}
+	if err := m.MenuRepo.SaveModifier(&modifier); err != nil {
+		return dto.MenuModifierResponse{}, errors.New("failed to create modifier: " + err.Error())
+	}
+	return dto.ToMenuModifierResponse(&modifier), nil
+}
+
+// UpdateModifier implements MenuOptionService.
+// Orders already placed keep the name and price the modifier had.
+func (m *MenuOptionServiceImpl) UpdateModifier(menuID uint, id uint, request dto.MenuModifierRequest) (dto.MenuModifierResponse, error) {
+	menu, err := m.menu(menuID)
+	if err != nil {
+		return dto.MenuModifierResponse{}, err
+	}
+	modifier, err := m.MenuRepo.GetModifier(menuID, id)
+	if err != nil {
+		return dto.MenuModifierResponse{}, fmt.Errorf("failed to get modifier: %w", err)
+	}
+	if err := applyModifier(menu, &modifier, request); err != nil {
+		return dto.MenuModifierResponse{}, err
+	}
+	if err := m.MenuRepo.SaveModifier(&modifier); err != nil {
+		return dto.MenuModifierResponse{}, errors.New("failed to update modifier: " + err.Error())
+	}
+	return dto.ToMenuModifierResponse(&modifier), nil
+}
+
+// DeleteModifier implements MenuOptionService.
+func (m *MenuOptionServiceImpl) DeleteModifier(menuID uint, id uint) error {
+	if err := m.MenuRepo.DeleteModifier(menuID, id); err != nil {
+		return fmt.Errorf("failed to delete modifier: %w", err)
+	}
+	return nil
+}
+
+// menu returns the menu item with its variants and modifiers.
+func (m *MenuOptionServiceImpl) menu(id uint) (*models.Menu, error) {
+	menu, err := m.MenuRepo.GetMenuByID(id, models.MenuRelationVariants, models.MenuRelationModifiers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get menu: %w", err)
+	}
+	return menu, nil
+}
+
+// applyVariant copies the fields of request that were sent onto variant.
+// Names are unique per menu item, ignoring case, and no variant may bring
+// the price below 0.
+func applyVariant(menu *models.Menu, variant *models.MenuVariant, request dto.MenuVariantRequest) error {
+	if name := strings.TrimSpace(request.VariantName); name != "" {
+		variant.VariantName = name
+	}
+	if request.PriceDelta != nil {
+		variant.PriceDelta = roundMoney(*request.PriceDelta)
+	}
+	if request.SortOrder != nil {
+		variant.SortOrder = *request.SortOrder
+	}
+
+	switch {
+	case variant.VariantName == "":
+		return fmt.Errorf("%w: variant_name is required", ErrInvalidMenuOption)
+	case menu.Price+variant.PriceDelta < 0:
+		return fmt.Errorf("%w: price_delta cannot take the price below 0", ErrInvalidMenuOption)
+	}
+	for _, other := range menu.Variants {
+		if other.ID != variant.ID && strings.EqualFold(other.VariantName, variant.VariantName) {
+			return fmt.Errorf("%w: variant %q already exists", ErrInvalidMenuOption, other.VariantName)
+		}
+	}
+	return nil
+}
+
+// applyModifier copies the fields of request that were sent onto
+// modifier. Names are unique per menu item, ignoring case.
+func applyModifier(menu *models.Menu, modifier *models.MenuModifier, request dto.MenuModifierRequest) error {
+	if name := strings.TrimSpace(request.ModifierName); name != "" {
+		modifier.ModifierName = name
+	}
+	if request.Price != nil {
+		modifier.Price = roundMoney(*request.Price)
+	}
+	if request.SortOrder != nil {
+		modifier.SortOrder = *request.SortOrder
+	}
+
+	switch {
+	case modifier.ModifierName == "":
+		return fmt.Errorf("%w: modifier_name is required", ErrInvalidMenuOption)
+	case modifier.Price < 0:
+		return fmt.Errorf("%w: price cannot be negative", ErrInvalidMenuOption)
+	}
+	for _, other := range menu.Modifiers {
+		if other.ID != modifier.ID && strings.EqualFold(other.ModifierName, modifier.ModifierName) {
+			return fmt.Errorf("%w: modifier %q already exists", ErrInvalidMenuOption, other.ModifierName)
+		}
+	}
+	return nil
+}
+
+func NewMenuOptionService(menuRepo repositories.MenuRepository) MenuOptionService {
+	return &MenuOptionServiceImpl{
+		MenuRepo: menuRepo,
+	}
+}
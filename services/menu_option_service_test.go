@@ -0,0 +1,89 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestApplyVariant(t *testing.T) {
+	menu := &models.Menu{
+		Price:    20000,
+		Variants: []models.MenuVariant{{Model: gorm.Model{ID: 1}, MenuID: 7, VariantName: "Large", PriceDelta: 5000}},
+	}
+	name := func(s string) dto.MenuVariantRequest { return dto.MenuVariantRequest{VariantName: s} }
+	delta := func(d float64) *float64 { return &d }
+
+	tests := []struct {
+		name    string
+		variant models.MenuVariant
+		request dto.MenuVariantRequest
+		want    models.MenuVariant
+		wantErr bool
+	}{
+		{name: "new", request: dto.MenuVariantRequest{VariantName: " Small ", PriceDelta: delta(-2000.004)}, want: models.MenuVariant{VariantName: "Small", PriceDelta: -2000}},
+		{name: "missing name", request: dto.MenuVariantRequest{PriceDelta: delta(1000)}, wantErr: true},
+		{name: "duplicate name", request: name("large"), wantErr: true},
+		{name: "rename itself", variant: menu.Variants[0], request: name("LARGE"), want: models.MenuVariant{Model: gorm.Model{ID: 1}, MenuID: 7, VariantName: "LARGE", PriceDelta: 5000}},
+		{name: "keeps omitted fields", variant: menu.Variants[0], request: dto.MenuVariantRequest{}, want: menu.Variants[0]},
+		{name: "price below zero", request: dto.MenuVariantRequest{VariantName: "Kid", PriceDelta: delta(-20001)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := tt.variant
+			err := applyVariant(menu, &variant, tt.request)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMenuOption) {
+					t.Fatalf("err = %v, want ErrInvalidMenuOption", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if variant != tt.want {
+				t.Errorf("variant = %+v, want %+v", variant, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyModifier(t *testing.T) {
+	menu := &models.Menu{
+		Modifiers: []models.MenuModifier{{Model: gorm.Model{ID: 1}, ModifierName: "Extra shot", Price: 5000}},
+	}
+	price := func(p float64) *float64 { return &p }
+
+	tests := []struct {
+		name    string
+		request dto.MenuModifierRequest
+		want    models.MenuModifier
+		wantErr bool
+	}{
+		{name: "new", request: dto.MenuModifierRequest{ModifierName: "Oat milk", Price: price(3000)}, want: models.MenuModifier{ModifierName: "Oat milk", Price: 3000}},
+		{name: "free", request: dto.MenuModifierRequest{ModifierName: "No ice"}, want: models.MenuModifier{ModifierName: "No ice"}},
+		{name: "duplicate name", request: dto.MenuModifierRequest{ModifierName: "extra SHOT"}, wantErr: true},
+		{name: "negative price", request: dto.MenuModifierRequest{ModifierName: "Discount", Price: price(-1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var modifier models.MenuModifier
+			err := applyModifier(menu, &modifier, tt.request)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidMenuOption) {
+					t.Fatalf("err = %v, want ErrInvalidMenuOption", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if modifier != tt.want {
+				t.Errorf("modifier = %+v, want %+v", modifier, tt.want)
+			}
+		})
+	}
+}
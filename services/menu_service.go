@@ -4,20 +4,49 @@ import (
 	"coffee_shop/dto"
 	"coffee_shop/models"
 	"coffee_shop/repositories"
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
 )
 
 type MenuService interface {
 	// Define menu-related business logic methods here
-	GetAllMenus() ([]dto.MenuResponse, error)
+	GetAllMenus(query dto.MenuQuery) ([]dto.MenuResponse, error)
 	CreateMenu(request models.Menu) (dto.MenuResponse, error)
 	UpdateMenu(id uint, request models.Menu) (dto.MenuResponse, error)
 	DeleteMenu(id uint) error
-	GetMenuByID(id uint) (dto.MenuResponse, error)
+	GetMenuByID(id uint, includes []string) (dto.MenuResponse, error)
+	SetSoldOut(id uint, soldOut bool) (dto.MenuResponse, error)
 }
 
 type MenuServiceImpl struct {
-	MenuRepo repositories.MenuRepository
+	MenuRepo     repositories.MenuRepository
+	Images       ImageService
+	Availability AvailabilityService
+}
+
+// toMenuResponse resolves the stored image key into URLs clients can fetch,
+// one per rendition, and tells whether the item can be ordered now.
+func (m *MenuServiceImpl) toMenuResponse(menu *models.Menu, availability *AvailabilitySnapshot) dto.MenuResponse {
+	ctx := context.Background()
+	response := dto.ToMenuResponse(menu)
+	response.ImageURL = m.Images.URL(ctx, menu.ImageURL)
+	response.Images = m.Images.Renditions(ctx, menu.ImageURL)
+	response.Available = availability.MenuAvailable(menu)
+	return response
+}
+
+// toSingleMenuResponse is toMenuResponse for a menu read on its own.
+func (m *MenuServiceImpl) toSingleMenuResponse(menu *models.Menu) (dto.MenuResponse, error) {
+	availability, err := m.Availability.Snapshot(time.Now())
+	if err != nil {
+		return dto.MenuResponse{}, err
+	}
+	return m.toMenuResponse(menu, availability), nil
 }
 
 // CreateMenu implements MenuService.
@@ -40,43 +69,86 @@ func (m *MenuServiceImpl) CreateMenu(request models.Menu) (dto.MenuResponse, err
 	if err != nil {
 		return dto.MenuResponse{}, errors.New("failed to create menu: " + err.Error())
 	}
-	return dto.ToMenuResponse(&requestMenu), nil
+	return m.toSingleMenuResponse(&requestMenu)
 }
 
 // DeleteMenu implements MenuService.
+// Menus are soft deleted, their image files are released right away.
 func (m *MenuServiceImpl) DeleteMenu(id uint) error {
-	err := m.MenuRepo.DeleteMenu(id)
+	menu, err := m.MenuRepo.GetMenuByID(id)
+	if err != nil {
+		return errors.New("failed to delete menu: " + err.Error())
+	}
+
+	err = m.MenuRepo.DeleteMenu(id)
 	if err != nil {
 		return errors.New("failed to delete menu: " + err.Error())
 	}
+	m.releaseImage(menu.ImageURL)
 	return nil
 }
 
+// releaseImage drops files that are no longer used. A failure is only
+// logged: the menu change already happened and the image GC retries.
+func (m *MenuServiceImpl) releaseImage(stored string) {
+	if err := m.Images.ReleaseMenuImage(context.Background(), stored); err != nil {
+		log.Default().Println("Failed to release menu image " + stored + ": " + err.Error())
+	}
+}
+
 // GetAllMenus implements MenuService.
-func (m *MenuServiceImpl) GetAllMenus() ([]dto.MenuResponse, error) {
-	AllMenus, err := m.MenuRepo.GetAllMenus()
+// With query.Available set, only the items whose availability matches it
+// at the time of the request are returned.
+func (m *MenuServiceImpl) GetAllMenus(query dto.MenuQuery) ([]dto.MenuResponse, error) {
+	AllMenus, err := m.MenuRepo.GetAllMenus(query.Includes...)
+	if err != nil {
+		return []dto.MenuResponse{}, err
+	}
+	availability, err := m.Availability.Snapshot(time.Now())
 	if err != nil {
 		return []dto.MenuResponse{}, err
 	}
 
 	var MenuResponses []dto.MenuResponse
 	for _, menu := range AllMenus {
-		MenuResponses = append(MenuResponses, dto.ToMenuResponse(&menu))
+		response := m.toMenuResponse(&menu, availability)
+		if query.Available != nil && response.Available != *query.Available {
+			continue
+		}
+		MenuResponses = append(MenuResponses, response)
 	}
 	return MenuResponses, nil
 }
 
 // GetMenuByID implements MenuService.
-func (m *MenuServiceImpl) GetMenuByID(id uint) (dto.MenuResponse, error) {
-	menu, err := m.MenuRepo.GetMenuByID(id)
+func (m *MenuServiceImpl) GetMenuByID(id uint, includes []string) (dto.MenuResponse, error) {
+	menu, err := m.MenuRepo.GetMenuByID(id, includes...)
 	if err != nil {
 		return dto.MenuResponse{}, errors.New("failed to get menu by id: " + err.Error())
 	}
-	return dto.ToMenuResponse(menu), nil
+	return m.toSingleMenuResponse(menu)
+}
+
+// SetSoldOut implements MenuService.
+func (m *MenuServiceImpl) SetSoldOut(id uint, soldOut bool) (dto.MenuResponse, error) {
+	if err := m.MenuRepo.SetSoldOut(id, soldOut); err != nil {
+		return dto.MenuResponse{}, fmt.Errorf("failed to update sold out: %w", err)
+	}
+	menu, err := m.MenuRepo.GetMenuByID(id)
+	if err != nil {
+		return dto.MenuResponse{}, errors.New("failed to get updated menu: " + err.Error())
+	}
+	return m.toSingleMenuResponse(menu)
 }
 
 // UpdateMenu implements MenuService.
+// An empty ImageURL keeps the current image, a new one replaces it.
 func (m *MenuServiceImpl) UpdateMenu(id uint, request models.Menu) (dto.MenuResponse, error) {
+	current, err := m.MenuRepo.GetMenuByID(id)
+	if err != nil {
+		return dto.MenuResponse{}, errors.New("failed to update menu: " + err.Error())
+	}
+
 	requestMenu := models.Menu{
 		MenuName:    request.MenuName,
 		Price:       request.Price,
@@ -85,15 +157,44 @@ func (m *MenuServiceImpl) UpdateMenu(id uint, request models.Menu) (dto.MenuResp
 		CategoryID:  request.CategoryID,
 	}
 
-	err := m.MenuRepo.UpdateMenu(id, &requestMenu)
+	err = m.MenuRepo.UpdateMenu(id, &requestMenu)
 	if err != nil {
 		return dto.MenuResponse{}, errors.New("failed to update menu: " + err.Error())
 	}
-	return dto.ToMenuResponse(&requestMenu), nil
+	if request.ImageURL != "" && request.ImageURL != current.ImageURL {
+		m.releaseImage(current.ImageURL)
+	}
+
+	updated, err := m.MenuRepo.GetMenuByID(id)
+	if err != nil {
+		return dto.MenuResponse{}, errors.New("failed to get updated menu: " + err.Error())
+	}
+	return m.toSingleMenuResponse(updated)
+}
+
+// ParseMenuIncludes splits an ?include= value such as "category,variants"
+// and rejects names that are not in dto.MenuIncludes.
+func ParseMenuIncludes(param string) ([]string, error) {
+	var includes []string
+	for _, include := range strings.Split(param, ",") {
+		include = strings.ToLower(strings.TrimSpace(include))
+		if include == "" {
+			continue
+		}
+		if !slices.Contains(dto.MenuIncludes, include) {
+			return nil, fmt.Errorf("unknown include %q, expected any of %s", include, strings.Join(dto.MenuIncludes, ", "))
+		}
+		if !slices.Contains(includes, include) {
+			includes = append(includes, include)
+		}
+	}
+	return includes, nil
 }
 
-func NewMenuService(menuRepo repositories.MenuRepository) MenuService {
+func NewMenuService(menuRepo repositories.MenuRepository, images ImageService, availability AvailabilityService) MenuService {
 	return &MenuServiceImpl{
-		MenuRepo: menuRepo,
+		MenuRepo:     menuRepo,
+		Images:       images,
+		Availability: availability,
 	}
 }
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"coffee_shop/cache"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// MenuCSVHeader is the column order written by ExportCSV. ParseMenuCSV
+// accepts the columns in any order as long as the header names match.
+var MenuCSVHeader = []string{"menu_name", "price", "description", "category_name", "image_url"}
+
+type MenuTransferService interface {
+	Export() (dto.MenuExportResponse, error)
+	ExportCSV(w io.Writer) error
+	Import(rows []dto.MenuTransferRow, mode string) (dto.MenuImportResponse, error)
+}
+
+type MenuTransferServiceImpl struct {
+	DB      *gorm.DB
+	Redis   redis.UniversalClient
+	MaxRows int
+}
+
+// Export implements MenuTransferService.
+func (m *MenuTransferServiceImpl) Export() (dto.MenuExportResponse, error) {
+	categories, err := repositories.NewCategoryRepository(m.DB).GetAllCategories()
+	if err != nil {
+		return dto.MenuExportResponse{}, errors.New("failed to get categories: " + err.Error())
+	}
+	menus, err := repositories.NewMenuRepository(m.DB).GetAllMenus()
+	if err != nil {
+		return dto.MenuExportResponse{}, errors.New("failed to get menus: " + err.Error())
+	}
+
+	names := make(map[uint]string, len(categories))
+	response := dto.MenuExportResponse{
+		Categories: []dto.CategoryResponse{},
+		Menus:      []dto.MenuTransferRow{},
+	}
+	for _, category := range categories {
+		names[category.ID] = category.CategoriesName
+		response.Categories = append(response.Categories, dto.ToCategoryResponse(&category))
+	}
+	for _, menu := range menus {
+		response.Menus = append(response.Menus, dto.MenuTransferRow{
+			ID:           menu.ID,
+			MenuName:     menu.MenuName,
+			Price:        menu.Price,
+			Description:  menu.Description,
+			CategoryName: names[menu.CategoryID],
+			ImageURL:     menu.ImageURL,
+		})
+	}
+	return response, nil
+}
+
+// ExportCSV implements MenuTransferService.
+func (m *MenuTransferServiceImpl) ExportCSV(w io.Writer) error {
+	export, err := m.Export()
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(MenuCSVHeader); err != nil {
+		return err
+	}
+	for _, row := range export.Menus {
+		err := writer.Write([]string{
+			row.MenuName,
+			strconv.FormatFloat(row.Price, 'f', -1, 64),
+			row.Description,
+			row.CategoryName,
+			row.ImageURL,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// Import implements MenuTransferService.
+// Rows are matched to existing menus by menu_name, upper-cased like the
+// controllers do. Every row is validated first; in commit mode the file is
+// applied in one transaction and only when no row failed, so a bad file
+// never leaves the menu half imported. A file of more than MaxRows rows is
+// refused as a whole.
+func (m *MenuTransferServiceImpl) Import(rows []dto.MenuTransferRow, mode string) (dto.MenuImportResponse, error) {
+	if mode != dto.MenuImportDryRun && mode != dto.MenuImportCommit {
+		return dto.MenuImportResponse{}, fmt.Errorf("mode must be %s or %s", dto.MenuImportDryRun, dto.MenuImportCommit)
+	}
+	if len(rows) > m.MaxRows {
+		return dto.MenuImportResponse{}, fmt.Errorf("import file has %d rows, at most %d are allowed", len(rows), m.MaxRows)
+	}
+
+	response := dto.MenuImportResponse{Mode: mode, Total: len(rows)}
+
+	err := m.DB.Transaction(func(tx *gorm.DB) error {
+		menuRepo := repositories.NewMenuRepository(tx)
+		categoryRepo := repositories.NewCategoryRepository(tx)
+
+		type plannedRow struct {
+			existing *models.Menu
+			menu     models.Menu
+		}
+		planned := make([]plannedRow, len(rows))
+		seen := map[string]int{}
+
+		for i, row := range rows {
+			// Rows are numbered from 1, a CSV header is not counted
+			result := dto.MenuImportRowResult{Row: i + 1, MenuName: row.MenuName}
+			name := strings.ToTitle(strings.TrimSpace(row.MenuName))
+
+			if name == "" {
+				result.Errors = append(result.Errors, "menu_name is required")
+			} else if first, ok := seen[name]; ok {
+				result.Errors = append(result.Errors, fmt.Sprintf("duplicate of row %d", first))
+			} else {
+				seen[name] = result.Row
+			}
+			if row.Price <= 0 {
+				result.Errors = append(result.Errors, "price must be greater than 0")
+			}
+
+			var categoryID uint
+			if strings.TrimSpace(row.CategoryName) == "" {
+				result.Errors = append(result.Errors, "category_name is required")
+			} else if category, err := categoryRepo.FindByName(strings.ToTitle(strings.TrimSpace(row.CategoryName))); err != nil {
+				result.Errors = append(result.Errors, "category not found: "+row.CategoryName)
+			} else {
+				categoryID = category.ID
+			}
+
+			existing, err := menuRepo.FindByName(name)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+
+			switch {
+			case len(result.Errors) > 0:
+				result.Action = dto.MenuImportActionError
+				response.Failed++
+			case existing != nil:
+				result.Action = dto.MenuImportActionUpdate
+				response.Updated++
+			default:
+				result.Action = dto.MenuImportActionCreate
+				response.Created++
+			}
+			response.Rows = append(response.Rows, result)
+
+			planned[i] = plannedRow{
+				existing: existing,
+				menu: models.Menu{
+					MenuName:    name,
+					Price:       row.Price,
+					Description: row.Description,
+					ImageURL:    row.ImageURL,
+					CategoryID:  categoryID,
+				},
+			}
+		}
+
+		if mode != dto.MenuImportCommit || response.Failed > 0 {
+			return nil
+		}
+
+		for _, p := range planned {
+			if p.existing == nil {
+				if err := menuRepo.CreateMenu(&p.menu); err != nil {
+					return err
+				}
+				continue
+			}
+			// Every imported column is written, so an empty description
+			// clears it; an empty image_url keeps the current image
+			columns := []string{"menu_name", "price", "description", "category_id"}
+			if p.menu.ImageURL != "" {
+				columns = append(columns, "image_url")
+			}
+			if err := menuRepo.UpdateMenuColumns(p.existing.ID, &p.menu, columns...); err != nil {
+				return err
+			}
+		}
+		response.Committed = true
+		return nil
+	})
+	if err != nil {
+		return dto.MenuImportResponse{}, errors.New("failed to import menus: " + err.Error())
+	}
+
+	// The import bypasses the cached repository, drop the cached menu here
+	if response.Committed && m.Redis != nil {
+		if err := cache.Invalidate(context.Background(), m.Redis, cache.MenuNamespace); err != nil {
+			log.Default().Println("Failed to invalidate menu cache: " + err.Error())
+		}
+	}
+	return response, nil
+}
+
+// ParseMenuCSV reads an import file with a header row. Unknown columns are
+// ignored; a price that is not a number is kept as 0 so the row fails
+// validation with the other rows instead of aborting the whole file.
+func ParseMenuCSV(r io.Reader) ([]dto.MenuTransferRow, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, errors.New("failed to read CSV header: " + err.Error())
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"menu_name", "price", "category_name"} {
+		if _, ok := columns[required]; !ok {
+			return nil, errors.New("CSV header is missing column " + required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var rows []dto.MenuTransferRow
+	reader.FieldsPerRecord = -1
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("failed to read CSV: " + err.Error())
+		}
+		price, _ := strconv.ParseFloat(field(record, "price"), 64)
+		rows = append(rows, dto.MenuTransferRow{
+			MenuName:     field(record, "menu_name"),
+			Price:        price,
+			Description:  field(record, "description"),
+			CategoryName: field(record, "category_name"),
+			ImageURL:     field(record, "image_url"),
+		})
+	}
+	return rows, nil
+}
+
+// ParseMenuJSON accepts either a plain array of rows or the object written
+// by the JSON export, so an export can be imported back unchanged.
+func ParseMenuJSON(r io.Reader) ([]dto.MenuTransferRow, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []dto.MenuTransferRow
+	if err := json.Unmarshal(raw, &rows); err == nil {
+		return rows, nil
+	}
+
+	var export dto.MenuExportResponse
+	if err := json.Unmarshal(raw, &export); err != nil {
+		return nil, errors.New("failed to parse JSON: " + err.Error())
+	}
+	return export.Menus, nil
+}
+
+// rdb may be nil when the service is only used for exports.
+func NewMenuTransferService(db *gorm.DB, rdb redis.UniversalClient, maxRows int) MenuTransferService {
+	return &MenuTransferServiceImpl{
+		DB:      db,
+		Redis:   rdb,
+		MaxRows: maxRows,
+	}
+}
@@ -0,0 +1,274 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidOrder marks an order request that fails validation.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// UnavailableItemsError lists the items that cannot be ordered right now.
+type UnavailableItemsError struct {
+	Items []dto.UnavailableItem
+}
+
+func (e *UnavailableItemsError) Error() string {
+	return fmt.Sprintf("%d item(s) cannot be ordered right now", len(e.Items))
+}
+
+type OrderService interface {
+	CreateOrder(userID uint, request dto.OrderRequest) (dto.OrderResponse, error)
+	PriceOrder(userID uint, request dto.OrderRequest) (models.Order, error)
+	SaveOrder(order *models.Order) (dto.OrderResponse, error)
+	PreviewOrder(userID uint, request dto.OrderRequest) (dto.OrderResponse, error)
+	CompleteOrder(id uint) (dto.OrderResponse, int, error)
+	PrepareOrder(id uint) (dto.OrderResponse, error)
+}
+
+type OrderServiceImpl struct {
+	OrderRepo    repositories.OrderRepository
+	MenuRepo     repositories.MenuRepository
+	Availability AvailabilityService
+	Promotions   PromotionService
+	Vouchers     VoucherService
+	Loyalty      LoyaltyService
+	Taxes        TaxService
+}
+
+// CreateOrder implements OrderService.
+// It prices the order as of now and saves it with SaveOrder.
+func (o *OrderServiceImpl) CreateOrder(userID uint, request dto.OrderRequest) (dto.OrderResponse, error) {
+	order, err := o.priceOrder(userID, request, time.Now())
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	return o.SaveOrder(&order)
+}
+
+// PriceOrder implements OrderService.
+// It prices an order like CreateOrder for a caller that adds to it before
+// it is saved with SaveOrder.
+func (o *OrderServiceImpl) PriceOrder(userID uint, request dto.OrderRequest) (models.Order, error) {
+	return o.priceOrder(userID, request, time.Now())
+}
+
+// SaveOrder implements OrderService.
+// The voucher and the loyalty reward of the order, if any, are redeemed in
+// the same transaction the order is saved in.
+func (o *OrderServiceImpl) SaveOrder(order *models.Order) (dto.OrderResponse, error) {
+	err := o.OrderRepo.CreateOrder(order)
+	if errors.Is(err, repositories.ErrVoucherLimitReached) {
+		return dto.OrderResponse{}, &VoucherRejectedError{Reason: err.Error()}
+	}
+	if errors.Is(err, repositories.ErrInsufficientPoints) {
+		return dto.OrderResponse{}, &RewardRejectedError{Reason: err.Error()}
+	}
+	if errors.Is(err, repositories.ErrShiftNotOpen) {
+		return dto.OrderResponse{}, fmt.Errorf("%w: %s", ErrNoOpenShift, err.Error())
+	}
+	if err != nil {
+		return dto.OrderResponse{}, errors.New("failed to create order: " + err.Error())
+	}
+	return dto.ToOrderResponse(order), nil
+}
+
+// CompleteOrder implements OrderService.
+// A pending or preparing order is marked completed and its customer earns
+// their loyalty points; it returns the number of points earned.
+func (o *OrderServiceImpl) CompleteOrder(id uint) (dto.OrderResponse, int, error) {
+	order, err := o.OrderRepo.GetOrderByID(id)
+	if err != nil {
+		return dto.OrderResponse{}, 0, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != models.OrderStatusPending && order.Status != models.OrderStatusPreparing {
+		return dto.OrderResponse{}, 0, fmt.Errorf("%w: order is %s", repositories.ErrOrderNotOpen, order.Status)
+	}
+
+	earned := o.Loyalty.EarnedPoints(&order, time.Now())
+	if err := o.OrderRepo.CompleteOrder(&order, earned); err != nil {
+		return dto.OrderResponse{}, 0, fmt.Errorf("failed to complete order: %w", err)
+	}
+	points := 0
+	if earned != nil {
+		points = earned.Points
+	}
+	return dto.ToOrderResponse(&order), points, nil
+}
+
+// PrepareOrder implements OrderService.
+// A pending order moves to preparing. From then on only staff can cancel
+// it.
+func (o *OrderServiceImpl) PrepareOrder(id uint) (dto.OrderResponse, error) {
+	order, err := o.OrderRepo.GetOrderByID(id)
+	if err != nil {
+		return dto.OrderResponse{}, fmt.Errorf("failed to get order: %w", err)
+	}
+	if order.Status != models.OrderStatusPending {
+		return dto.OrderResponse{}, fmt.Errorf("%w: order is %s", repositories.ErrOrderNotPending, order.Status)
+	}
+
+	if err := o.OrderRepo.PrepareOrder(id); err != nil {
+		return dto.OrderResponse{}, fmt.Errorf("failed to prepare order: %w", err)
+	}
+	order.Status = models.OrderStatusPreparing
+	return dto.ToOrderResponse(&order), nil
+}
+
+// PreviewOrder implements OrderService.
+// It prices an order exactly like CreateOrder without saving it.
+func (o *OrderServiceImpl) PreviewOrder(userID uint, request dto.OrderRequest) (dto.OrderResponse, error) {
+	order, err := o.priceOrder(userID, request, time.Now())
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	return dto.ToOrderResponse(&order), nil
+}
+
+// priceOrder builds an order from a request. Prices come from the menu,
+// never from the client, then promotions, the voucher and the loyalty
+// reward are applied as of now, and taxes and service charge on what is
+// left. Every item is checked against the same
+// availability snapshot and all refused items are reported at once, so the
+// client can fix the order in one go.
+func (o *OrderServiceImpl) priceOrder(userID uint, request dto.OrderRequest, now time.Time) (models.Order, error) {
+	if len(request.Items) == 0 {
+		return models.Order{}, fmt.Errorf("%w: an order needs at least one item", ErrInvalidOrder)
+	}
+
+	availability, err := o.Availability.Snapshot(now)
+	if err != nil {
+		return models.Order{}, err
+	}
+
+	orderType := request.OrderType
+	if orderType == "" {
+		orderType = models.OrderTypeTakeaway
+	}
+	if orderType != models.OrderTypeTakeaway && orderType != models.OrderTypeDineIn {
+		return models.Order{}, fmt.Errorf("%w: order_type must be %s or %s", ErrInvalidOrder, models.OrderTypeTakeaway, models.OrderTypeDineIn)
+	}
+
+	order := models.Order{
+		OrderType: orderType,
+		Note:      request.Note,
+		Status:    models.OrderStatusPending,
+		UserID:    userID,
+	}
+	var unavailable []dto.UnavailableItem
+	for i, item := range request.Items {
+		if item.Quantity < 1 {
+			return models.Order{}, fmt.Errorf("%w: item %d: quantity must be at least 1", ErrInvalidOrder, i+1)
+		}
+
+		menu, err := o.MenuRepo.GetMenuByID(item.MenuID, models.MenuRelationVariants, models.MenuRelationModifiers)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			unavailable = append(unavailable, dto.UnavailableItem{Line: i + 1, MenuID: item.MenuID, Reason: dto.UnavailableNotFound})
+			continue
+		}
+		if err != nil {
+			return models.Order{}, errors.New("failed to get menu: " + err.Error())
+		}
+		if menu.SoldOut {
+			unavailable = append(unavailable, dto.UnavailableItem{Line: i + 1, MenuID: menu.ID, MenuName: menu.MenuName, Reason: dto.UnavailableSoldOut})
+			continue
+		}
+		if !availability.MenuAvailable(menu) {
+			unavailable = append(unavailable, dto.UnavailableItem{Line: i + 1, MenuID: menu.ID, MenuName: menu.MenuName, Reason: dto.UnavailableSchedule})
+			continue
+		}
+
+		line, ok, err := orderLine(menu, item)
+		if err != nil {
+			return models.Order{}, fmt.Errorf("%w: item %d: %s", ErrInvalidOrder, i+1, err.Error())
+		}
+		if !ok {
+			unavailable = append(unavailable, dto.UnavailableItem{Line: i + 1, MenuID: menu.ID, MenuName: menu.MenuName, Reason: dto.UnavailableOption})
+			continue
+		}
+		order.Items = append(order.Items, line)
+	}
+	if len(unavailable) > 0 {
+		return models.Order{}, &UnavailableItemsError{Items: unavailable}
+	}
+
+	if err := o.Promotions.ApplyPromotions(&order, now); err != nil {
+		return models.Order{}, err
+	}
+	if request.VoucherCode != "" {
+		if err := o.Vouchers.ApplyVoucher(&order, request.VoucherCode, now); err != nil {
+			return models.Order{}, err
+		}
+	}
+	if request.RewardID != nil {
+		if err := o.Loyalty.ApplyReward(&order, *request.RewardID, now); err != nil {
+			return models.Order{}, err
+		}
+	}
+	if err := o.Taxes.ApplyTaxes(&order); err != nil {
+		return models.Order{}, err
+	}
+	return order, nil
+}
+
+// orderLine prices one line of menu with the variant and modifiers of
+// item. It reports false when one of them no longer exists on the menu.
+func orderLine(menu *models.Menu, item dto.OrderItemRequest) (models.OrderMenuItem, bool, error) {
+	line := models.OrderMenuItem{
+		MenuID:   menu.ID,
+		Price:    menu.Price,
+		Quantity: item.Quantity,
+		Menu:     *menu,
+	}
+
+	if item.VariantID != nil {
+		index := slices.IndexFunc(menu.Variants, func(variant models.MenuVariant) bool { return variant.ID == *item.VariantID })
+		if index < 0 {
+			return models.OrderMenuItem{}, false, nil
+		}
+		variant := menu.Variants[index]
+		line.VariantID = &variant.ID
+		line.VariantName = variant.VariantName
+		line.Price += variant.PriceDelta
+	}
+
+	seen := make(map[uint]bool, len(item.ModifierIDs))
+	for _, id := range item.ModifierIDs {
+		if seen[id] {
+			return models.OrderMenuItem{}, false, fmt.Errorf("modifier %d is listed twice", id)
+		}
+		seen[id] = true
+		index := slices.IndexFunc(menu.Modifiers, func(modifier models.MenuModifier) bool { return modifier.ID == id })
+		if index < 0 {
+			return models.OrderMenuItem{}, false, nil
+		}
+		modifier := menu.Modifiers[index]
+		line.Modifiers = append(line.Modifiers, models.OrderItemModifier{
+			ModifierID: &modifier.ID,
+			Name:       modifier.ModifierName,
+			Price:      modifier.Price,
+		})
+		line.Price += modifier.Price
+	}
+	line.Price = roundMoney(line.Price)
+	return line, true, nil
+}
+
+func NewOrderService(orderRepo repositories.OrderRepository, menuRepo repositories.MenuRepository, availability AvailabilityService, promotions PromotionService, vouchers VoucherService, loyalty LoyaltyService, taxes TaxService) OrderService {
+	return &OrderServiceImpl{
+		OrderRepo:    orderRepo,
+		MenuRepo:     menuRepo,
+		Availability: availability,
+		Promotions:   promotions,
+		Vouchers:     vouchers,
+		Loyalty:      loyalty,
+		Taxes:        taxes,
+	}
+}
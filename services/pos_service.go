@@ -0,0 +1,323 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidPayment marks payments that do not settle an order.
+var ErrInvalidPayment = errors.New("invalid payment")
+
+// ErrNoOpenShift is returned when a cashier takes an order without an
+// open shift.
+var ErrNoOpenShift = errors.New("no open shift")
+
+type POSService interface {
+	FindCustomers(phone string) ([]dto.POSCustomerResponse, error)
+	PreviewOrder(request dto.POSOrderRequest) (dto.OrderResponse, error)
+	CreateOrder(cashierID uint, request dto.POSOrderRequest) (dto.OrderResponse, error)
+	GetReceipt(id uint) (string, error)
+}
+
+type POSServiceImpl struct {
+	Orders      OrderService
+	OrderRepo   repositories.OrderRepository
+	ShiftRepo   repositories.ShiftRepository
+	UserRepo    repositories.UserRepository
+	LoyaltyRepo repositories.LoyaltyRepository
+	Config      config.ReceiptConfig
+	Location    *time.Location
+}
+
+// FindCustomers implements POSService.
+// Spaces and dashes in phone are ignored.
+func (p *POSServiceImpl) FindCustomers(phone string) ([]dto.POSCustomerResponse, error) {
+	phone = strings.NewReplacer(" ", "", "-", "").Replace(phone)
+	if phone == "" {
+		return nil, fmt.Errorf("%w: a phone number is required", ErrInvalidOrder)
+	}
+
+	users, err := p.UserRepo.FindByPhone(phone)
+	if err != nil {
+		return nil, errors.New("failed to find customers: " + err.Error())
+	}
+	now := time.Now()
+	customers := make([]dto.POSCustomerResponse, 0, len(users))
+	for _, user := range users {
+		balance, err := p.LoyaltyRepo.GetBalance(user.ID, now)
+		if err != nil {
+			return nil, errors.New("failed to get balance: " + err.Error())
+		}
+		customers = append(customers, dto.POSCustomerResponse{
+			ID:            user.ID,
+			Name:          user.Name,
+			PhoneNumber:   user.PhoneNumber,
+			LoyaltyPoints: balance,
+		})
+	}
+	return customers, nil
+}
+
+// PreviewOrder implements POSService.
+// It prices the order for the customer so the cashier can tell them what
+// to pay; payments are not looked at.
+func (p *POSServiceImpl) PreviewOrder(request dto.POSOrderRequest) (dto.OrderResponse, error) {
+	customerID, err := p.customer(request)
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	return p.Orders.PreviewOrder(customerID, request.OrderRequest)
+}
+
+// CreateOrder implements POSService.
+// The order is priced like any other and must be settled by its payments
+// before it is saved under the cashier, in the shift they have open.
+func (p *POSServiceImpl) CreateOrder(cashierID uint, request dto.POSOrderRequest) (dto.OrderResponse, error) {
+	customerID, err := p.customer(request)
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	shift, err := p.ShiftRepo.GetOpenShift(cashierID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return dto.OrderResponse{}, fmt.Errorf("%w: open a shift before taking orders", ErrNoOpenShift)
+	}
+	if err != nil {
+		return dto.OrderResponse{}, errors.New("failed to get shift: " + err.Error())
+	}
+
+	order, err := p.Orders.PriceOrder(customerID, request.OrderRequest)
+	if err != nil {
+		return dto.OrderResponse{}, err
+	}
+	if order.Payments, err = takePayments(order.GrandTotal, request.Payments); err != nil {
+		return dto.OrderResponse{}, err
+	}
+	order.CashierID = &cashierID
+	order.ShiftID = &shift.ID
+	return p.Orders.SaveOrder(&order)
+}
+
+// GetReceipt implements POSService.
+// The receipt is plain text for a receipt printer, Config.Width
+// characters per line. Any order can be printed, also again.
+func (p *POSServiceImpl) GetReceipt(id uint) (string, error) {
+	order, err := p.OrderRepo.GetOrderByID(id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get order: %w", err)
+	}
+
+	width := p.Config.Width
+	var b strings.Builder
+	rule := strings.Repeat("-", width)
+	line := func(left string, amount float64) {
+		b.WriteString(receiptLine(left, strconv.FormatFloat(amount, 'f', 2, 64), width))
+	}
+
+	b.WriteString(receiptCenter(p.Config.ShopName, width))
+	b.WriteString(receiptLine("Order #"+strconv.FormatUint(uint64(order.ID), 10), order.CreatedAt.In(p.Location).Format("2006-01-02 15:04"), width))
+	b.WriteString(receiptLine("Type", order.OrderType, width))
+	if order.CashierID != nil {
+		if cashier, err := p.UserRepo.GetUserByID(*order.CashierID); err == nil {
+			b.WriteString(receiptLine("Cashier", cashier.Name, width))
+		}
+	}
+	if order.UserID != 0 {
+		if customer, err := p.UserRepo.GetUserByID(order.UserID); err == nil {
+			b.WriteString(receiptLine("Customer", customer.Name, width))
+		}
+	}
+	if order.Status == models.OrderStatusCanceled {
+		b.WriteString(receiptCenter("*** CANCELED ***", width))
+	}
+	b.WriteString(rule + "\n")
+
+	for _, item := range order.Items {
+		name := item.Menu.MenuName
+		if item.VariantName != "" {
+			name += " (" + item.VariantName + ")"
+		}
+		line(strconv.Itoa(item.Quantity)+" x "+name, item.Price*float64(item.Quantity))
+		for _, modifier := range item.Modifiers {
+			b.WriteString(receiptLine("  + "+modifier.Name, "", width))
+		}
+		if item.Discount > 0 {
+			line("  Discount", -item.Discount)
+		}
+	}
+	b.WriteString(rule + "\n")
+
+	line("Subtotal", order.Subtotal)
+	if order.DiscountTotal > 0 {
+		line("Discount", -order.DiscountTotal)
+	}
+	for _, tax := range order.Taxes {
+		name := tax.Name + " " + strconv.FormatFloat(tax.Rate, 'f', -1, 64) + "%"
+		if tax.Inclusive {
+			name += " (incl.)"
+		}
+		line(name, tax.Amount)
+	}
+	if order.ServiceCharge > 0 {
+		line("Service charge", order.ServiceCharge)
+	}
+	if order.Rounding != 0 {
+		line("Rounding", order.Rounding)
+	}
+	line("TOTAL", order.GrandTotal)
+
+	if len(order.Payments) > 0 {
+		b.WriteString(rule + "\n")
+		for _, payment := range order.Payments {
+			if payment.Method == models.PaymentCash {
+				line("Cash", payment.Tendered)
+				line("Change", payment.ChangeDue)
+				continue
+			}
+			label := strings.ToUpper(payment.Method[:1]) + payment.Method[1:]
+			if payment.Reference != "" {
+				label += " " + payment.Reference
+			}
+			line(label, payment.Amount)
+		}
+	}
+
+	if p.Config.Footer != "" {
+		b.WriteString(rule + "\n")
+		b.WriteString(receiptCenter(p.Config.Footer, width))
+	}
+	return b.String(), nil
+}
+
+// customer checks the customer of a POS order and returns their user ID,
+// 0 for an anonymous walk-in.
+func (p *POSServiceImpl) customer(request dto.POSOrderRequest) (uint, error) {
+	if request.CustomerID == nil {
+		if request.VoucherCode != "" || request.RewardID != nil {
+			return 0, fmt.Errorf("%w: vouchers and rewards need a customer, look them up by phone", ErrInvalidOrder)
+		}
+		return 0, nil
+	}
+
+	customer, err := p.UserRepo.GetUserByID(*request.CustomerID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, fmt.Errorf("%w: customer not found", ErrInvalidOrder)
+	}
+	if err != nil {
+		return 0, errors.New("failed to get customer: " + err.Error())
+	}
+	return customer.ID, nil
+}
+
+// takePayments turns the payments of a request into those of an order of
+// grandTotal. Together they must pay exactly the grand total, cash may be
+// overpaid and gets change back.
+func takePayments(grandTotal float64, requests []dto.POSPaymentRequest) ([]models.OrderPayment, error) {
+	if len(requests) == 0 {
+		if grandTotal > 0 {
+			return nil, fmt.Errorf("%w: at least one payment is required", ErrInvalidPayment)
+		}
+		return nil, nil
+	}
+
+	paid, rest := 0.0, -1
+	for i, request := range requests {
+		switch {
+		case request.Amount < 0 || request.Tendered < 0:
+			return nil, fmt.Errorf("%w: payment %d: amounts cannot be negative", ErrInvalidPayment, i+1)
+		case request.Amount == 0 && rest >= 0:
+			return nil, fmt.Errorf("%w: payment %d: only one payment can take the rest", ErrInvalidPayment, i+1)
+		case request.Amount == 0:
+			rest = i
+		}
+		paid += request.Amount
+	}
+
+	payments := make([]models.OrderPayment, 0, len(requests))
+	for i, request := range requests {
+		payment := models.OrderPayment{
+			Method:    request.Method,
+			Amount:    roundMoney(request.Amount),
+			Tendered:  roundMoney(request.Tendered),
+			Reference: strings.TrimSpace(request.Reference),
+		}
+		if i == rest {
+			payment.Amount = roundMoney(grandTotal - paid)
+			if payment.Amount <= 0 {
+				return nil, fmt.Errorf("%w: payment %d: nothing is left to pay", ErrInvalidPayment, i+1)
+			}
+		}
+
+		switch payment.Method {
+		case models.PaymentCash:
+			if payment.Tendered == 0 {
+				payment.Tendered = payment.Amount
+			}
+			if payment.Tendered < payment.Amount {
+				return nil, fmt.Errorf("%w: payment %d: %.2f tendered is less than %.2f", ErrInvalidPayment, i+1, payment.Tendered, payment.Amount)
+			}
+			payment.ChangeDue = roundMoney(payment.Tendered - payment.Amount)
+		case models.PaymentCard, models.PaymentEWallet:
+			if payment.Tendered != 0 && payment.Tendered != payment.Amount {
+				return nil, fmt.Errorf("%w: payment %d: only cash can be overpaid", ErrInvalidPayment, i+1)
+			}
+			payment.Tendered = payment.Amount
+		default:
+			return nil, fmt.Errorf("%w: payment %d: method must be %s, %s or %s", ErrInvalidPayment, i+1, models.PaymentCash, models.PaymentCard, models.PaymentEWallet)
+		}
+		payments = append(payments, payment)
+	}
+
+	total := 0.0
+	for _, payment := range payments {
+		total += payment.Amount
+	}
+	if math.Abs(total-grandTotal) >= 0.005 {
+		return nil, fmt.Errorf("%w: payments add up to %.2f, the order is %.2f", ErrInvalidPayment, total, grandTotal)
+	}
+	return payments, nil
+}
+
+// receiptLine puts left and right on one line of width characters, the
+// left part cut short when both do not fit.
+func receiptLine(left, right string, width int) string {
+	room := width - utf8.RuneCountInString(right) - 1
+	if right == "" {
+		room = width
+	}
+	if utf8.RuneCountInString(left) > room {
+		left = string([]rune(left)[:max(room, 0)])
+	}
+	gap := width - utf8.RuneCountInString(left) - utf8.RuneCountInString(right)
+	return left + strings.Repeat(" ", max(gap, 0)) + right + "\n"
+}
+
+// receiptCenter centers text on a line of width characters.
+func receiptCenter(text string, width int) string {
+	if utf8.RuneCountInString(text) > width {
+		text = string([]rune(text)[:width])
+	}
+	return strings.Repeat(" ", (width-utf8.RuneCountInString(text))/2) + text + "\n"
+}
+
+func NewPOSService(orders OrderService, orderRepo repositories.OrderRepository, shiftRepo repositories.ShiftRepository, userRepo repositories.UserRepository, loyaltyRepo repositories.LoyaltyRepository, cfg config.ReceiptConfig, loc *time.Location) POSService {
+	return &POSServiceImpl{
+		Orders:      orders,
+		OrderRepo:   orderRepo,
+		ShiftRepo:   shiftRepo,
+		UserRepo:    userRepo,
+		LoyaltyRepo: loyaltyRepo,
+		Config:      cfg,
+		Location:    loc,
+	}
+}
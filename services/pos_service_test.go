@@ -0,0 +1,130 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"errors"
+	"testing"
+)
+
+func TestTakePayments(t *testing.T) {
+	type want struct {
+		method                   string
+		amount, tendered, change float64
+	}
+	tests := []struct {
+		name       string
+		grandTotal float64
+		requests   []dto.POSPaymentRequest
+		want       []want
+		wantErr    bool
+	}{
+		{
+			name:       "exact cash",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Amount: 25000}},
+			want:       []want{{models.PaymentCash, 25000, 25000, 0}},
+		},
+		{
+			name:       "cash with change",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Amount: 25000, Tendered: 50000}},
+			want:       []want{{models.PaymentCash, 25000, 50000, 25000}},
+		},
+		{
+			name:       "cash takes the rest",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Tendered: 30000}},
+			want:       []want{{models.PaymentCash, 25000, 30000, 5000}},
+		},
+		{
+			name:       "split card and cash",
+			grandTotal: 25000.5,
+			requests: []dto.POSPaymentRequest{
+				{Method: models.PaymentCard, Amount: 20000, Reference: " AUTH1 "},
+				{Method: models.PaymentCash, Tendered: 10000},
+			},
+			want: []want{{models.PaymentCard, 20000, 20000, 0}, {models.PaymentCash, 5000.5, 10000, 4999.5}},
+		},
+		{
+			name:       "free order",
+			grandTotal: 0,
+		},
+		{
+			name:       "no payment",
+			grandTotal: 100,
+			wantErr:    true,
+		},
+		{
+			name:       "short",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCard, Amount: 20000}},
+			wantErr:    true,
+		},
+		{
+			name:       "overpaid",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Amount: 30000}},
+			wantErr:    true,
+		},
+		{
+			name:       "too little tendered",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Amount: 25000, Tendered: 20000}},
+			wantErr:    true,
+		},
+		{
+			name:       "card overpaid",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCard, Amount: 25000, Tendered: 30000}},
+			wantErr:    true,
+		},
+		{
+			name:       "two payments take the rest",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCard}, {Method: models.PaymentCash}},
+			wantErr:    true,
+		},
+		{
+			name:       "nothing left for the rest",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCard, Amount: 25000}, {Method: models.PaymentCash}},
+			wantErr:    true,
+		},
+		{
+			name:       "negative amount",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: models.PaymentCash, Amount: -1}},
+			wantErr:    true,
+		},
+		{
+			name:       "unknown method",
+			grandTotal: 25000,
+			requests:   []dto.POSPaymentRequest{{Method: "cheque", Amount: 25000}},
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payments, err := takePayments(tt.grandTotal, tt.requests)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidPayment) {
+					t.Fatalf("err = %v, want ErrInvalidPayment", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(payments) != len(tt.want) {
+				t.Fatalf("got %d payments, want %d", len(payments), len(tt.want))
+			}
+			for i, payment := range payments {
+				got := want{payment.Method, payment.Amount, payment.Tendered, payment.ChangeDue}
+				if got != tt.want[i] {
+					t.Errorf("payment %d = %+v, want %+v", i+1, got, tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// ErrInvalidPromotion marks a promotion request that fails validation.
+var ErrInvalidPromotion = errors.New("invalid promotion")
+
+var promotionTypes = []string{models.PromotionPercentage, models.PromotionFixed, models.PromotionBuyXGetY}
+
+type PromotionService interface {
+	GetAllPromotions() ([]dto.PromotionResponse, error)
+	GetPromotionByID(id uint) (dto.PromotionResponse, error)
+	CreatePromotion(request dto.PromotionRequest) (dto.PromotionResponse, error)
+	UpdatePromotion(id uint, request dto.PromotionRequest) (dto.PromotionResponse, error)
+	DeletePromotion(id uint) error
+	ApplyPromotions(order *models.Order, now time.Time) error
+}
+
+type PromotionServiceImpl struct {
+	PromotionRepo repositories.PromotionRepository
+	CategoryRepo  repositories.CategoryRepository
+	Location      *time.Location
+}
+
+// ApplyPromotions implements PromotionService.
+// It prices order, whose items must carry their Menu, and records the
+// promotions that were applied on the lines and on the order.
+func (p *PromotionServiceImpl) ApplyPromotions(order *models.Order, now time.Time) error {
+	promotions, err := p.PromotionRepo.GetActivePromotions()
+	if err != nil {
+		return errors.New("failed to read promotions: " + err.Error())
+	}
+	categories, err := p.CategoryRepo.GetAllCategories()
+	if err != nil {
+		return errors.New("failed to read categories: " + err.Error())
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+
+	applyPromotions(order, promotions, parents, now.In(p.Location))
+	return nil
+}
+
+// applyPromotions is the pricing engine. The result only depends on its
+// input, never on the order promotions were loaded in:
+//
+//  1. A promotion counts when it is in its schedule at local and the
+//     subtotal before discounts reaches its minimum spend.
+//  2. Every line gets the line promotion (one with a menu or category
+//     target, or any buy-X-get-Y) worth the largest discount on it.
+//  3. The order gets the order promotion worth the largest discount on
+//     what is left after the line discounts.
+//
+// Promotions never stack on the same line or on the order. Ties go to the
+// higher priority, then to the lower ID.
+func applyPromotions(order *models.Order, promotions []models.Promotion, parents map[uint]*uint, local time.Time) {
+	subtotal := 0.0
+	for i := range order.Items {
+		order.Items[i].Discount = 0
+		order.Items[i].PromotionID = nil
+		subtotal += order.Items[i].Price * float64(order.Items[i].Quantity)
+	}
+	order.Discount = 0
+	order.PromotionID = nil
+
+	var eligible []models.Promotion
+	for _, promotion := range promotions {
+		if promotion.Active && subtotal >= promotion.MinSpend &&
+			scheduleOpen(local, promotion.DaysOfWeek, promotion.StartTime, promotion.EndTime, promotion.StartDate, promotion.EndDate) {
+			eligible = append(eligible, promotion)
+		}
+	}
+	slices.SortFunc(eligible, func(a, b models.Promotion) int {
+		if a.Priority != b.Priority {
+			return b.Priority - a.Priority
+		}
+		return int(a.ID) - int(b.ID)
+	})
+
+	remaining := subtotal
+	for i := range order.Items {
+		item := &order.Items[i]
+		for _, promotion := range eligible {
+			if !isLinePromotion(promotion) || !promotionTargets(promotion, &item.Menu, parents) {
+				continue
+			}
+			if discount := lineDiscount(promotion, item); discount > item.Discount {
+				item.Discount = discount
+				item.PromotionID = &promotion.ID
+			}
+		}
+		remaining -= item.Discount
+	}
+
+	for _, promotion := range eligible {
+		if isLinePromotion(promotion) {
+			continue
+		}
+		if discount := orderDiscount(promotion, remaining); discount > order.Discount {
+			order.Discount = discount
+			order.PromotionID = &promotion.ID
+		}
+	}
+
+	order.TotalPrice = roundMoney(remaining - order.Discount)
+}
+
+func isLinePromotion(promotion models.Promotion) bool {
+	return promotion.MenuID != nil || promotion.CategoryID != nil || promotion.Type == models.PromotionBuyXGetY
+}
+
+// promotionTargets reports whether a line promotion applies to menu. A
+// category promotion covers its subcategories too.
+func promotionTargets(promotion models.Promotion, menu *models.Menu, parents map[uint]*uint) bool {
+	if promotion.MenuID != nil && *promotion.MenuID != menu.ID {
+		return false
+	}
+	if promotion.CategoryID == nil {
+		return true
+	}
+	seen := make(map[uint]bool)
+	for id := &menu.CategoryID; id != nil && *id != 0 && !seen[*id]; id = parents[*id] {
+		if *id == *promotion.CategoryID {
+			return true
+		}
+		seen[*id] = true
+	}
+	return false
+}
+
+// lineDiscount is the discount of a promotion on one line, at most the
+// line total. Fixed amounts are per unit; buy-X-get-Y makes Y units free
+// for every X+Y ordered.
+func lineDiscount(promotion models.Promotion, item *models.OrderMenuItem) float64 {
+	total := item.Price * float64(item.Quantity)
+	var discount float64
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		discount = total * promotion.Value / 100
+	case models.PromotionFixed:
+		discount = promotion.Value * float64(item.Quantity)
+	case models.PromotionBuyXGetY:
+		if group := promotion.BuyQuantity + promotion.GetQuantity; group > 0 {
+			discount = item.Price * float64(item.Quantity/group*promotion.GetQuantity)
+		}
+	}
+	return roundMoney(math.Min(discount, total))
+}
+
+// orderDiscount is the discount of an order promotion on amount.
+func orderDiscount(promotion models.Promotion, amount float64) float64 {
+	var discount float64
+	switch promotion.Type {
+	case models.PromotionPercentage:
+		discount = amount * promotion.Value / 100
+	case models.PromotionFixed:
+		discount = promotion.Value
+	}
+	return roundMoney(math.Max(0, math.Min(discount, amount)))
+}
+
+// roundMoney rounds to cents.
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// GetAllPromotions implements PromotionService.
+func (p *PromotionServiceImpl) GetAllPromotions() ([]dto.PromotionResponse, error) {
+	promotions, err := p.PromotionRepo.GetAllPromotions()
+	if err != nil {
+		return nil, errors.New("failed to get promotions: " + err.Error())
+	}
+	responses := make([]dto.PromotionResponse, 0, len(promotions))
+	for _, promotion := range promotions {
+		responses = append(responses, toPromotionResponse(&promotion))
+	}
+	return responses, nil
+}
+
+// GetPromotionByID implements PromotionService.
+func (p *PromotionServiceImpl) GetPromotionByID(id uint) (dto.PromotionResponse, error) {
+	promotion, err := p.PromotionRepo.GetPromotionByID(id)
+	if err != nil {
+		return dto.PromotionResponse{}, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	return toPromotionResponse(&promotion), nil
+}
+
+// CreatePromotion implements PromotionService.
+func (p *PromotionServiceImpl) CreatePromotion(request dto.PromotionRequest) (dto.PromotionResponse, error) {
+	var promotion models.Promotion
+	if err := fillPromotion(&promotion, request); err != nil {
+		return dto.PromotionResponse{}, err
+	}
+	if err := p.PromotionRepo.CreatePromotion(&promotion); err != nil {
+		return dto.PromotionResponse{}, errors.New("failed to create promotion: " + err.Error())
+	}
+	return toPromotionResponse(&promotion), nil
+}
+
+// UpdatePromotion implements PromotionService.
+// The request replaces every field of the promotion.
+func (p *PromotionServiceImpl) UpdatePromotion(id uint, request dto.PromotionRequest) (dto.PromotionResponse, error) {
+	promotion, err := p.PromotionRepo.GetPromotionByID(id)
+	if err != nil {
+		return dto.PromotionResponse{}, fmt.Errorf("failed to get promotion: %w", err)
+	}
+	if err := fillPromotion(&promotion, request); err != nil {
+		return dto.PromotionResponse{}, err
+	}
+	if err := p.PromotionRepo.UpdatePromotion(&promotion); err != nil {
+		return dto.PromotionResponse{}, errors.New("failed to update promotion: " + err.Error())
+	}
+	return toPromotionResponse(&promotion), nil
+}
+
+// DeletePromotion implements PromotionService.
+// Orders keep the ID of the promotion they were priced with.
+func (p *PromotionServiceImpl) DeletePromotion(id uint) error {
+	if err := p.PromotionRepo.DeletePromotion(id); err != nil {
+		return fmt.Errorf("failed to delete promotion: %w", err)
+	}
+	return nil
+}
+
+// fillPromotion validates request and copies it onto promotion.
+func fillPromotion(promotion *models.Promotion, request dto.PromotionRequest) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidPromotion, fmt.Sprintf(format, args...))
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return invalid("name is required")
+	}
+	if !slices.Contains(promotionTypes, request.Type) {
+		return invalid("unknown type %q, expected any of %s", request.Type, strings.Join(promotionTypes, ", "))
+	}
+	switch request.Type {
+	case models.PromotionPercentage:
+		if request.Value <= 0 || request.Value > 100 {
+			return invalid("a percentage must be above 0 and at most 100")
+		}
+	case models.PromotionFixed:
+		if request.Value <= 0 {
+			return invalid("a fixed discount must be above 0")
+		}
+	case models.PromotionBuyXGetY:
+		if request.BuyQuantity < 1 || request.GetQuantity < 1 {
+			return invalid("buy_quantity and get_quantity must be at least 1")
+		}
+	}
+	if request.MenuID != nil && request.CategoryID != nil {
+		return invalid("menu_id and category_id cannot both be set")
+	}
+	if request.MinSpend < 0 {
+		return invalid("min_spend cannot be negative")
+	}
+	days, err := scheduleDays(request.Days)
+	if err != nil {
+		return invalid("%s", err)
+	}
+	if err := validateSchedule(request.StartTime, request.EndTime, request.StartDate, request.EndDate); err != nil {
+		return invalid("%s", err)
+	}
+
+	promotion.Name = name
+	promotion.Type = request.Type
+	promotion.Value = request.Value
+	promotion.BuyQuantity = request.BuyQuantity
+	promotion.GetQuantity = request.GetQuantity
+	promotion.MenuID = request.MenuID
+	promotion.CategoryID = request.CategoryID
+	promotion.MinSpend = request.MinSpend
+	promotion.DaysOfWeek = days
+	promotion.StartTime = scheduleClock(request.StartTime)
+	promotion.EndTime = scheduleClock(request.EndTime)
+	promotion.StartDate = request.StartDate
+	promotion.EndDate = request.EndDate
+	promotion.Priority = request.Priority
+	promotion.Active = request.Active == nil || *request.Active
+	return nil
+}
+
+func toPromotionResponse(promotion *models.Promotion) dto.PromotionResponse {
+	return dto.PromotionResponse{
+		ID:          promotion.ID,
+		Name:        promotion.Name,
+		Type:        promotion.Type,
+		Value:       promotion.Value,
+		BuyQuantity: promotion.BuyQuantity,
+		GetQuantity: promotion.GetQuantity,
+		MenuID:      promotion.MenuID,
+		CategoryID:  promotion.CategoryID,
+		MinSpend:    promotion.MinSpend,
+		Days:        scheduleDayNames(promotion.DaysOfWeek),
+		StartTime:   promotion.StartTime,
+		EndTime:     promotion.EndTime,
+		StartDate:   promotion.StartDate,
+		EndDate:     promotion.EndDate,
+		Priority:    promotion.Priority,
+		Active:      promotion.Active,
+		CreatedAt:   promotion.CreatedAt,
+		UpdatedAt:   promotion.UpdatedAt,
+	}
+}
+
+func NewPromotionService(promotionRepo repositories.PromotionRepository, categoryRepo repositories.CategoryRepository, location *time.Location) PromotionService {
+	return &PromotionServiceImpl{
+		PromotionRepo: promotionRepo,
+		CategoryRepo:  categoryRepo,
+		Location:      location,
+	}
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"testing"
+	"time"
+)
+
+func TestApplyPromotionsAcrossTen(t *testing.T) {
+	// An 8:00-10:30 breakfast promotion, stored before times were padded.
+	promotion := models.Promotion{
+		Type:       models.PromotionPercentage,
+		Value:      10,
+		DaysOfWeek: models.AllDays,
+		StartTime:  "8:00",
+		EndTime:    "10:30",
+		Active:     true,
+	}
+	promotion.ID = 1
+
+	tests := []struct {
+		clock string
+		want  float64
+	}{
+		{"07:59", 0},
+		{"08:00", 2000},
+		{"09:59", 2000},
+		{"10:00", 2000},
+		{"10:29", 2000},
+		{"10:30", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.clock, func(t *testing.T) {
+			local, err := time.Parse("2006-01-02 15:04", "2026-10-16 "+tt.clock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			order := &models.Order{Items: []models.OrderMenuItem{{Price: 20000, Quantity: 1}}}
+			applyPromotions(order, []models.Promotion{promotion}, nil, local)
+			if order.Discount != tt.want {
+				t.Errorf("discount at %s = %v, want %v", tt.clock, order.Discount, tt.want)
+			}
+		})
+	}
+}
+
+func TestFillPromotionPadsTimes(t *testing.T) {
+	var promotion models.Promotion
+	err := fillPromotion(&promotion, dto.PromotionRequest{
+		Name:      "Happy hour",
+		Type:      models.PromotionFixed,
+		Value:     5000,
+		StartTime: "9:00",
+		EndTime:   "10:00",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if promotion.StartTime != "09:00" || promotion.EndTime != "10:00" {
+		t.Errorf("times = %q-%q, want 09:00-10:00", promotion.StartTime, promotion.EndTime)
+	}
+}
@@ -0,0 +1,397 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/payments"
+	"coffee_shop/repositories"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidRefund marks a cancellation or refund request that fails
+// validation.
+var ErrInvalidRefund = errors.New("invalid refund request")
+
+// ErrNotCancelable is returned when the one canceling may not cancel the
+// order in its current status.
+var ErrNotCancelable = errors.New("order cannot be canceled")
+
+// ErrRefundFailed is returned when a credit note was issued but the
+// payment layer could not pay the refund out. The note stays on record
+// with refund status failed.
+var ErrRefundFailed = errors.New("refund failed")
+
+type RefundService interface {
+	CancelOrder(userID uint, role string, id uint, request dto.CancelOrderRequest) (dto.CreditNoteResponse, error)
+	RefundOrder(staffID uint, id uint, request dto.RefundOrderRequest) (dto.CreditNoteResponse, error)
+	GetCreditNotes(id uint) ([]dto.CreditNoteResponse, error)
+}
+
+type RefundServiceImpl struct {
+	OrderRepo repositories.OrderRepository
+	ShiftRepo repositories.ShiftRepository
+	Loyalty   LoyaltyService
+	Payments  payments.Gateway
+}
+
+// cancelableStatuses are the statuses each role may cancel an order in:
+// customers only while it is pending, staff until it is handed over.
+var cancelableStatuses = map[string][]string{
+	"customer": {models.OrderStatusPending},
+	"cashier":  {models.OrderStatusPending, models.OrderStatusPreparing},
+	"admin":    {models.OrderStatusPending, models.OrderStatusPreparing},
+}
+
+// CancelOrder implements RefundService.
+// Customers can only cancel their own orders. The whole order is credited
+// back: its voucher is released and the points spent on its reward are
+// restored. An order that was not paid at the till is credited nothing,
+// there is no money to give back.
+func (r *RefundServiceImpl) CancelOrder(userID uint, role string, id uint, request dto.CancelOrderRequest) (dto.CreditNoteResponse, error) {
+	order, err := r.OrderRepo.GetOrderByID(id)
+	if err != nil {
+		return dto.CreditNoteResponse{}, fmt.Errorf("failed to get order: %w", err)
+	}
+	reason, method, err := refundDetails(request.Reason, request.Note, request.RefundMethod, paidWith(&order))
+	if err != nil {
+		return dto.CreditNoteResponse{}, err
+	}
+	if role == "customer" && order.UserID != userID {
+		return dto.CreditNoteResponse{}, fmt.Errorf("failed to get order: %w", gorm.ErrRecordNotFound)
+	}
+	statuses := cancelableStatuses[role]
+	if !slices.Contains(statuses, order.Status) {
+		return dto.CreditNoteResponse{}, fmt.Errorf("%w: order is %s", ErrNotCancelable, order.Status)
+	}
+
+	quantities := make(map[uint]int, len(order.Items))
+	for _, item := range order.Items {
+		quantities[item.ID] = item.Quantity
+	}
+	note := &models.CreditNote{
+		OrderID:      order.ID,
+		Type:         models.CreditNoteCancellation,
+		Reason:       reason,
+		Note:         strings.TrimSpace(request.Note),
+		RefundMethod: method,
+		RefundStatus: models.RefundPending,
+		IssuedBy:     &userID,
+	}
+	creditItems(note, &order, quantities, nil)
+	if len(order.Payments) == 0 {
+		creditNothing(note)
+	}
+	if role != "customer" {
+		if note.ShiftID, err = r.openShift(userID, note); err != nil {
+			return dto.CreditNoteResponse{}, err
+		}
+	}
+
+	refund := repositories.OrderRefund{
+		Note:     note,
+		Full:     true,
+		Restored: r.Loyalty.RestoredPoints(&order, time.Now()),
+	}
+	err = r.OrderRepo.CancelOrder(&order, statuses, refund)
+	if errors.Is(err, repositories.ErrOrderNotOpen) {
+		return dto.CreditNoteResponse{}, fmt.Errorf("%w: %s", ErrNotCancelable, err.Error())
+	}
+	if errors.Is(err, repositories.ErrShiftNotOpen) {
+		return dto.CreditNoteResponse{}, err
+	}
+	if err != nil {
+		return dto.CreditNoteResponse{}, errors.New("failed to cancel order: " + err.Error())
+	}
+	if len(order.Payments) == 0 {
+		return dto.ToCreditNoteResponse(note), nil
+	}
+	return r.payOut(note, &order)
+}
+
+// RefundOrder implements RefundService.
+// Lines of a completed order are refunded in full or in part. The
+// earned points are taken back in proportion, and once nothing is left to
+// refund the voucher is released and the points spent on the reward are
+// restored, like on a cancellation.
+func (r *RefundServiceImpl) RefundOrder(staffID uint, id uint, request dto.RefundOrderRequest) (dto.CreditNoteResponse, error) {
+	order, err := r.OrderRepo.GetOrderByID(id)
+	if err != nil {
+		return dto.CreditNoteResponse{}, fmt.Errorf("failed to get order: %w", err)
+	}
+	reason, method, err := refundDetails(request.Reason, request.Note, request.RefundMethod, paidWith(&order))
+	if err != nil {
+		return dto.CreditNoteResponse{}, err
+	}
+	if order.Status != models.OrderStatusCompleted {
+		return dto.CreditNoteResponse{}, fmt.Errorf("%w: order is %s", repositories.ErrOrderNotCompleted, order.Status)
+	}
+	previous, err := r.OrderRepo.GetCreditNotes(order.ID)
+	if err != nil {
+		return dto.CreditNoteResponse{}, errors.New("failed to get credit notes: " + err.Error())
+	}
+	refunded := map[uint]int{}
+	for _, note := range previous {
+		for _, item := range note.Items {
+			refunded[item.OrderMenuItemID] += item.Quantity
+		}
+	}
+
+	quantities := map[uint]int{}
+	if len(request.Items) == 0 {
+		for _, item := range order.Items {
+			if left := item.Quantity - refunded[item.ID]; left > 0 {
+				quantities[item.ID] = left
+			}
+		}
+		if len(quantities) == 0 {
+			return dto.CreditNoteResponse{}, fmt.Errorf("%w: the order was already refunded in full", ErrInvalidRefund)
+		}
+	}
+	for i, requested := range request.Items {
+		index := slices.IndexFunc(order.Items, func(item models.OrderMenuItem) bool { return item.ID == requested.ItemID })
+		switch {
+		case index < 0:
+			return dto.CreditNoteResponse{}, fmt.Errorf("%w: item %d: line %d is not on the order", ErrInvalidRefund, i+1, requested.ItemID)
+		case requested.Quantity < 1:
+			return dto.CreditNoteResponse{}, fmt.Errorf("%w: item %d: quantity must be at least 1", ErrInvalidRefund, i+1)
+		}
+		quantities[requested.ItemID] += requested.Quantity
+		if left := order.Items[index].Quantity - refunded[requested.ItemID]; quantities[requested.ItemID] > left {
+			return dto.CreditNoteResponse{}, fmt.Errorf("%w: item %d: only %d left to refund", ErrInvalidRefund, i+1, left)
+		}
+	}
+
+	note := &models.CreditNote{
+		OrderID:      order.ID,
+		Type:         models.CreditNoteRefund,
+		Reason:       reason,
+		Note:         strings.TrimSpace(request.Note),
+		RefundMethod: method,
+		RefundStatus: models.RefundPending,
+		IssuedBy:     &staffID,
+	}
+	full := creditItems(note, &order, quantities, previous)
+	if note.ShiftID, err = r.openShift(staffID, note); err != nil {
+		return dto.CreditNoteResponse{}, err
+	}
+
+	total := note.Amount
+	for _, previousNote := range previous {
+		total += previousNote.Amount
+	}
+	refund := repositories.OrderRefund{
+		Note:     note,
+		Refunded: refunded,
+		Full:     full,
+		Reverse: func(entries []models.LoyaltyEntry) *models.LoyaltyEntry {
+			return r.Loyalty.ReversedPoints(&order, entries, total, full)
+		},
+	}
+	if full {
+		refund.Restored = r.Loyalty.RestoredPoints(&order, time.Now())
+	}
+	err = r.OrderRepo.RefundOrder(&order, refund)
+	if errors.Is(err, repositories.ErrOrderNotCompleted) || errors.Is(err, repositories.ErrRefundConflict) || errors.Is(err, repositories.ErrShiftNotOpen) {
+		return dto.CreditNoteResponse{}, err
+	}
+	if err != nil {
+		return dto.CreditNoteResponse{}, errors.New("failed to refund order: " + err.Error())
+	}
+	return r.payOut(note, &order)
+}
+
+// GetCreditNotes implements RefundService.
+func (r *RefundServiceImpl) GetCreditNotes(id uint) ([]dto.CreditNoteResponse, error) {
+	if _, err := r.OrderRepo.GetOrderByID(id); err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+	notes, err := r.OrderRepo.GetCreditNotes(id)
+	if err != nil {
+		return nil, errors.New("failed to get credit notes: " + err.Error())
+	}
+	response := make([]dto.CreditNoteResponse, 0, len(notes))
+	for _, note := range notes {
+		response = append(response, dto.ToCreditNoteResponse(&note))
+	}
+	return response, nil
+}
+
+// payOut sends the refund of an issued credit note through the payment
+// layer and records the outcome on the note.
+func (r *RefundServiceImpl) payOut(note *models.CreditNote, order *models.Order) (dto.CreditNoteResponse, error) {
+	if note.Amount <= 0 {
+		note.RefundStatus = models.RefundRefunded
+	} else {
+		reference, err := r.Payments.Refund(context.Background(), payments.Refund{
+			Reference: dto.CreditNoteNumber(note.ID),
+			OrderID:   order.ID,
+			Method:    note.RefundMethod,
+			Amount:    note.Amount,
+		})
+		if err != nil {
+			note.RefundStatus = models.RefundFailed
+			if updateErr := r.OrderRepo.UpdateRefundStatus(note); updateErr != nil {
+				log.Default().Println("Failed to record failed refund: " + updateErr.Error())
+			}
+			return dto.CreditNoteResponse{}, fmt.Errorf("%w: credit note %s: %s", ErrRefundFailed, dto.CreditNoteNumber(note.ID), err.Error())
+		}
+		note.RefundStatus = models.RefundRefunded
+		note.RefundReference = reference
+	}
+
+	if err := r.OrderRepo.UpdateRefundStatus(note); err != nil {
+		return dto.CreditNoteResponse{}, errors.New("failed to record refund: " + err.Error())
+	}
+	return dto.ToCreditNoteResponse(note), nil
+}
+
+// creditItems fills in the lines and amounts of a credit note giving back
+// quantities of the lines of order. Each line is credited its share of the
+// grand total, and of the revenue, in proportion to its value after line
+// discounts, so order discounts, tax and service charge are given back in
+// the same measure. The note that gives back the rest of the order gets
+// exactly what is left, so rounding never leaves a cent behind. It
+// reports whether the note is that last one.
+func creditItems(note *models.CreditNote, order *models.Order, quantities map[uint]int, previous []models.CreditNote) bool {
+	value, refunded := 0.0, map[uint]int{}
+	for _, item := range order.Items {
+		value += item.Price*float64(item.Quantity) - item.Discount
+	}
+	for _, previousNote := range previous {
+		for _, item := range previousNote.Items {
+			refunded[item.OrderMenuItemID] += item.Quantity
+		}
+	}
+
+	full := true
+	for _, item := range order.Items {
+		quantity := quantities[item.ID]
+		if refunded[item.ID]+quantity < item.Quantity {
+			full = false
+		}
+		if quantity == 0 {
+			continue
+		}
+		credited := models.CreditNoteItem{
+			OrderMenuItemID: item.ID,
+			MenuID:          &item.MenuID,
+			Quantity:        quantity,
+		}
+		if value > 0 {
+			share := (item.Price*float64(item.Quantity) - item.Discount) * float64(quantity) / float64(item.Quantity) / value
+			credited.Amount = roundMoney(order.GrandTotal * share)
+			credited.Revenue = roundMoney(order.TotalPrice * share)
+		}
+		note.Items = append(note.Items, credited)
+		note.Amount += credited.Amount
+		note.Revenue += credited.Revenue
+	}
+
+	if full && len(note.Items) > 0 {
+		amount, revenue := order.GrandTotal, order.TotalPrice
+		for _, previousNote := range previous {
+			amount -= previousNote.Amount
+			revenue -= previousNote.Revenue
+		}
+		last := &note.Items[len(note.Items)-1]
+		last.Amount = roundMoney(last.Amount + amount - note.Amount)
+		last.Revenue = roundMoney(last.Revenue + revenue - note.Revenue)
+		note.Amount, note.Revenue = amount, revenue
+	}
+	note.Amount = roundMoney(note.Amount)
+	note.Revenue = roundMoney(note.Revenue)
+	return full
+}
+
+// refundDetails checks the reason code and refund method of a request,
+// the method is fallback when omitted, and required when there is no
+// fallback. The note is required with the reason other.
+func refundDetails(reason, note, method, fallback string) (string, string, error) {
+	switch reason {
+	case models.ReasonCustomerRequest, models.ReasonWrongOrder, models.ReasonQualityIssue,
+		models.ReasonOutOfStock, models.ReasonDuplicateOrder:
+	case models.ReasonOther:
+		if strings.TrimSpace(note) == "" {
+			return "", "", fmt.Errorf("%w: a note is required with reason %s", ErrInvalidRefund, models.ReasonOther)
+		}
+	default:
+		return "", "", fmt.Errorf("%w: reason must be one of %s, %s, %s, %s, %s or %s", ErrInvalidRefund,
+			models.ReasonCustomerRequest, models.ReasonWrongOrder, models.ReasonQualityIssue,
+			models.ReasonOutOfStock, models.ReasonDuplicateOrder, models.ReasonOther)
+	}
+
+	switch method {
+	case "":
+		if fallback == "" {
+			return "", "", fmt.Errorf("%w: refund_method is required for an order paid with more than one method", ErrInvalidRefund)
+		}
+		method = fallback
+	case models.PaymentCash, models.PaymentCard, models.PaymentEWallet:
+	default:
+		return "", "", fmt.Errorf("%w: refund_method must be %s, %s or %s", ErrInvalidRefund, models.PaymentCash, models.PaymentCard, models.PaymentEWallet)
+	}
+	return reason, method, nil
+}
+
+// creditNothing zeroes a note voiding the lines of an order that was never
+// paid: there is nothing to give back, so it is refunded as issued.
+func creditNothing(note *models.CreditNote) {
+	for i := range note.Items {
+		note.Items[i].Amount = 0
+		note.Items[i].Revenue = 0
+	}
+	note.Amount = 0
+	note.Revenue = 0
+	note.RefundStatus = models.RefundRefunded
+}
+
+// paidWith is the method order was paid with at the till, cash when it
+// was not paid there. A split across methods has none: the refund could
+// not be told apart from one method, so it is left to the request.
+func paidWith(order *models.Order) string {
+	if len(order.Payments) == 0 {
+		return models.PaymentCash
+	}
+	method := order.Payments[0].Method
+	for _, payment := range order.Payments[1:] {
+		if payment.Method != method {
+			return ""
+		}
+	}
+	return method
+}
+
+// openShift returns the shift staffID has open, if any, for the credit
+// notes they issue to be counted in it. Cash is paid back from the drawer
+// of a shift, so a note paying cash back without one fails with
+// ErrNoOpenShift.
+func (r *RefundServiceImpl) openShift(staffID uint, note *models.CreditNote) (*uint, error) {
+	shift, err := r.ShiftRepo.GetOpenShift(staffID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if note.RefundMethod == models.PaymentCash && note.Amount > 0 {
+			return nil, fmt.Errorf("%w: open a shift to refund cash, or refund by %s or %s", ErrNoOpenShift, models.PaymentCard, models.PaymentEWallet)
+		}
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to get shift: " + err.Error())
+	}
+	return &shift.ID, nil
+}
+
+func NewRefundService(orderRepo repositories.OrderRepository, shiftRepo repositories.ShiftRepository, loyalty LoyaltyService, gateway payments.Gateway) RefundService {
+	return &RefundServiceImpl{
+		OrderRepo: orderRepo,
+		ShiftRepo: shiftRepo,
+		Loyalty:   loyalty,
+		Payments:  gateway,
+	}
+}
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/payments"
+	"coffee_shop/repositories"
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func orderItem(id uint, price float64, quantity int, discount float64) models.OrderMenuItem {
+	return models.OrderMenuItem{Model: gorm.Model{ID: id}, Price: price, Quantity: quantity, Discount: discount}
+}
+
+// previousNote is a credit note already issued for quantities of lines.
+func previousNote(amount, revenue float64, quantities map[uint]int) models.CreditNote {
+	note := models.CreditNote{Amount: amount, Revenue: revenue}
+	for id, quantity := range quantities {
+		note.Items = append(note.Items, models.CreditNoteItem{OrderMenuItemID: id, Quantity: quantity})
+	}
+	return note
+}
+
+func TestCreditItems(t *testing.T) {
+	// Line 2 has a 1000 line discount; tax and service charge bring the
+	// 24000 of revenue to a grand total of 26400.
+	order := &models.Order{
+		Items:      []models.OrderMenuItem{orderItem(1, 10000, 2, 0), orderItem(2, 5000, 1, 1000)},
+		TotalPrice: 24000,
+		GrandTotal: 26400,
+	}
+	thirds := &models.Order{
+		Items:      []models.OrderMenuItem{orderItem(1, 10, 1, 0), orderItem(2, 10, 1, 0), orderItem(3, 10, 1, 0)},
+		TotalPrice: 30,
+		GrandTotal: 100,
+	}
+	free := &models.Order{Items: []models.OrderMenuItem{orderItem(1, 0, 1, 0)}}
+
+	tests := []struct {
+		name        string
+		order       *models.Order
+		quantities  map[uint]int
+		previous    []models.CreditNote
+		wantAmounts []float64
+		wantAmount  float64
+		wantRevenue float64
+		wantFull    bool
+	}{
+		{
+			name:        "one unit",
+			order:       order,
+			quantities:  map[uint]int{1: 1},
+			wantAmounts: []float64{11000},
+			wantAmount:  11000,
+			wantRevenue: 10000,
+		},
+		{
+			name:        "discounted line",
+			order:       order,
+			quantities:  map[uint]int{2: 1},
+			wantAmounts: []float64{4400},
+			wantAmount:  4400,
+			wantRevenue: 4000,
+		},
+		{
+			name:        "whole order",
+			order:       order,
+			quantities:  map[uint]int{1: 2, 2: 1},
+			wantAmounts: []float64{22000, 4400},
+			wantAmount:  26400,
+			wantRevenue: 24000,
+			wantFull:    true,
+		},
+		{
+			name:        "rest of the order",
+			order:       order,
+			quantities:  map[uint]int{1: 1, 2: 1},
+			previous:    []models.CreditNote{previousNote(11000, 10000, map[uint]int{1: 1})},
+			wantAmounts: []float64{11000, 4400},
+			wantAmount:  15400,
+			wantRevenue: 14000,
+			wantFull:    true,
+		},
+		{
+			name:        "first third",
+			order:       thirds,
+			quantities:  map[uint]int{1: 1},
+			wantAmounts: []float64{33.33},
+			wantAmount:  33.33,
+			wantRevenue: 10,
+		},
+		{
+			name:       "last third takes the rounding",
+			order:      thirds,
+			quantities: map[uint]int{3: 1},
+			previous: []models.CreditNote{
+				previousNote(33.33, 10, map[uint]int{1: 1}),
+				previousNote(33.33, 10, map[uint]int{2: 1}),
+			},
+			wantAmounts: []float64{33.34},
+			wantAmount:  33.34,
+			wantRevenue: 10,
+			wantFull:    true,
+		},
+		{
+			name:        "free order",
+			order:       free,
+			quantities:  map[uint]int{1: 1},
+			wantAmounts: []float64{0},
+			wantFull:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := &models.CreditNote{}
+			full := creditItems(note, tt.order, tt.quantities, tt.previous)
+			if full != tt.wantFull {
+				t.Errorf("full = %v, want %v", full, tt.wantFull)
+			}
+			if note.Amount != tt.wantAmount || note.Revenue != tt.wantRevenue {
+				t.Errorf("amount, revenue = %v, %v, want %v, %v", note.Amount, note.Revenue, tt.wantAmount, tt.wantRevenue)
+			}
+			if len(note.Items) != len(tt.wantAmounts) {
+				t.Fatalf("got %d lines, want %d", len(note.Items), len(tt.wantAmounts))
+			}
+			for i, item := range note.Items {
+				if item.Amount != tt.wantAmounts[i] {
+					t.Errorf("line %d amount = %v, want %v", item.OrderMenuItemID, item.Amount, tt.wantAmounts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRefundMethodDefaultsToPaidWith(t *testing.T) {
+	tests := []struct {
+		name     string
+		payments []models.OrderPayment
+		method   string
+		want     string
+		wantErr  bool
+	}{
+		{name: "not paid at the till", want: models.PaymentCash},
+		{name: "card", payments: []models.OrderPayment{{Method: models.PaymentCard, Amount: 100}}, want: models.PaymentCard},
+		{name: "split in one method", payments: []models.OrderPayment{{Method: models.PaymentCash, Amount: 40}, {Method: models.PaymentCash, Amount: 60}}, want: models.PaymentCash},
+		{name: "split across methods", payments: []models.OrderPayment{{Method: models.PaymentCash, Amount: 40}, {Method: models.PaymentEWallet, Amount: 60}}, wantErr: true},
+		{name: "split asked for", payments: []models.OrderPayment{{Method: models.PaymentCash, Amount: 40}, {Method: models.PaymentEWallet, Amount: 60}}, method: models.PaymentCard, want: models.PaymentCard},
+		{name: "asked for", payments: []models.OrderPayment{{Method: models.PaymentCard, Amount: 100}}, method: models.PaymentCash, want: models.PaymentCash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := &models.Order{Payments: tt.payments}
+			_, method, err := refundDetails(models.ReasonCustomerRequest, "", tt.method, paidWith(order))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidRefund) {
+					t.Errorf("err = %v, want ErrInvalidRefund", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method != tt.want {
+				t.Errorf("method = %s, want %s", method, tt.want)
+			}
+		})
+	}
+}
+
+// cancelledOrders is an OrderRepository holding one order, keeping the
+// credit notes it is asked to issue.
+type cancelledOrders struct {
+	repositories.OrderRepository
+	order  models.Order
+	issued []models.CreditNote
+}
+
+func (c *cancelledOrders) GetOrderByID(id uint) (models.Order, error) {
+	if id != c.order.ID {
+		return models.Order{}, gorm.ErrRecordNotFound
+	}
+	return c.order, nil
+}
+
+func (c *cancelledOrders) CancelOrder(order *models.Order, statuses []string, refund repositories.OrderRefund) error {
+	c.issued = append(c.issued, *refund.Note)
+	return nil
+}
+
+func (c *cancelledOrders) UpdateRefundStatus(note *models.CreditNote) error {
+	return nil
+}
+
+// countingGateway is a payments.Gateway counting the refunds sent to it.
+type countingGateway struct {
+	refunds []payments.Refund
+}
+
+func (g *countingGateway) Refund(ctx context.Context, refund payments.Refund) (string, error) {
+	g.refunds = append(g.refunds, refund)
+	return refund.Reference, nil
+}
+
+func TestCancelUnpaidOrder(t *testing.T) {
+	order := models.Order{
+		Model:      gorm.Model{ID: 7},
+		UserID:     5,
+		Status:     models.OrderStatusPending,
+		Items:      []models.OrderMenuItem{orderItem(1, 10000, 2, 0)},
+		TotalPrice: 20000,
+		GrandTotal: 22000,
+	}
+	paid := order
+	paid.Payments = []models.OrderPayment{{Method: models.PaymentCard, Amount: 22000}}
+
+	tests := []struct {
+		name        string
+		order       models.Order
+		wantAmount  float64
+		wantRefunds int
+	}{
+		{name: "unpaid", order: order},
+		{name: "paid at the till", order: paid, wantAmount: 22000, wantRefunds: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orders := &cancelledOrders{order: tt.order}
+			gateway := &countingGateway{}
+			refunds := &RefundServiceImpl{
+				OrderRepo: orders,
+				// Cashier 3 has no shift open.
+				ShiftRepo: &lockedShift{shift: models.Shift{Model: gorm.Model{ID: 4}, CashierID: 2, Status: models.ShiftOpen}},
+				Loyalty:   &LoyaltyServiceImpl{},
+				Payments:  gateway,
+			}
+			response, err := refunds.CancelOrder(3, "cashier", 7, dto.CancelOrderRequest{Reason: models.ReasonCustomerRequest})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(orders.issued) != 1 {
+				t.Fatalf("issued %d notes, want 1", len(orders.issued))
+			}
+			note := orders.issued[0]
+			if note.Amount != tt.wantAmount || response.Amount != tt.wantAmount {
+				t.Errorf("amount = %v, response %v, want %v", note.Amount, response.Amount, tt.wantAmount)
+			}
+			for _, item := range note.Items {
+				if tt.wantAmount == 0 && (item.Amount != 0 || item.Revenue != 0) {
+					t.Errorf("line %d amount, revenue = %v, %v, want 0", item.OrderMenuItemID, item.Amount, item.Revenue)
+				}
+			}
+			if len(gateway.refunds) != tt.wantRefunds {
+				t.Errorf("gateway called %d times, want %d", len(gateway.refunds), tt.wantRefunds)
+			}
+			if response.RefundStatus != models.RefundRefunded {
+				t.Errorf("refund status = %s, want %s", response.RefundStatus, models.RefundRefunded)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"sort"
+	"time"
+)
+
+type ReportService interface {
+	DailyReport(day time.Time) (dto.DailyReportResponse, error)
+}
+
+type ReportServiceImpl struct {
+	OrderRepo repositories.OrderRepository
+}
+
+// DailyReport implements ReportService.
+// Revenue only counts completed orders, the status breakdown counts all of them.
+// Refunds issued on the day are netted out of the revenue and the top items
+// on the day they are made, whenever the order was; cancellations are not,
+// canceled orders never counted as revenue.
+func (r *ReportServiceImpl) DailyReport(day time.Time) (dto.DailyReportResponse, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	orders, err := r.OrderRepo.GetOrdersBetween(start, end)
+	if err != nil {
+		return dto.DailyReportResponse{}, errors.New("failed to get orders: " + err.Error())
+	}
+
+	report := dto.DailyReportResponse{
+		Date:           start.Format(time.DateOnly),
+		TotalOrders:    len(orders),
+		OrdersByStatus: map[string]int{},
+		TopItems:       []dto.DailyReportItem{},
+	}
+
+	items := map[uint]*dto.DailyReportItem{}
+	completed := 0
+	for _, order := range orders {
+		report.OrdersByStatus[order.Status]++
+		if order.Status != "completed" {
+			continue
+		}
+		completed++
+		report.Revenue += order.TotalPrice
+
+		for _, item := range order.Items {
+			summary, ok := items[item.MenuID]
+			if !ok {
+				summary = &dto.DailyReportItem{MenuID: item.MenuID, MenuName: item.Menu.MenuName}
+				items[item.MenuID] = summary
+			}
+			summary.Quantity += item.Quantity
+			summary.Revenue += item.Price*float64(item.Quantity) - item.Discount
+		}
+	}
+	if completed > 0 {
+		report.AverageOrder = report.Revenue / float64(completed)
+	}
+
+	notes, err := r.OrderRepo.GetCreditNotesBetween(start, end)
+	if err != nil {
+		return dto.DailyReportResponse{}, errors.New("failed to get credit notes: " + err.Error())
+	}
+	for _, note := range notes {
+		if note.Type != models.CreditNoteRefund {
+			continue
+		}
+		report.Refunds += note.Revenue
+		for _, item := range note.Items {
+			if item.MenuID == nil {
+				continue
+			}
+			summary, ok := items[*item.MenuID]
+			if !ok {
+				summary = &dto.DailyReportItem{MenuID: *item.MenuID, MenuName: item.Menu.MenuName}
+				items[*item.MenuID] = summary
+			}
+			summary.Quantity -= item.Quantity
+			summary.Revenue -= item.Revenue
+		}
+	}
+	report.NetRevenue = report.Revenue - report.Refunds
+
+	for _, item := range items {
+		report.TopItems = append(report.TopItems, *item)
+	}
+	sort.Slice(report.TopItems, func(i, j int) bool {
+		if report.TopItems[i].Quantity != report.TopItems[j].Quantity {
+			return report.TopItems[i].Quantity > report.TopItems[j].Quantity
+		}
+		return report.TopItems[i].MenuID < report.TopItems[j].MenuID
+	})
+	return report, nil
+}
+
+func NewReportService(orderRepo repositories.OrderRepository) ReportService {
+	return &ReportServiceImpl{
+		OrderRepo: orderRepo,
+	}
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Availability windows and promotions share one schedule format: a
+// DaysOfWeek bit mask indexed by time.Weekday, "HH:MM" start and end times
+// and inclusive "YYYY-MM-DD" start and end dates. Empty values are open
+// ended, and an end time before the start time runs past midnight.
+
+// scheduleOpen reports whether a schedule is open at local, a wall clock
+// time in the schedule's timezone. The days of a schedule that runs past
+// midnight are the days it starts on: a Friday 22:00-02:00 schedule is
+// open early on Saturday.
+func scheduleOpen(local time.Time, days int, startTime, endTime, startDate, endDate string) bool {
+	date := local.Format("2006-01-02")
+	if (startDate != "" && date < startDate) || (endDate != "" && date > endDate) {
+		return false
+	}
+
+	start, end := 0, 24*60
+	if startTime != "" {
+		start = clockMinutes(startTime)
+	}
+	if endTime != "" {
+		end = clockMinutes(endTime)
+	}
+	clock := local.Hour()*60 + local.Minute()
+	today := hasDay(days, local.Weekday())
+	if start <= end {
+		return today && start <= clock && clock < end
+	}
+	yesterday := hasDay(days, local.AddDate(0, 0, -1).Weekday())
+	return (today && clock >= start) || (yesterday && clock < end)
+}
+
+// clockMinutes returns the minutes since midnight of a valid "HH:MM"
+// time. Times are compared as numbers because an unpadded "9:00" sorts
+// after "10:00" as a string.
+func clockMinutes(clock string) int {
+	parsed, _ := time.Parse("15:04", clock)
+	return parsed.Hour()*60 + parsed.Minute()
+}
+
+// scheduleClock returns a valid time in the zero padded "HH:MM" form that
+// is stored and returned, keeping empty times empty.
+func scheduleClock(clock string) string {
+	if clock == "" {
+		return ""
+	}
+	parsed, _ := time.Parse("15:04", clock)
+	return parsed.Format("15:04")
+}
+
+func hasDay(mask int, day time.Weekday) bool {
+	return mask&(1<<day) != 0
+}
+
+// scheduleDays converts day names to a DaysOfWeek mask, no names means
+// every day.
+func scheduleDays(names []string) (int, error) {
+	if len(names) == 0 {
+		return models.AllDays, nil
+	}
+	days := 0
+	for _, name := range names {
+		day := slices.Index(dto.WeekdayNames, strings.ToLower(strings.TrimSpace(name)))
+		if day < 0 {
+			return 0, fmt.Errorf("unknown day %q, expected any of %s", name, strings.Join(dto.WeekdayNames, ", "))
+		}
+		days |= 1 << day
+	}
+	return days, nil
+}
+
+// scheduleDayNames is the inverse of scheduleDays.
+func scheduleDayNames(days int) []string {
+	var names []string
+	for day, name := range dto.WeekdayNames {
+		if hasDay(days, time.Weekday(day)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// validateSchedule checks the time and date formats of a schedule.
+func validateSchedule(startTime, endTime, startDate, endDate string) error {
+	for _, clock := range []string{startTime, endTime} {
+		if _, err := time.Parse("15:04", clock); clock != "" && err != nil {
+			return fmt.Errorf("time %q is not HH:MM", clock)
+		}
+	}
+	if startTime != "" && endTime != "" && clockMinutes(startTime) == clockMinutes(endTime) {
+		return errors.New("start_time and end_time are equal")
+	}
+	for _, date := range []string{startDate, endDate} {
+		if _, err := time.Parse("2006-01-02", date); date != "" && err != nil {
+			return fmt.Errorf("date %q is not YYYY-MM-DD", date)
+		}
+	}
+	if startDate != "" && endDate != "" && endDate < startDate {
+		return errors.New("end_date is before start_date")
+	}
+	return nil
+}
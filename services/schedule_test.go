@@ -0,0 +1,99 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"testing"
+	"time"
+)
+
+func TestScheduleOpen(t *testing.T) {
+	// 2026-10-16 is a Friday.
+	at := func(clock string) time.Time {
+		local, err := time.Parse("2006-01-02 15:04", "2026-10-16 "+clock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return local
+	}
+	friday := 1 << time.Friday
+
+	tests := []struct {
+		name       string
+		local      time.Time
+		days       int
+		start, end string
+		want       bool
+	}{
+		{name: "unpadded start before window", local: at("08:59"), days: models.AllDays, start: "9:00", end: "17:00", want: false},
+		{name: "unpadded start at start", local: at("09:00"), days: models.AllDays, start: "9:00", end: "17:00", want: true},
+		{name: "unpadded start after ten", local: at("10:30"), days: models.AllDays, start: "9:00", end: "17:00", want: true},
+		{name: "unpadded end", local: at("09:30"), days: models.AllDays, start: "8:00", end: "9:45", want: true},
+		{name: "at end", local: at("17:00"), days: models.AllDays, start: "09:00", end: "17:00", want: false},
+		{name: "open ended", local: at("23:59"), days: models.AllDays, want: true},
+		{name: "other day", local: at("12:00"), days: 1 << time.Monday, want: false},
+		{name: "past midnight late", local: at("23:00"), days: friday, start: "22:00", end: "2:00", want: true},
+		{name: "past midnight early on the start day", local: at("01:00"), days: friday, start: "22:00", end: "2:00", want: false},
+		{name: "past midnight early on the next day", local: at("01:00").AddDate(0, 0, 1), days: friday, start: "22:00", end: "2:00", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scheduleOpen(tt.local, tt.days, tt.start, tt.end, "", ""); got != tt.want {
+				t.Errorf("scheduleOpen(%s, %q-%q) = %v, want %v", tt.local.Format("Mon 15:04"), tt.start, tt.end, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleOpenDates(t *testing.T) {
+	local := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		start, end string
+		want       bool
+	}{
+		{"", "", true},
+		{"2026-10-16", "2026-10-16", true},
+		{"2026-10-17", "", false},
+		{"", "2026-10-15", false},
+	} {
+		if got := scheduleOpen(local, models.AllDays, "", "", tt.start, tt.end); got != tt.want {
+			t.Errorf("scheduleOpen(dates %q-%q) = %v, want %v", tt.start, tt.end, got, tt.want)
+		}
+	}
+}
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		name               string
+		startTime, endTime string
+		startDate, endDate string
+		wantErr            bool
+	}{
+		{name: "padded", startTime: "09:00", endTime: "17:00"},
+		{name: "unpadded", startTime: "9:00", endTime: "17:00"},
+		{name: "open ended"},
+		{name: "bad time", startTime: "9am", wantErr: true},
+		{name: "equal times", startTime: "9:00", endTime: "09:00", wantErr: true},
+		{name: "dates", startDate: "2026-10-01", endDate: "2026-10-31"},
+		{name: "bad date", startDate: "2026-10-1", wantErr: true},
+		{name: "dates reversed", startDate: "2026-10-31", endDate: "2026-10-01", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateSchedule(tt.startTime, tt.endTime, tt.startDate, tt.endDate)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateSchedule() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToAvailabilityWindowsPadsTimes(t *testing.T) {
+	windows, err := toAvailabilityWindows([]dto.AvailabilityWindowRequest{{StartTime: "7:30", EndTime: "11:00"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := windows[0]; got.StartTime != "07:30" || got.EndTime != "11:00" {
+		t.Errorf("times = %q-%q, want 07:30-11:00", got.StartTime, got.EndTime)
+	}
+}
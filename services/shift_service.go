@@ -0,0 +1,251 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidShift marks shift requests that cannot be carried out.
+var ErrInvalidShift = errors.New("invalid shift request")
+
+type ShiftService interface {
+	OpenShift(cashierID uint, request dto.OpenShiftRequest) (dto.ShiftReportResponse, error)
+	GetCurrentShift(cashierID uint) (dto.ShiftReportResponse, error)
+	RecordMovement(cashierID uint, request dto.CashMovementRequest) (dto.ShiftMovementResponse, error)
+	CloseShift(cashierID uint, request dto.CloseShiftRequest) (dto.ShiftReportResponse, error)
+	GetShiftReport(userID uint, role string, id uint) (dto.ShiftReportResponse, error)
+	GetShifts(day time.Time) ([]dto.ShiftReportResponse, error)
+}
+
+type ShiftServiceImpl struct {
+	ShiftRepo repositories.ShiftRepository
+	Config    config.ShiftConfig
+}
+
+// OpenShift implements ShiftService.
+// A cashier has at most one shift open at a time.
+func (s *ShiftServiceImpl) OpenShift(cashierID uint, request dto.OpenShiftRequest) (dto.ShiftReportResponse, error) {
+	if request.OpeningFloat < 0 {
+		return dto.ShiftReportResponse{}, fmt.Errorf("%w: opening float cannot be negative", ErrInvalidShift)
+	}
+
+	shift := models.Shift{
+		CashierID:    cashierID,
+		Status:       models.ShiftOpen,
+		OpeningFloat: roundMoney(request.OpeningFloat),
+		OpenedAt:     time.Now(),
+	}
+	if err := s.ShiftRepo.OpenShift(&shift); err != nil {
+		if errors.Is(err, repositories.ErrShiftAlreadyOpen) {
+			return dto.ShiftReportResponse{}, err
+		}
+		return dto.ShiftReportResponse{}, errors.New("failed to open shift: " + err.Error())
+	}
+	return s.report(&shift)
+}
+
+// GetCurrentShift implements ShiftService.
+// It returns the report so far of the shift the cashier has open.
+func (s *ShiftServiceImpl) GetCurrentShift(cashierID uint) (dto.ShiftReportResponse, error) {
+	shift, err := s.openShift(cashierID)
+	if err != nil {
+		return dto.ShiftReportResponse{}, err
+	}
+	return s.report(&shift)
+}
+
+// RecordMovement implements ShiftService.
+// Paid-outs and drops cannot take more cash out of the drawer than is
+// expected in it, which is checked with the shift locked.
+func (s *ShiftServiceImpl) RecordMovement(cashierID uint, request dto.CashMovementRequest) (dto.ShiftMovementResponse, error) {
+	movement := models.ShiftCashMovement{
+		Type:       request.Type,
+		Amount:     roundMoney(request.Amount),
+		Note:       strings.TrimSpace(request.Note),
+		RecordedBy: &cashierID,
+	}
+	switch {
+	case movement.Type != models.CashIn && movement.Type != models.CashPaidOut && movement.Type != models.CashDrop:
+		return dto.ShiftMovementResponse{}, fmt.Errorf("%w: type must be %s, %s or %s", ErrInvalidShift, models.CashIn, models.CashPaidOut, models.CashDrop)
+	case movement.Amount <= 0:
+		return dto.ShiftMovementResponse{}, fmt.Errorf("%w: amount must be greater than 0", ErrInvalidShift)
+	case movement.Type == models.CashPaidOut && movement.Note == "":
+		return dto.ShiftMovementResponse{}, fmt.Errorf("%w: a paid-out needs a note saying what it paid for", ErrInvalidShift)
+	}
+
+	shift, err := s.openShift(cashierID)
+	if err != nil {
+		return dto.ShiftMovementResponse{}, err
+	}
+	movement.ShiftID = shift.ID
+	var check func(shift *models.Shift, totals repositories.ShiftTotals) error
+	if movement.Type != models.CashIn {
+		check = func(shift *models.Shift, totals repositories.ShiftTotals) error {
+			if expected := expectedCash(shift.OpeningFloat, totals); movement.Amount > expected {
+				return fmt.Errorf("%w: only %.2f is expected in the drawer", ErrInvalidShift, expected)
+			}
+			return nil
+		}
+	}
+
+	if err := s.ShiftRepo.AddMovement(&movement, check); err != nil {
+		if errors.Is(err, repositories.ErrShiftNotOpen) || errors.Is(err, ErrInvalidShift) {
+			return dto.ShiftMovementResponse{}, err
+		}
+		return dto.ShiftMovementResponse{}, errors.New("failed to record cash movement: " + err.Error())
+	}
+	return dto.ToShiftMovementResponse(&movement), nil
+}
+
+// CloseShift implements ShiftService.
+// The counted cash is compared to the cash expected in the drawer and the
+// difference kept on the shift; the Z-report flags it when it is more than
+// Config.DiscrepancyTolerance either way.
+func (s *ShiftServiceImpl) CloseShift(cashierID uint, request dto.CloseShiftRequest) (dto.ShiftReportResponse, error) {
+	switch {
+	case request.CountedCash == nil:
+		return dto.ShiftReportResponse{}, fmt.Errorf("%w: counted cash is required", ErrInvalidShift)
+	case *request.CountedCash < 0:
+		return dto.ShiftReportResponse{}, fmt.Errorf("%w: counted cash cannot be negative", ErrInvalidShift)
+	}
+
+	open, err := s.openShift(cashierID)
+	if err != nil {
+		return dto.ShiftReportResponse{}, err
+	}
+	shift, err := s.ShiftRepo.CloseShift(open.ID, func(shift *models.Shift, totals repositories.ShiftTotals) error {
+		now := time.Now()
+		expected := expectedCash(shift.OpeningFloat, totals)
+		counted := roundMoney(*request.CountedCash)
+		discrepancy := roundMoney(counted - expected)
+		shift.ClosedAt = &now
+		shift.ExpectedCash = &expected
+		shift.CountedCash = &counted
+		shift.Discrepancy = &discrepancy
+		shift.ClosingNote = strings.TrimSpace(request.Note)
+		return nil
+	})
+	if errors.Is(err, repositories.ErrShiftNotOpen) {
+		return dto.ShiftReportResponse{}, err
+	}
+	if err != nil {
+		return dto.ShiftReportResponse{}, errors.New("failed to close shift: " + err.Error())
+	}
+	return s.report(&shift)
+}
+
+// GetShiftReport implements ShiftService.
+// Cashiers only see their own shifts, admins see all of them.
+func (s *ShiftServiceImpl) GetShiftReport(userID uint, role string, id uint) (dto.ShiftReportResponse, error) {
+	shift, err := s.ShiftRepo.GetShiftByID(id)
+	if err != nil {
+		return dto.ShiftReportResponse{}, fmt.Errorf("failed to get shift: %w", err)
+	}
+	if role != "admin" && shift.CashierID != userID {
+		return dto.ShiftReportResponse{}, fmt.Errorf("failed to get shift: %w", gorm.ErrRecordNotFound)
+	}
+	return s.report(&shift)
+}
+
+// GetShifts implements ShiftService.
+// It returns the reports of the shifts opened on day.
+func (s *ShiftServiceImpl) GetShifts(day time.Time) ([]dto.ShiftReportResponse, error) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	end := start.AddDate(0, 0, 1)
+
+	shifts, err := s.ShiftRepo.GetShiftsBetween(start, end)
+	if err != nil {
+		return nil, errors.New("failed to get shifts: " + err.Error())
+	}
+	reports := make([]dto.ShiftReportResponse, 0, len(shifts))
+	for i := range shifts {
+		report, err := s.report(&shifts[i])
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// openShift returns the shift cashierID has open, or ErrNoOpenShift when
+// there is none.
+func (s *ShiftServiceImpl) openShift(cashierID uint) (models.Shift, error) {
+	shift, err := s.ShiftRepo.GetOpenShift(cashierID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.Shift{}, fmt.Errorf("%w: open a shift first", ErrNoOpenShift)
+	}
+	if err != nil {
+		return models.Shift{}, errors.New("failed to get shift: " + err.Error())
+	}
+	return shift, nil
+}
+
+// report builds the report of shift. A closed shift keeps the cash that
+// was expected when it closed, for an open one it is worked out as of now.
+func (s *ShiftServiceImpl) report(shift *models.Shift) (dto.ShiftReportResponse, error) {
+	totals, err := s.ShiftRepo.GetShiftTotals(shift.ID)
+	if err != nil {
+		return dto.ShiftReportResponse{}, errors.New("failed to get shift totals: " + err.Error())
+	}
+
+	report := dto.ShiftReportResponse{
+		ID:           shift.ID,
+		CashierID:    shift.CashierID,
+		Status:       shift.Status,
+		OpenedAt:     shift.OpenedAt,
+		ClosedAt:     shift.ClosedAt,
+		Orders:       totals.Orders,
+		Sales:        totals.Payments,
+		Refunds:      totals.Refunds,
+		OpeningFloat: shift.OpeningFloat,
+		CashSales:    totals.Payments[models.PaymentCash],
+		CashRefunds:  totals.Refunds[models.PaymentCash],
+		CashIn:       totals.Movements[models.CashIn],
+		PaidOut:      totals.Movements[models.CashPaidOut],
+		Drops:        totals.Movements[models.CashDrop],
+		ExpectedCash: expectedCash(shift.OpeningFloat, totals),
+		CountedCash:  shift.CountedCash,
+		Discrepancy:  shift.Discrepancy,
+		ClosingNote:  shift.ClosingNote,
+		Movements:    make([]dto.ShiftMovementResponse, 0, len(shift.Movements)),
+	}
+	if shift.ExpectedCash != nil {
+		report.ExpectedCash = *shift.ExpectedCash
+	}
+	if shift.Discrepancy != nil {
+		report.Flagged = math.Abs(*shift.Discrepancy)-s.Config.DiscrepancyTolerance > 0.005
+	}
+	for i := range shift.Movements {
+		report.Movements = append(report.Movements, dto.ToShiftMovementResponse(&shift.Movements[i]))
+	}
+	return report, nil
+}
+
+// expectedCash is the cash that should be in the drawer: the opening
+// float and the cash taken for orders and put in, less the cash refunded,
+// paid out and dropped.
+func expectedCash(openingFloat float64, totals repositories.ShiftTotals) float64 {
+	return roundMoney(openingFloat +
+		totals.Payments[models.PaymentCash] -
+		totals.Refunds[models.PaymentCash] +
+		totals.Movements[models.CashIn] -
+		totals.Movements[models.CashPaidOut] -
+		totals.Movements[models.CashDrop])
+}
+
+func NewShiftService(shiftRepo repositories.ShiftRepository, cfg config.ShiftConfig) ShiftService {
+	return &ShiftServiceImpl{
+		ShiftRepo: shiftRepo,
+		Config:    cfg,
+	}
+}
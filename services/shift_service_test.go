@@ -0,0 +1,197 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestExpectedCash(t *testing.T) {
+	tests := []struct {
+		name         string
+		openingFloat float64
+		totals       repositories.ShiftTotals
+		want         float64
+	}{
+		{name: "float only", openingFloat: 200000, want: 200000},
+		{
+			name:         "only cash counts",
+			openingFloat: 200000,
+			totals: repositories.ShiftTotals{
+				Payments: map[string]float64{models.PaymentCash: 150000, models.PaymentCard: 90000},
+				Refunds:  map[string]float64{models.PaymentCash: 25000, models.PaymentEWallet: 10000},
+			},
+			want: 325000,
+		},
+		{
+			name:         "movements",
+			openingFloat: 200000,
+			totals: repositories.ShiftTotals{
+				Payments:  map[string]float64{models.PaymentCash: 100000},
+				Movements: map[string]float64{models.CashIn: 50000, models.CashPaidOut: 20000, models.CashDrop: 300000},
+			},
+			want: 30000,
+		},
+		{
+			name:   "rounded to cents",
+			totals: repositories.ShiftTotals{Payments: map[string]float64{models.PaymentCash: 0.1 + 0.2}},
+			want:   0.3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := expectedCash(tt.openingFloat, tt.totals); got != tt.want {
+				t.Errorf("expectedCash() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// lockedShift is a ShiftRepository with one open shift and its totals,
+// handed to the check of AddMovement the way the locked repository does.
+type lockedShift struct {
+	repositories.ShiftRepository
+	shift   models.Shift
+	totals  repositories.ShiftTotals
+	added   []models.ShiftCashMovement
+	checked int
+}
+
+func (l *lockedShift) GetOpenShift(cashierID uint) (models.Shift, error) {
+	if cashierID != l.shift.CashierID {
+		return models.Shift{}, gorm.ErrRecordNotFound
+	}
+	return l.shift, nil
+}
+
+func (l *lockedShift) AddMovement(movement *models.ShiftCashMovement, check func(shift *models.Shift, totals repositories.ShiftTotals) error) error {
+	if check != nil {
+		l.checked++
+		if err := check(&l.shift, l.totals); err != nil {
+			return err
+		}
+	}
+	l.added = append(l.added, *movement)
+	return nil
+}
+
+func TestRecordMovementChecksUnderLock(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     dto.CashMovementRequest
+		wantChecked int
+		wantErr     bool
+	}{
+		{name: "cash in", request: dto.CashMovementRequest{Type: models.CashIn, Amount: 500000}},
+		{name: "drop all", request: dto.CashMovementRequest{Type: models.CashDrop, Amount: 250000}, wantChecked: 1},
+		{name: "drop too much", request: dto.CashMovementRequest{Type: models.CashDrop, Amount: 250000.01}, wantChecked: 1, wantErr: true},
+		{name: "paid out too much", request: dto.CashMovementRequest{Type: models.CashPaidOut, Amount: 300000, Note: "milk"}, wantChecked: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &lockedShift{
+				shift:  models.Shift{Model: gorm.Model{ID: 4}, CashierID: 2, Status: models.ShiftOpen, OpeningFloat: 200000},
+				totals: repositories.ShiftTotals{Payments: map[string]float64{models.PaymentCash: 50000}},
+			}
+			_, err := (&ShiftServiceImpl{ShiftRepo: repo}).RecordMovement(2, tt.request)
+			if repo.checked != tt.wantChecked {
+				t.Errorf("checked %d times, want %d", repo.checked, tt.wantChecked)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidShift) || len(repo.added) != 0 {
+					t.Errorf("err = %v, added %d, want ErrInvalidShift and nothing added", err, len(repo.added))
+				}
+				return
+			}
+			if err != nil || len(repo.added) != 1 || repo.added[0].ShiftID != 4 {
+				t.Errorf("err = %v, added %+v, want one movement on shift 4", err, repo.added)
+			}
+		})
+	}
+}
+
+func TestRefundShift(t *testing.T) {
+	repo := &lockedShift{shift: models.Shift{Model: gorm.Model{ID: 4}, CashierID: 2, Status: models.ShiftOpen}}
+	refunds := &RefundServiceImpl{ShiftRepo: repo}
+
+	tests := []struct {
+		name    string
+		staffID uint
+		method  string
+		amount  float64
+		want    uint // 0 for no shift
+		wantErr bool
+	}{
+		{name: "cash with a shift", staffID: 2, method: models.PaymentCash, amount: 100, want: 4},
+		{name: "card with a shift", staffID: 2, method: models.PaymentCard, amount: 100, want: 4},
+		{name: "card without a shift", staffID: 3, method: models.PaymentCard, amount: 100},
+		{name: "cash without a shift", staffID: 3, method: models.PaymentCash, amount: 100, wantErr: true},
+		{name: "no cash back without a shift", staffID: 3, method: models.PaymentCash},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := &models.CreditNote{RefundMethod: tt.method, Amount: tt.amount}
+			shiftID, err := refunds.openShift(tt.staffID, note)
+			if tt.wantErr {
+				if !errors.Is(err, ErrNoOpenShift) {
+					t.Fatalf("err = %v, want ErrNoOpenShift", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := uint(0)
+			if shiftID != nil {
+				got = *shiftID
+			}
+			if got != tt.want {
+				t.Errorf("shift = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentShiftCashRefunds(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Shift{}, &models.ShiftCashMovement{}, &models.Order{}, &models.OrderPayment{}, &models.CreditNote{}); err != nil {
+		t.Fatal(err)
+	}
+	shiftID := uint(1)
+	rows := []any{
+		&models.Shift{Model: gorm.Model{ID: shiftID}, CashierID: 2, Status: models.ShiftOpen, OpeningFloat: 200000, OpenedAt: time.Now()},
+		// A till order paid 30000 in cash, 10000 of it refunded in cash.
+		&models.Order{Model: gorm.Model{ID: 1}, ShiftID: &shiftID, GrandTotal: 30000, Status: models.OrderStatusCompleted},
+		&models.OrderPayment{OrderID: 1, Method: models.PaymentCash, Amount: 30000},
+		&models.CreditNote{OrderID: 1, Type: models.CreditNoteRefund, Amount: 10000, RefundMethod: models.PaymentCash, RefundStatus: models.RefundRefunded, ShiftID: &shiftID},
+		// An online order cancelled before it was paid.
+		&models.Order{Model: gorm.Model{ID: 2}, GrandTotal: 22000, Status: models.OrderStatusCanceled},
+		&models.CreditNote{OrderID: 2, Type: models.CreditNoteCancellation, RefundMethod: models.PaymentCash, RefundStatus: models.RefundRefunded, ShiftID: &shiftID},
+		// An online order never paid into the drawer, credited in full.
+		&models.Order{Model: gorm.Model{ID: 3}, GrandTotal: 15000, Status: models.OrderStatusCanceled},
+		&models.CreditNote{OrderID: 3, Type: models.CreditNoteCancellation, Amount: 15000, RefundMethod: models.PaymentCash, RefundStatus: models.RefundRefunded, ShiftID: &shiftID},
+	}
+	for _, row := range rows {
+		if err := db.Create(row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	shifts := &ShiftServiceImpl{ShiftRepo: repositories.NewShiftRepository(db)}
+	report, err := shifts.GetCurrentShift(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.CashRefunds != 10000 || report.ExpectedCash != 220000 {
+		t.Errorf("cash refunds, expected cash = %v, %v, want 10000, 220000", report.CashRefunds, report.ExpectedCash)
+	}
+}
@@ -0,0 +1,371 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalidTax marks a tax rate or service charge request that fails
+// validation.
+var ErrInvalidTax = errors.New("invalid tax setting")
+
+type TaxService interface {
+	GetAllTaxRates() ([]dto.TaxRateResponse, error)
+	CreateTaxRate(request dto.TaxRateRequest) (dto.TaxRateResponse, error)
+	UpdateTaxRate(id uint, request dto.TaxRateRequest) (dto.TaxRateResponse, error)
+	DeleteTaxRate(id uint) error
+	GetAllServiceCharges() ([]dto.ServiceChargeResponse, error)
+	CreateServiceCharge(request dto.ServiceChargeRequest) (dto.ServiceChargeResponse, error)
+	UpdateServiceCharge(id uint, request dto.ServiceChargeRequest) (dto.ServiceChargeResponse, error)
+	DeleteServiceCharge(id uint) error
+	ApplyTaxes(order *models.Order) error
+}
+
+type TaxServiceImpl struct {
+	TaxRepo      repositories.TaxRepository
+	CategoryRepo repositories.CategoryRepository
+	Config       config.TaxConfig
+}
+
+// ApplyTaxes implements TaxService.
+// It runs last on a priced order, whose items must carry their Menu.
+func (t *TaxServiceImpl) ApplyTaxes(order *models.Order) error {
+	rates, err := t.TaxRepo.GetAllTaxRates()
+	if err != nil {
+		return errors.New("failed to read tax rates: " + err.Error())
+	}
+	rules, err := t.TaxRepo.GetActiveServiceCharges(order.OrderType)
+	if err != nil {
+		return errors.New("failed to read service charges: " + err.Error())
+	}
+	categories, err := t.CategoryRepo.GetAllCategories()
+	if err != nil {
+		return errors.New("failed to read categories: " + err.Error())
+	}
+
+	applyTaxes(order, rates, categories, rules, t.Config)
+	return nil
+}
+
+// applyTaxes fills the totals of an order whose discounts are applied:
+//
+//  1. Order-level discounts are spread over the lines in proportion to
+//     what is left on them, so every line knows its taxable amount.
+//  2. Each line is taxed at the rate of its category's tax class, or of
+//     the nearest parent category with one, or at the default rate. A
+//     line without any rate is not taxed.
+//  3. Service charges are a percentage of the amount before tax; taxable
+//     ones are taxed at the default rate on top.
+//  4. The grand total is rounded to the configured increment and the
+//     difference is kept in Rounding.
+//
+// Taxes are rounded to cents per line and added up per rate.
+func applyTaxes(order *models.Order, rates []models.TaxRate, categories []models.Category, rules []models.ServiceChargeRule, cfg config.TaxConfig) {
+	rateByID := make(map[uint]*models.TaxRate, len(rates))
+	var defaultRate *models.TaxRate
+	for i := range rates {
+		rateByID[rates[i].ID] = &rates[i]
+		if rates[i].IsDefault {
+			defaultRate = &rates[i]
+		}
+	}
+	categoryByID := make(map[uint]models.Category, len(categories))
+	for _, category := range categories {
+		categoryByID[category.ID] = category
+	}
+	rateFor := func(menu *models.Menu) *models.TaxRate {
+		seen := make(map[uint]bool)
+		for id := menu.CategoryID; id != 0 && !seen[id]; {
+			category, ok := categoryByID[id]
+			if !ok {
+				break
+			}
+			if category.TaxRateID != nil && rateByID[*category.TaxRateID] != nil {
+				return rateByID[*category.TaxRateID]
+			}
+			seen[id] = true
+			if category.ParentID == nil {
+				break
+			}
+			id = *category.ParentID
+		}
+		return defaultRate
+	}
+
+	subtotal, net := 0.0, 0.0
+	for _, item := range order.Items {
+		subtotal += item.Price * float64(item.Quantity)
+		net += item.Price*float64(item.Quantity) - item.Discount
+	}
+	orderDiscount := order.Discount + order.VoucherDiscount + order.LoyaltyDiscount
+
+	type taxKey struct {
+		id        uint
+		inclusive bool
+	}
+	var taxes []models.OrderTax
+	index := make(map[taxKey]int)
+	addTax := func(rate *models.TaxRate, inclusive bool, taxable, amount float64) {
+		key := taxKey{rate.ID, inclusive}
+		i, ok := index[key]
+		if !ok {
+			i = len(taxes)
+			index[key] = i
+			taxes = append(taxes, models.OrderTax{TaxRateID: &rate.ID, Name: rate.Name, Rate: rate.Rate, Inclusive: inclusive})
+		}
+		taxes[i].TaxableAmount = roundMoney(taxes[i].TaxableAmount + taxable)
+		taxes[i].Amount = roundMoney(taxes[i].Amount + amount)
+	}
+
+	allocated, base, tax, exclusiveTax := 0.0, 0.0, 0.0, 0.0
+	for i := range order.Items {
+		item := &order.Items[i]
+		lineNet := item.Price*float64(item.Quantity) - item.Discount
+		share := 0.0
+		if i == len(order.Items)-1 {
+			share = roundMoney(orderDiscount - allocated)
+		} else if net > 0 {
+			share = roundMoney(orderDiscount * lineNet / net)
+		}
+		allocated += share
+		taxable := math.Max(0, lineNet-share)
+
+		item.TaxRateID, item.Tax = nil, 0
+		rate := rateFor(&item.Menu)
+		if rate == nil {
+			base += taxable
+			continue
+		}
+		item.TaxRateID = &rate.ID
+		if rate.Inclusive {
+			item.Tax = roundMoney(taxable * rate.Rate / (100 + rate.Rate))
+			base += taxable - item.Tax
+		} else {
+			item.Tax = roundMoney(taxable * rate.Rate / 100)
+			exclusiveTax += item.Tax
+			base += taxable
+		}
+		tax += item.Tax
+		addTax(rate, rate.Inclusive, taxable, item.Tax)
+	}
+
+	serviceCharge := 0.0
+	for _, rule := range rules {
+		if !rule.Active || rule.OrderType != order.OrderType {
+			continue
+		}
+		charge := roundMoney(base * rule.Rate / 100)
+		serviceCharge += charge
+		if rule.Taxable && defaultRate != nil {
+			chargeTax := roundMoney(charge * defaultRate.Rate / 100)
+			exclusiveTax += chargeTax
+			tax += chargeTax
+			addTax(defaultRate, false, charge, chargeTax)
+		}
+	}
+
+	grandTotal := roundMoney(order.TotalPrice + exclusiveTax + serviceCharge)
+	rounded := roundTo(grandTotal, cfg.RoundingMode, cfg.RoundingIncrement)
+
+	order.Subtotal = roundMoney(subtotal)
+	order.DiscountTotal = roundMoney(subtotal - order.TotalPrice)
+	order.Tax = roundMoney(tax)
+	order.ServiceCharge = roundMoney(serviceCharge)
+	order.Rounding = roundMoney(rounded - grandTotal)
+	order.GrandTotal = rounded
+	order.Taxes = taxes
+}
+
+// roundTo rounds amount to a multiple of increment, the way mode says.
+func roundTo(amount float64, mode string, increment float64) float64 {
+	if increment <= 0 {
+		return roundMoney(amount)
+	}
+	// The epsilon keeps amounts that are already a multiple, but not
+	// exactly in binary, from moving up or down an increment
+	steps := amount / increment
+	switch mode {
+	case config.RoundingUp:
+		steps = math.Ceil(steps - 1e-9)
+	case config.RoundingDown:
+		steps = math.Floor(steps + 1e-9)
+	default:
+		steps = math.Round(steps)
+	}
+	return roundMoney(steps * increment)
+}
+
+// GetAllTaxRates implements TaxService.
+func (t *TaxServiceImpl) GetAllTaxRates() ([]dto.TaxRateResponse, error) {
+	rates, err := t.TaxRepo.GetAllTaxRates()
+	if err != nil {
+		return nil, errors.New("failed to get tax rates: " + err.Error())
+	}
+	responses := make([]dto.TaxRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		responses = append(responses, toTaxRateResponse(&rate))
+	}
+	return responses, nil
+}
+
+// CreateTaxRate implements TaxService.
+func (t *TaxServiceImpl) CreateTaxRate(request dto.TaxRateRequest) (dto.TaxRateResponse, error) {
+	var rate models.TaxRate
+	if err := fillTaxRate(&rate, request); err != nil {
+		return dto.TaxRateResponse{}, err
+	}
+	if err := t.TaxRepo.CreateTaxRate(&rate); err != nil {
+		return dto.TaxRateResponse{}, errors.New("failed to create tax rate: " + err.Error())
+	}
+	return toTaxRateResponse(&rate), nil
+}
+
+// UpdateTaxRate implements TaxService.
+// Orders keep the name and rate they were taxed with.
+func (t *TaxServiceImpl) UpdateTaxRate(id uint, request dto.TaxRateRequest) (dto.TaxRateResponse, error) {
+	rate, err := t.TaxRepo.GetTaxRateByID(id)
+	if err != nil {
+		return dto.TaxRateResponse{}, fmt.Errorf("failed to get tax rate: %w", err)
+	}
+	if err := fillTaxRate(&rate, request); err != nil {
+		return dto.TaxRateResponse{}, err
+	}
+	if err := t.TaxRepo.UpdateTaxRate(&rate); err != nil {
+		return dto.TaxRateResponse{}, errors.New("failed to update tax rate: " + err.Error())
+	}
+	return toTaxRateResponse(&rate), nil
+}
+
+// DeleteTaxRate implements TaxService.
+// Categories using the rate fall back to their parent's or the default.
+func (t *TaxServiceImpl) DeleteTaxRate(id uint) error {
+	if err := t.TaxRepo.DeleteTaxRate(id); err != nil {
+		return fmt.Errorf("failed to delete tax rate: %w", err)
+	}
+	return nil
+}
+
+// GetAllServiceCharges implements TaxService.
+func (t *TaxServiceImpl) GetAllServiceCharges() ([]dto.ServiceChargeResponse, error) {
+	rules, err := t.TaxRepo.GetAllServiceCharges()
+	if err != nil {
+		return nil, errors.New("failed to get service charges: " + err.Error())
+	}
+	responses := make([]dto.ServiceChargeResponse, 0, len(rules))
+	for _, rule := range rules {
+		responses = append(responses, toServiceChargeResponse(&rule))
+	}
+	return responses, nil
+}
+
+// CreateServiceCharge implements TaxService.
+func (t *TaxServiceImpl) CreateServiceCharge(request dto.ServiceChargeRequest) (dto.ServiceChargeResponse, error) {
+	var rule models.ServiceChargeRule
+	if err := fillServiceCharge(&rule, request); err != nil {
+		return dto.ServiceChargeResponse{}, err
+	}
+	if err := t.TaxRepo.CreateServiceCharge(&rule); err != nil {
+		return dto.ServiceChargeResponse{}, errors.New("failed to create service charge: " + err.Error())
+	}
+	return toServiceChargeResponse(&rule), nil
+}
+
+// UpdateServiceCharge implements TaxService.
+func (t *TaxServiceImpl) UpdateServiceCharge(id uint, request dto.ServiceChargeRequest) (dto.ServiceChargeResponse, error) {
+	rule, err := t.TaxRepo.GetServiceChargeByID(id)
+	if err != nil {
+		return dto.ServiceChargeResponse{}, fmt.Errorf("failed to get service charge: %w", err)
+	}
+	if err := fillServiceCharge(&rule, request); err != nil {
+		return dto.ServiceChargeResponse{}, err
+	}
+	if err := t.TaxRepo.UpdateServiceCharge(&rule); err != nil {
+		return dto.ServiceChargeResponse{}, errors.New("failed to update service charge: " + err.Error())
+	}
+	return toServiceChargeResponse(&rule), nil
+}
+
+// DeleteServiceCharge implements TaxService.
+func (t *TaxServiceImpl) DeleteServiceCharge(id uint) error {
+	if err := t.TaxRepo.DeleteServiceCharge(id); err != nil {
+		return fmt.Errorf("failed to delete service charge: %w", err)
+	}
+	return nil
+}
+
+// fillTaxRate validates request and copies it onto rate.
+func fillTaxRate(rate *models.TaxRate, request dto.TaxRateRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTax)
+	}
+	if request.Rate < 0 || request.Rate > 100 {
+		return fmt.Errorf("%w: rate must be between 0 and 100", ErrInvalidTax)
+	}
+
+	rate.Name = name
+	rate.Rate = request.Rate
+	rate.Inclusive = request.Inclusive
+	rate.IsDefault = request.IsDefault
+	return nil
+}
+
+// fillServiceCharge validates request and copies it onto rule.
+func fillServiceCharge(rule *models.ServiceChargeRule, request dto.ServiceChargeRequest) error {
+	name := strings.TrimSpace(request.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidTax)
+	}
+	if request.OrderType != models.OrderTypeDineIn && request.OrderType != models.OrderTypeTakeaway {
+		return fmt.Errorf("%w: order_type must be %s or %s", ErrInvalidTax, models.OrderTypeDineIn, models.OrderTypeTakeaway)
+	}
+	if request.Rate <= 0 || request.Rate > 100 {
+		return fmt.Errorf("%w: rate must be above 0 and at most 100", ErrInvalidTax)
+	}
+
+	rule.Name = name
+	rule.OrderType = request.OrderType
+	rule.Rate = request.Rate
+	rule.Taxable = request.Taxable
+	rule.Active = request.Active == nil || *request.Active
+	return nil
+}
+
+func toTaxRateResponse(rate *models.TaxRate) dto.TaxRateResponse {
+	return dto.TaxRateResponse{
+		ID:        rate.ID,
+		Name:      rate.Name,
+		Rate:      rate.Rate,
+		Inclusive: rate.Inclusive,
+		IsDefault: rate.IsDefault,
+		CreatedAt: rate.CreatedAt,
+		UpdatedAt: rate.UpdatedAt,
+	}
+}
+
+func toServiceChargeResponse(rule *models.ServiceChargeRule) dto.ServiceChargeResponse {
+	return dto.ServiceChargeResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		OrderType: rule.OrderType,
+		Rate:      rule.Rate,
+		Taxable:   rule.Taxable,
+		Active:    rule.Active,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+}
+
+func NewTaxService(taxRepo repositories.TaxRepository, categoryRepo repositories.CategoryRepository, cfg config.TaxConfig) TaxService {
+	return &TaxServiceImpl{
+		TaxRepo:      taxRepo,
+		CategoryRepo: categoryRepo,
+		Config:       cfg,
+	}
+}
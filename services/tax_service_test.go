@@ -0,0 +1,156 @@
+package services
+
+import (
+	"coffee_shop/config"
+	"coffee_shop/models"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		amount    float64
+		mode      string
+		increment float64
+		want      float64
+	}{
+		{1369.745, config.RoundingNearest, 0, 1369.75},
+		{1349.99, config.RoundingNearest, 100, 1300},
+		{1350, config.RoundingNearest, 100, 1400},
+		{1301, config.RoundingUp, 100, 1400},
+		{1399, config.RoundingDown, 100, 1300},
+		{1300, config.RoundingUp, 100, 1300},
+		{0.3, config.RoundingUp, 0.1, 0.3},
+		{0.3, config.RoundingDown, 0.1, 0.3},
+		{10.12, config.RoundingNearest, 0.05, 10.10},
+		{10.13, config.RoundingNearest, 0.05, 10.15},
+	}
+	for _, tt := range tests {
+		if got := roundTo(tt.amount, tt.mode, tt.increment); got != tt.want {
+			t.Errorf("roundTo(%v, %s, %v) = %v, want %v", tt.amount, tt.mode, tt.increment, got, tt.want)
+		}
+	}
+}
+
+func TestApplyTaxes(t *testing.T) {
+	vat := models.TaxRate{Model: gorm.Model{ID: 1}, Name: "VAT", Rate: 11, IsDefault: true}
+	included := models.TaxRate{Model: gorm.Model{ID: 2}, Name: "Included", Rate: 10, Inclusive: true}
+	includedID, drinksID := uint(2), uint(1)
+	categories := []models.Category{
+		{Model: gorm.Model{ID: 1}, TaxRateID: &includedID},
+		{Model: gorm.Model{ID: 2}, ParentID: &drinksID},
+		{Model: gorm.Model{ID: 3}},
+	}
+	line := func(category uint, price float64, quantity int) models.OrderMenuItem {
+		return models.OrderMenuItem{Price: price, Quantity: quantity, Menu: models.Menu{CategoryID: category}}
+	}
+	dineIn := models.ServiceChargeRule{OrderType: models.OrderTypeDineIn, Rate: 5, Taxable: true, Active: true}
+
+	tests := []struct {
+		name          string
+		order         models.Order
+		rates         []models.TaxRate
+		rules         []models.ServiceChargeRule
+		cfg           config.TaxConfig
+		wantLineTax   []float64
+		wantTax       float64
+		wantCharge    float64
+		wantRounding  float64
+		wantTotal     float64
+		wantBreakdown []models.OrderTax
+	}{
+		{
+			name:          "default exclusive rate",
+			order:         models.Order{Items: []models.OrderMenuItem{line(3, 10000, 2)}, TotalPrice: 20000},
+			rates:         []models.TaxRate{vat, included},
+			wantLineTax:   []float64{2200},
+			wantTax:       2200,
+			wantTotal:     22200,
+			wantBreakdown: []models.OrderTax{{Name: "VAT", Rate: 11, TaxableAmount: 20000, Amount: 2200}},
+		},
+		{
+			name:          "inclusive rate of the parent category",
+			order:         models.Order{Items: []models.OrderMenuItem{line(2, 11000, 1)}, TotalPrice: 11000},
+			rates:         []models.TaxRate{vat, included},
+			wantLineTax:   []float64{1000},
+			wantTax:       1000,
+			wantTotal:     11000,
+			wantBreakdown: []models.OrderTax{{Name: "Included", Rate: 10, Inclusive: true, TaxableAmount: 11000, Amount: 1000}},
+		},
+		{
+			name:          "order discount spread over the lines",
+			order:         models.Order{Items: []models.OrderMenuItem{line(3, 10000, 1), line(3, 5000, 1)}, Discount: 3000, TotalPrice: 12000},
+			rates:         []models.TaxRate{vat},
+			wantLineTax:   []float64{880, 440},
+			wantTax:       1320,
+			wantTotal:     13320,
+			wantBreakdown: []models.OrderTax{{Name: "VAT", Rate: 11, TaxableAmount: 12000, Amount: 1320}},
+		},
+		{
+			name:          "taxable service charge",
+			order:         models.Order{OrderType: models.OrderTypeDineIn, Items: []models.OrderMenuItem{line(3, 10000, 1)}, TotalPrice: 10000},
+			rates:         []models.TaxRate{vat},
+			rules:         []models.ServiceChargeRule{dineIn},
+			wantLineTax:   []float64{1100},
+			wantTax:       1155,
+			wantCharge:    500,
+			wantTotal:     11655,
+			wantBreakdown: []models.OrderTax{{Name: "VAT", Rate: 11, TaxableAmount: 10500, Amount: 1155}},
+		},
+		{
+			name:          "service charge of another order type",
+			order:         models.Order{OrderType: models.OrderTypeTakeaway, Items: []models.OrderMenuItem{line(3, 10000, 1)}, TotalPrice: 10000},
+			rates:         []models.TaxRate{vat},
+			rules:         []models.ServiceChargeRule{dineIn},
+			wantLineTax:   []float64{1100},
+			wantTax:       1100,
+			wantTotal:     11100,
+			wantBreakdown: []models.OrderTax{{Name: "VAT", Rate: 11, TaxableAmount: 10000, Amount: 1100}},
+		},
+		{
+			name:        "no rate applies",
+			order:       models.Order{Items: []models.OrderMenuItem{line(3, 10000, 1)}, TotalPrice: 10000},
+			rates:       []models.TaxRate{included},
+			wantLineTax: []float64{0},
+			wantTotal:   10000,
+		},
+		{
+			name:          "grand total rounded",
+			order:         models.Order{Items: []models.OrderMenuItem{line(3, 1234, 1)}, TotalPrice: 1234},
+			rates:         []models.TaxRate{vat},
+			cfg:           config.TaxConfig{RoundingMode: config.RoundingNearest, RoundingIncrement: 100},
+			wantLineTax:   []float64{135.74},
+			wantTax:       135.74,
+			wantRounding:  30.26,
+			wantTotal:     1400,
+			wantBreakdown: []models.OrderTax{{Name: "VAT", Rate: 11, TaxableAmount: 1234, Amount: 135.74}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order := tt.order
+			applyTaxes(&order, tt.rates, categories, tt.rules, tt.cfg)
+			for i, item := range order.Items {
+				if item.Tax != tt.wantLineTax[i] {
+					t.Errorf("line %d tax = %v, want %v", i+1, item.Tax, tt.wantLineTax[i])
+				}
+			}
+			if order.Tax != tt.wantTax || order.ServiceCharge != tt.wantCharge || order.Rounding != tt.wantRounding || order.GrandTotal != tt.wantTotal {
+				t.Errorf("tax, service charge, rounding, grand total = %v, %v, %v, %v, want %v, %v, %v, %v",
+					order.Tax, order.ServiceCharge, order.Rounding, order.GrandTotal,
+					tt.wantTax, tt.wantCharge, tt.wantRounding, tt.wantTotal)
+			}
+			if len(order.Taxes) != len(tt.wantBreakdown) {
+				t.Fatalf("got %d tax lines, want %d", len(order.Taxes), len(tt.wantBreakdown))
+			}
+			for i, tax := range order.Taxes {
+				want := tt.wantBreakdown[i]
+				if tax.Name != want.Name || tax.Rate != want.Rate || tax.Inclusive != want.Inclusive ||
+					tax.TaxableAmount != want.TaxableAmount || tax.Amount != want.Amount {
+					t.Errorf("tax line %d = %+v, want %+v", i+1, tax, want)
+				}
+			}
+		})
+	}
+}
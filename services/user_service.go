@@ -6,10 +6,7 @@ import (
 	"coffee_shop/models"
 	"coffee_shop/repositories"
 	"coffee_shop/utils"
-	"context"
 	"errors"
-	"log"
-	"time"
 
 	"gorm.io/gorm"
 )
@@ -21,12 +18,12 @@ type UserService interface {
 	UpdateUser(user_id uint, request models.User) (dto.UpdateUserResponse, error)
 	DeleteUser(user_id uint) (int, error)
 	GetUserByID(user_id uint) (dto.GetUserByIDResponse, error)
+	ResetPassword(email string, password string) error
 }
 
-var ctx = context.Background()
-
 type UserServiceImpl struct {
 	userRepo repositories.UserRepository
+	cfg      *config.Config
 }
 
 // Register implements UserService.
@@ -72,27 +69,16 @@ func (u *UserServiceImpl) Login(request dto.LoginRequest) (dto.LoginResponse, er
 	}
 
 	// Generate JWT token
-	accessToken, err := utils.GenerateJWT(user.ID, user.Email, user.Role)
+	accessToken, err := utils.GenerateJWT(u.cfg.JWT, user.ID, user.Email, user.Role)
 	if err != nil {
 		return dto.LoginResponse{}, err
 	}
 
-	refresherToken, err := utils.GenerateRefresherJWT(user.ID, user.Email, user.Role)
+	refresherToken, err := utils.GenerateRefresherJWT(u.cfg.JWT, user.ID, user.Email, user.Role)
 	if err != nil {
 		return dto.LoginResponse{}, err
 	}
 
-	// Save Refresher Token to Redis
-	rdb, err := config.RedisClient()
-	if err != nil {
-		log.Fatal("Failed Connect to Redis")
-	}
-
-	err = rdb.Set(ctx, user.Email, refresherToken, 7*24*time.Hour).Err()
-	if err != nil {
-		return dto.LoginResponse{}, errors.New("failed to save refresher token to redis")
-	}
-
 	return dto.ToLoginResponse(*user, accessToken, refresherToken), nil
 }
 
@@ -134,11 +120,40 @@ func (u *UserServiceImpl) UpdateUser(user_id uint, request models.User) (dto.Upd
 		return dto.UpdateUserResponse{}, errors.New("failed to update user: " + err.Error())
 	}
 
-	return dto.ToUpdateUserResponse(*user), nil
+	// Read the row back so the response carries the ID and timestamps
+	updated, err := u.userRepo.GetUserByID(user_id)
+	if err != nil {
+		return dto.UpdateUserResponse{}, errors.New("failed to get updated user: " + err.Error())
+	}
+	return dto.ToUpdateUserResponse(*updated), nil
+}
+
+// ResetPassword implements UserService.
+func (u *UserServiceImpl) ResetPassword(email string, password string) error {
+	if password == "" {
+		return errors.New("password is required")
+	}
+
+	user, err := u.userRepo.CheckEmailValid(email)
+	if err != nil {
+		return errors.New("user not found : " + err.Error())
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	err = u.userRepo.UpdateUser(user.ID, &models.User{Password: hashedPassword})
+	if err != nil {
+		return errors.New("failed to reset password: " + err.Error())
+	}
+	return nil
 }
 
-func NewUserService(db *gorm.DB) UserService {
+func NewUserService(db *gorm.DB, cfg *config.Config) UserService {
 	return &UserServiceImpl{
 		userRepo: repositories.NewUserRepository(db),
+		cfg:      cfg,
 	}
 }
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"coffee_shop/dto"
+	"coffee_shop/models"
+	"coffee_shop/repositories"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrInvalidVoucher marks a voucher request that fails validation.
+var ErrInvalidVoucher = errors.New("invalid voucher")
+
+// VoucherRejectedError tells why a voucher code cannot be used on an order.
+type VoucherRejectedError struct {
+	Reason string
+}
+
+func (e *VoucherRejectedError) Error() string {
+	return "voucher rejected: " + e.Reason
+}
+
+type VoucherService interface {
+	GetAllVouchers() ([]dto.VoucherResponse, error)
+	GetVoucherByID(id uint) (dto.VoucherResponse, error)
+	CreateVoucher(request dto.VoucherRequest) (dto.VoucherResponse, error)
+	UpdateVoucher(id uint, request dto.VoucherRequest) (dto.VoucherResponse, error)
+	DeleteVoucher(id uint) error
+	ApplyVoucher(order *models.Order, code string, now time.Time) error
+}
+
+type VoucherServiceImpl struct {
+	VoucherRepo  repositories.VoucherRepository
+	OrderRepo    repositories.OrderRepository
+	CategoryRepo repositories.CategoryRepository
+}
+
+// ApplyVoucher implements VoucherService.
+// It runs after promotions on a priced order and takes the voucher
+// discount off its total. The limits checked here are checked again under
+// a lock when the order is saved, this check only gives an early answer.
+func (v *VoucherServiceImpl) ApplyVoucher(order *models.Order, code string, now time.Time) error {
+	voucher, err := v.VoucherRepo.FindByCode(NormalizeVoucherCode(code))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &VoucherRejectedError{Reason: "unknown voucher code"}
+	}
+	if err != nil {
+		return errors.New("failed to get voucher: " + err.Error())
+	}
+
+	switch {
+	case !voucher.Active:
+		return &VoucherRejectedError{Reason: "voucher is not active"}
+	case voucher.ValidFrom != nil && now.Before(*voucher.ValidFrom):
+		return &VoucherRejectedError{Reason: "voucher is not valid yet"}
+	case voucher.ValidUntil != nil && now.After(*voucher.ValidUntil):
+		return &VoucherRejectedError{Reason: "voucher has expired"}
+	case voucher.MaxRedemptions > 0 && voucher.RedemptionCount >= voucher.MaxRedemptions:
+		return &VoucherRejectedError{Reason: "voucher has been fully redeemed"}
+	}
+
+	subtotal := 0.0
+	for _, item := range order.Items {
+		subtotal += item.Price * float64(item.Quantity)
+	}
+	if subtotal < voucher.MinSpend {
+		return &VoucherRejectedError{Reason: fmt.Sprintf("voucher needs a minimum spend of %.2f", voucher.MinSpend)}
+	}
+
+	if voucher.MaxPerUser > 0 {
+		used, err := v.VoucherRepo.CountRedemptions(voucher.ID, order.UserID)
+		if err != nil {
+			return errors.New("failed to count redemptions: " + err.Error())
+		}
+		if used >= int64(voucher.MaxPerUser) {
+			return &VoucherRejectedError{Reason: "voucher was already used the maximum number of times"}
+		}
+	}
+	if voucher.FirstOrderOnly {
+		previous, err := v.OrderRepo.CountOrdersByUser(order.UserID)
+		if err != nil {
+			return errors.New("failed to count orders: " + err.Error())
+		}
+		if previous > 0 {
+			return &VoucherRejectedError{Reason: "voucher is only valid on a first order"}
+		}
+	}
+
+	base, err := v.eligibleAmount(voucher, order)
+	if err != nil {
+		return err
+	}
+	if base <= 0 {
+		return &VoucherRejectedError{Reason: "no item in the order is eligible for this voucher"}
+	}
+
+	discount := voucher.Value
+	if voucher.Type == models.PromotionPercentage {
+		discount = base * voucher.Value / 100
+		if voucher.MaxDiscount > 0 {
+			discount = math.Min(discount, voucher.MaxDiscount)
+		}
+	}
+	discount = roundMoney(math.Min(discount, base))
+
+	order.VoucherID = &voucher.ID
+	order.VoucherDiscount = discount
+	order.TotalPrice = roundMoney(order.TotalPrice - discount)
+	return nil
+}
+
+// eligibleAmount is what a voucher discounts: the lines of its categories,
+// or their subcategories, after line promotions, and the order total when
+// it has no categories.
+func (v *VoucherServiceImpl) eligibleAmount(voucher *models.Voucher, order *models.Order) (float64, error) {
+	if len(voucher.Categories) == 0 {
+		return order.TotalPrice, nil
+	}
+
+	categories, err := v.CategoryRepo.GetAllCategories()
+	if err != nil {
+		return 0, errors.New("failed to read categories: " + err.Error())
+	}
+	parents := make(map[uint]*uint, len(categories))
+	for _, category := range categories {
+		parents[category.ID] = category.ParentID
+	}
+	eligible := make(map[uint]bool, len(voucher.Categories))
+	for _, category := range voucher.Categories {
+		eligible[category.ID] = true
+	}
+
+	amount := 0.0
+	for _, item := range order.Items {
+		seen := make(map[uint]bool)
+		for id := &item.Menu.CategoryID; id != nil && *id != 0 && !seen[*id]; id = parents[*id] {
+			if eligible[*id] {
+				amount += item.Price*float64(item.Quantity) - item.Discount
+				break
+			}
+			seen[*id] = true
+		}
+	}
+	return math.Min(amount, order.TotalPrice), nil
+}
+
+// GetAllVouchers implements VoucherService.
+func (v *VoucherServiceImpl) GetAllVouchers() ([]dto.VoucherResponse, error) {
+	vouchers, err := v.VoucherRepo.GetAllVouchers()
+	if err != nil {
+		return nil, errors.New("failed to get vouchers: " + err.Error())
+	}
+	responses := make([]dto.VoucherResponse, 0, len(vouchers))
+	for _, voucher := range vouchers {
+		responses = append(responses, toVoucherResponse(&voucher))
+	}
+	return responses, nil
+}
+
+// GetVoucherByID implements VoucherService.
+func (v *VoucherServiceImpl) GetVoucherByID(id uint) (dto.VoucherResponse, error) {
+	voucher, err := v.VoucherRepo.GetVoucherByID(id)
+	if err != nil {
+		return dto.VoucherResponse{}, fmt.Errorf("failed to get voucher: %w", err)
+	}
+	return toVoucherResponse(&voucher), nil
+}
+
+// CreateVoucher implements VoucherService.
+func (v *VoucherServiceImpl) CreateVoucher(request dto.VoucherRequest) (dto.VoucherResponse, error) {
+	var voucher models.Voucher
+	if err := v.fillVoucher(&voucher, request); err != nil {
+		return dto.VoucherResponse{}, err
+	}
+	err := v.VoucherRepo.CreateVoucher(&voucher)
+	if errors.Is(err, repositories.ErrDuplicateVoucherCode) {
+		return dto.VoucherResponse{}, fmt.Errorf("%w: code %s is already used by another voucher", ErrInvalidVoucher, voucher.Code)
+	}
+	if err != nil {
+		return dto.VoucherResponse{}, errors.New("failed to create voucher: " + err.Error())
+	}
+	return toVoucherResponse(&voucher), nil
+}
+
+// UpdateVoucher implements VoucherService.
+// The request replaces every field but the redemption count.
+func (v *VoucherServiceImpl) UpdateVoucher(id uint, request dto.VoucherRequest) (dto.VoucherResponse, error) {
+	voucher, err := v.VoucherRepo.GetVoucherByID(id)
+	if err != nil {
+		return dto.VoucherResponse{}, fmt.Errorf("failed to get voucher: %w", err)
+	}
+	if err := v.fillVoucher(&voucher, request); err != nil {
+		return dto.VoucherResponse{}, err
+	}
+	err = v.VoucherRepo.UpdateVoucher(&voucher)
+	if errors.Is(err, repositories.ErrDuplicateVoucherCode) {
+		return dto.VoucherResponse{}, fmt.Errorf("%w: code %s is already used by another voucher", ErrInvalidVoucher, voucher.Code)
+	}
+	if err != nil {
+		return dto.VoucherResponse{}, errors.New("failed to update voucher: " + err.Error())
+	}
+	return toVoucherResponse(&voucher), nil
+}
+
+// DeleteVoucher implements VoucherService.
+func (v *VoucherServiceImpl) DeleteVoucher(id uint) error {
+	if err := v.VoucherRepo.DeleteVoucher(id); err != nil {
+		return fmt.Errorf("failed to delete voucher: %w", err)
+	}
+	return nil
+}
+
+// fillVoucher validates request and copies it onto voucher.
+func (v *VoucherServiceImpl) fillVoucher(voucher *models.Voucher, request dto.VoucherRequest) error {
+	invalid := func(format string, args ...any) error {
+		return fmt.Errorf("%w: %s", ErrInvalidVoucher, fmt.Sprintf(format, args...))
+	}
+
+	code := NormalizeVoucherCode(request.Code)
+	if code == "" || strings.ContainsAny(code, " \t\r\n") {
+		return invalid("code is required and cannot contain spaces")
+	}
+	existing, err := v.VoucherRepo.FindByCode(code)
+	if err == nil && existing.ID != voucher.ID {
+		return invalid("code %s is already used by another voucher", code)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return errors.New("failed to check voucher code: " + err.Error())
+	}
+
+	switch request.Type {
+	case models.PromotionPercentage:
+		if request.Value <= 0 || request.Value > 100 {
+			return invalid("a percentage must be above 0 and at most 100")
+		}
+	case models.PromotionFixed:
+		if request.Value <= 0 {
+			return invalid("a fixed discount must be above 0")
+		}
+	default:
+		return invalid("unknown type %q, expected %s or %s", request.Type, models.PromotionPercentage, models.PromotionFixed)
+	}
+	if request.MaxDiscount < 0 || request.MinSpend < 0 || request.MaxRedemptions < 0 || request.MaxPerUser < 0 {
+		return invalid("max_discount, min_spend, max_redemptions and max_per_user cannot be negative")
+	}
+	if request.ValidFrom != nil && request.ValidUntil != nil && request.ValidUntil.Before(*request.ValidFrom) {
+		return invalid("valid_until is before valid_from")
+	}
+
+	categories := make([]models.Category, 0, len(request.CategoryIDs))
+	for _, id := range request.CategoryIDs {
+		category, err := v.CategoryRepo.GetCategoryByID(id)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return invalid("category %d does not exist", id)
+		}
+		if err != nil {
+			return errors.New("failed to get category: " + err.Error())
+		}
+		categories = append(categories, category)
+	}
+
+	voucher.Code = code
+	voucher.Description = request.Description
+	voucher.Type = request.Type
+	voucher.Value = request.Value
+	voucher.MaxDiscount = request.MaxDiscount
+	voucher.MinSpend = request.MinSpend
+	voucher.ValidFrom = request.ValidFrom
+	voucher.ValidUntil = request.ValidUntil
+	voucher.MaxRedemptions = request.MaxRedemptions
+	voucher.MaxPerUser = request.MaxPerUser
+	voucher.FirstOrderOnly = request.FirstOrderOnly
+	voucher.Categories = categories
+	voucher.Active = request.Active == nil || *request.Active
+	return nil
+}
+
+// NormalizeVoucherCode makes codes case-insensitive.
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+func toVoucherResponse(voucher *models.Voucher) dto.VoucherResponse {
+	categoryIDs := make([]uint, 0, len(voucher.Categories))
+	for _, category := range voucher.Categories {
+		categoryIDs = append(categoryIDs, category.ID)
+	}
+	return dto.VoucherResponse{
+		ID:              voucher.ID,
+		Code:            voucher.Code,
+		Description:     voucher.Description,
+		Type:            voucher.Type,
+		Value:           voucher.Value,
+		MaxDiscount:     voucher.MaxDiscount,
+		MinSpend:        voucher.MinSpend,
+		ValidFrom:       voucher.ValidFrom,
+		ValidUntil:      voucher.ValidUntil,
+		MaxRedemptions:  voucher.MaxRedemptions,
+		MaxPerUser:      voucher.MaxPerUser,
+		RedemptionCount: voucher.RedemptionCount,
+		FirstOrderOnly:  voucher.FirstOrderOnly,
+		CategoryIDs:     categoryIDs,
+		Active:          voucher.Active,
+		CreatedAt:       voucher.CreatedAt,
+		UpdatedAt:       voucher.UpdatedAt,
+	}
+}
+
+func NewVoucherService(voucherRepo repositories.VoucherRepository, orderRepo repositories.OrderRepository, categoryRepo repositories.CategoryRepository) VoucherService {
+	return &VoucherServiceImpl{
+		VoucherRepo:  voucherRepo,
+		OrderRepo:    orderRepo,
+		CategoryRepo: categoryRepo,
+	}
+}
@@ -0,0 +1,152 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LocalStore keeps blobs on the local filesystem under root. It is the
+// default for a single instance; use the S3 store when running more.
+type LocalStore struct {
+	root          string
+	publicBaseURL string
+}
+
+func NewLocalStore(root string, publicBaseURL string) *LocalStore {
+	return &LocalStore{
+		root:          root,
+		publicBaseURL: strings.TrimSuffix(publicBaseURL, "/"),
+	}
+}
+
+// path maps a key to a file under root. Cleaning the key as an absolute
+// path first drops any ".." so a key can never point outside root.
+func (l *LocalStore) path(key string) string {
+	return filepath.Join(l.root, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+// Put implements BlobStore.
+// The file is written to a temporary name and renamed, so readers never
+// see a partial file.
+func (l *LocalStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	dst := l.path(key)
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dst)
+}
+
+// Get implements BlobStore.
+func (l *LocalStore) Get(ctx context.Context, key string) (io.ReadCloser, BlobInfo, error) {
+	file, err := os.Open(l.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, BlobInfo{}, ErrNotFound
+		}
+		return nil, BlobInfo{}, err
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, BlobInfo{}, err
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, BlobInfo{}, ErrNotFound
+	}
+	return file, l.info(key, stat), nil
+}
+
+// Stat implements BlobStore.
+func (l *LocalStore) Stat(ctx context.Context, key string) (BlobInfo, error) {
+	stat, err := os.Stat(l.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return BlobInfo{}, ErrNotFound
+		}
+		return BlobInfo{}, err
+	}
+	if stat.IsDir() {
+		return BlobInfo{}, ErrNotFound
+	}
+	return l.info(key, stat), nil
+}
+
+// Delete implements BlobStore. Deleting a missing key is not an error.
+func (l *LocalStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(l.path(key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List implements BlobStore.
+// The prefix is treated as a directory, "menu_img" lists everything below
+// image/menu_img/.
+func (l *LocalStore) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	root := l.path(prefix)
+	var blobs []BlobInfo
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(l.root, p)
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, l.info(filepath.ToSlash(rel), stat))
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return blobs, err
+}
+
+// URL implements BlobStore.
+func (l *LocalStore) URL(ctx context.Context, key string) (string, error) {
+	return l.publicBaseURL + escapePath(path.Clean("/"+key)), nil
+}
+
+func (l *LocalStore) info(key string, stat fs.FileInfo) BlobInfo {
+	return BlobInfo{
+		Key:          key,
+		Size:         stat.Size(),
+		ContentType:  mime.TypeByExtension(filepath.Ext(key)),
+		LastModified: stat.ModTime(),
+		ETag:         `"` + strconv.FormatInt(stat.ModTime().UnixNano(), 36) + "-" + strconv.FormatInt(stat.Size(), 36) + `"`,
+	}
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	root := t.TempDir()
+	store := NewLocalStore(root, "")
+
+	if err := store.Put(ctx, "menu_img/a.jpg", strings.NewReader("latte"), 5, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, "other/b.jpg", strings.NewReader("mocha"), 5, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	body, info, err := store.Get(ctx, "menu_img/a.jpg")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(body)
+	body.Close()
+	if string(got) != "latte" || info.Size != 5 || info.ContentType != "image/jpeg" {
+		t.Errorf("Get = %q, %+v", got, info)
+	}
+
+	blobs, err := store.List(ctx, "menu_img")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(blobs) != 1 || blobs[0].Key != "menu_img/a.jpg" {
+		t.Errorf("List(menu_img) = %+v", blobs)
+	}
+	if blobs, err := store.List(ctx, "missing"); err != nil || len(blobs) != 0 {
+		t.Errorf("List(missing) = %+v, %v, want nothing", blobs, err)
+	}
+
+	if err := store.Delete(ctx, "menu_img/a.jpg"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := store.Delete(ctx, "menu_img/a.jpg"); err != nil {
+		t.Errorf("Delete of a missing key = %v, want nil", err)
+	}
+	if _, err := store.Stat(ctx, "menu_img/a.jpg"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Delete = %v, want ErrNotFound", err)
+	}
+	if _, err := store.Stat(ctx, "menu_img"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat of a directory = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStoreKeysStayUnderRoot(t *testing.T) {
+	ctx := context.Background()
+	parent := t.TempDir()
+	root := filepath.Join(parent, "store")
+	store := NewLocalStore(root, "")
+
+	if err := store.Put(ctx, "../escape.txt", strings.NewReader("x"), 1, "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escape.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("a key with .. was written outside the root")
+	}
+	if _, err := os.Stat(filepath.Join(root, "escape.txt")); err != nil {
+		t.Errorf("a key with .. should land under the root: %v", err)
+	}
+}
+
+func TestLocalStoreURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/image/")
+	got, err := store.URL(context.Background(), "menu_img/../a b.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "http://localhost:8080/image/a%20b.jpg"; got != want {
+		t.Errorf("URL = %q, want %q", got, want)
+	}
+}
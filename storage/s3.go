@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"coffee_shop/config"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3Store keeps blobs in an S3-compatible bucket (AWS S3, MinIO, R2, ...),
+// so every instance of the API sees the same files.
+type S3Store struct {
+	client       *minio.Client
+	bucket       string
+	publicURL    string
+	signedURLTTL time.Duration
+}
+
+func NewS3Store(cfg config.StorageConfig) (*S3Store, error) {
+	client, err := minio.New(cfg.S3Endpoint, &minio.Options{
+		Creds:        credentials.NewStaticV4(cfg.S3AccessKey, cfg.S3SecretKey, ""),
+		Secure:       cfg.S3UseSSL,
+		Region:       cfg.S3Region,
+		BucketLookup: bucketLookup(cfg.S3PathStyle),
+	})
+	if err != nil {
+		return nil, errors.New("failed to create S3 client: " + err.Error())
+	}
+	return &S3Store{
+		client:       client,
+		bucket:       cfg.S3Bucket,
+		publicURL:    strings.TrimSuffix(cfg.S3PublicURL, "/"),
+		signedURLTTL: cfg.SignedURLTTL,
+	}, nil
+}
+
+// MinIO and most self-hosted S3 servers only support path-style URLs.
+func bucketLookup(pathStyle bool) minio.BucketLookupType {
+	if pathStyle {
+		return minio.BucketLookupPath
+	}
+	return minio.BucketLookupAuto
+}
+
+// Put implements BlobStore.
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, r, size, minio.PutObjectOptions{
+		ContentType: contentType,
+	})
+	return err
+}
+
+// Get implements BlobStore.
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, BlobInfo, error) {
+	info, err := s.Stat(ctx, key)
+	if err != nil {
+		return nil, BlobInfo{}, err
+	}
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, BlobInfo{}, s.translate(err)
+	}
+	return object, info, nil
+}
+
+// Stat implements BlobStore.
+func (s *S3Store) Stat(ctx context.Context, key string) (BlobInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		return BlobInfo{}, s.translate(err)
+	}
+	return BlobInfo{
+		Key:          key,
+		Size:         stat.Size,
+		ContentType:  stat.ContentType,
+		LastModified: stat.LastModified,
+		ETag:         `"` + stat.ETag + `"`,
+	}, nil
+}
+
+// Delete implements BlobStore. S3 does not report missing keys on delete.
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
+
+// List implements BlobStore.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]BlobInfo, error) {
+	if prefix != "" {
+		prefix = strings.TrimSuffix(prefix, "/") + "/"
+	}
+	var blobs []BlobInfo
+	for object := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if object.Err != nil {
+			return nil, object.Err
+		}
+		blobs = append(blobs, BlobInfo{
+			Key:          object.Key,
+			Size:         object.Size,
+			ContentType:  object.ContentType,
+			LastModified: object.LastModified,
+			ETag:         `"` + object.ETag + `"`,
+		})
+	}
+	return blobs, nil
+}
+
+// URL implements BlobStore.
+// With STORAGE_S3_PUBLIC_URL set the bucket is assumed to be publicly
+// readable (directly or through a CDN); otherwise a presigned GET URL is
+// returned that expires after STORAGE_SIGNED_URL_TTL.
+func (s *S3Store) URL(ctx context.Context, key string) (string, error) {
+	if s.publicURL != "" {
+		return s.publicURL + escapePath("/"+key), nil
+	}
+	signed, err := s.client.PresignedGetObject(ctx, s.bucket, key, s.signedURLTTL, url.Values{})
+	if err != nil {
+		return "", err
+	}
+	return signed.String(), nil
+}
+
+func (s *S3Store) translate(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrNotFound
+	}
+	return err
+}
@@ -0,0 +1,168 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// fakeS3 is a MinIO-style S3 server holding one bucket in memory. It
+// speaks just enough of the path-style API for S3Store: put, get, head,
+// delete and ListObjectsV2.
+type fakeS3 struct {
+	bucket  string
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+	etag        string
+}
+
+func newFakeS3(bucket string) *fakeS3 {
+	return &fakeS3{bucket: bucket, objects: map[string]fakeObject{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.bucket {
+		f.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case key == "" && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r.URL.Query().Get("prefix"))
+	case key == "":
+		f.error(w, http.StatusNotImplemented, "NotImplemented")
+	case r.Method == http.MethodPut:
+		data, err := readPayload(r)
+		if err != nil {
+			f.error(w, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		sum := md5.Sum(data)
+		object := fakeObject{
+			data:        data,
+			contentType: r.Header.Get("Content-Type"),
+			modified:    time.Now().UTC().Truncate(time.Second),
+			etag:        hex.EncodeToString(sum[:]),
+		}
+		f.objects[key] = object
+		w.Header().Set("ETag", `"`+object.etag+`"`)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet || r.Method == http.MethodHead:
+		object, ok := f.objects[key]
+		if !ok {
+			f.error(w, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(object.data)))
+		w.Header().Set("ETag", `"`+object.etag+`"`)
+		w.Header().Set("Last-Modified", object.modified.Format(http.TimeFormat))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			w.Write(object.data)
+		}
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		f.error(w, http.StatusNotImplemented, "NotImplemented")
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string) {
+	type content struct {
+		Key          string
+		LastModified string
+		ETag         string
+		Size         int64
+	}
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []content
+	}{Name: f.bucket, Prefix: prefix, MaxKeys: 1000}
+
+	keys := make([]string, 0, len(f.objects))
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		object := f.objects[key]
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			LastModified: object.modified.Format(time.RFC3339),
+			ETag:         `"` + object.etag + `"`,
+			Size:         int64(len(object.data)),
+		})
+	}
+	result.KeyCount = len(result.Contents)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeS3) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+	}{Code: code})
+}
+
+// readPayload reads the body of an upload. Over plain HTTP the client
+// streams it aws-chunked, "size;chunk-signature=...\r\n" before each chunk.
+func readPayload(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data bytes.Buffer
+	reader := bufio.NewReader(r.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		hexSize, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		size, err := strconv.ParseInt(hexSize, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
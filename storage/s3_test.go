@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"bytes"
+	"coffee_shop/config"
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestS3Store(t *testing.T, publicURL string) *S3Store {
+	t.Helper()
+	server := httptest.NewServer(newFakeS3("menu"))
+	t.Cleanup(server.Close)
+
+	store, err := NewS3Store(config.StorageConfig{
+		S3Endpoint:   strings.TrimPrefix(server.URL, "http://"),
+		S3Region:     "us-east-1",
+		S3Bucket:     "menu",
+		S3AccessKey:  "access",
+		S3SecretKey:  "secret",
+		S3PathStyle:  true,
+		S3PublicURL:  publicURL,
+		SignedURLTTL: 15 * time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestS3Store(t *testing.T) {
+	ctx := context.Background()
+	store := newTestS3Store(t, "")
+
+	content := []byte("not really a jpeg")
+	key := ContentKey("menu_img", content, ".jpg")
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if err := store.Put(ctx, "menu_imgs/other.jpg", strings.NewReader("x"), 1, "image/jpeg"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+
+	info, err := store.Stat(ctx, key)
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if info.Size != int64(len(content)) || info.ContentType != "image/jpeg" || !strings.HasPrefix(info.ETag, `"`) {
+		t.Errorf("Stat = %+v", info)
+	}
+
+	body, _, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, %v, want %q", got, err, content)
+	}
+
+	blobs, err := store.List(ctx, "menu_img")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(blobs) != 1 || blobs[0].Key != key {
+		t.Errorf("List(menu_img) = %+v, want only %s", blobs, key)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Stat(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat after Delete = %v, want ErrNotFound", err)
+	}
+	if _, _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestS3StoreURL(t *testing.T) {
+	ctx := context.Background()
+
+	public := newTestS3Store(t, "https://cdn.example.com/")
+	got, err := public.URL(ctx, "menu_img/Cheese Cake.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "https://cdn.example.com/menu_img/Cheese%20Cake.png"; got != want {
+		t.Errorf("public URL = %q, want %q", got, want)
+	}
+
+	private := newTestS3Store(t, "")
+	got, err = private.URL(ctx, "menu_img/a.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := url.Parse(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := signed.Query()
+	if signed.Path != "/menu/menu_img/a.jpg" {
+		t.Errorf("signed URL path = %q, want the path-style key", signed.Path)
+	}
+	if query.Get("X-Amz-Expires") != "900" || query.Get("X-Amz-Signature") == "" {
+		t.Errorf("signed URL query = %v, want a signature expiring in 900s", query)
+	}
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"coffee_shop/config"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist in the store.
+var ErrNotFound = errors.New("blob not found")
+
+// BlobInfo describes a stored object.
+type BlobInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+	ETag         string
+}
+
+// BlobStore is where uploaded files live. Keys are slash separated paths
+// relative to the store root, e.g. "menu_img/3f2a...c1.jpg", and are what
+// the database stores; URL turns a key into something a client can fetch.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, BlobInfo, error)
+	Stat(ctx context.Context, key string) (BlobInfo, error)
+	Delete(ctx context.Context, key string) error
+	// List returns every blob whose key starts with prefix.
+	List(ctx context.Context, prefix string) ([]BlobInfo, error)
+	// URL returns a public URL, or a time-limited signed URL when the
+	// store is private.
+	URL(ctx context.Context, key string) (string, error)
+}
+
+// New returns the store selected by STORAGE_DRIVER.
+func New(cfg config.StorageConfig) (BlobStore, error) {
+	switch cfg.Driver {
+	case config.StorageDriverLocal:
+		return NewLocalStore(cfg.LocalDir, cfg.PublicBaseURL), nil
+	case config.StorageDriverS3:
+		return NewS3Store(cfg)
+	default:
+		return nil, errors.New("unknown storage driver: " + cfg.Driver)
+	}
+}
+
+// ContentKey builds a content-addressed key: the SHA-256 of the bytes
+// under prefix, with the given extension. Identical uploads share a key
+// and a name can never escape the prefix, whatever the client sent.
+func ContentKey(prefix string, content []byte, ext string) string {
+	sum := sha256.Sum256(content)
+	return path.Join(prefix, hex.EncodeToString(sum[:])+ext)
+}
+
+// NormalizeKey turns what is stored in the database into a store key.
+// Rows written before the blob store held paths such as
+// "./image/menu_img/Cheese Cake.png", which map to "menu_img/Cheese Cake.png".
+func NormalizeKey(stored string) string {
+	key := strings.TrimPrefix(stored, "./")
+	key = strings.TrimPrefix(key, "image/")
+	return strings.TrimPrefix(path.Clean("/"+key), "/")
+}
+
+// escapePath percent-encodes a key for use in a URL path, legacy keys may
+// contain spaces.
+func escapePath(p string) string {
+	return (&url.URL{Path: p}).EscapedPath()
+}
+
+// ResolveURL returns the URL for a stored value, or an empty string when
+// there is no image or the URL cannot be built.
+func ResolveURL(ctx context.Context, store BlobStore, stored string) string {
+	if stored == "" {
+		return ""
+	}
+	if strings.HasPrefix(stored, "http://") || strings.HasPrefix(stored, "https://") {
+		return stored
+	}
+	resolved, err := store.URL(ctx, NormalizeKey(stored))
+	if err != nil {
+		return ""
+	}
+	return resolved
+}
+
+// IsContentAddressed reports whether a key was built from a content hash
+// by ContentKey, i.e. its bytes can never change and it may be cached
+// forever.
+func IsContentAddressed(key string) bool {
+	for _, segment := range strings.Split(key, "/") {
+		name := strings.TrimSuffix(segment, path.Ext(segment))
+		if len(name) != sha256.Size*2 {
+			continue
+		}
+		if _, err := hex.DecodeString(name); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestContentKey(t *testing.T) {
+	key := ContentKey("menu_img", []byte("latte"), ".webp")
+	if key != ContentKey("menu_img", []byte("latte"), ".webp") {
+		t.Error("same content must give the same key")
+	}
+	if key == ContentKey("menu_img", []byte("mocha"), ".webp") {
+		t.Error("different content must give different keys")
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(key, "menu_img/"), ".webp")
+	if len(name) != 64 || strings.Contains(name, "/") {
+		t.Errorf("ContentKey = %q, want menu_img/<sha256>.webp", key)
+	}
+	if !IsContentAddressed(key) {
+		t.Errorf("IsContentAddressed(%q) = false", key)
+	}
+}
+
+func TestIsContentAddressed(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"menu_img/" + hash + ".jpg", true},
+		{"menu_img/" + hash + "/thumb.webp", true},
+		{"menu_img/Cheese Cake.png", false},
+		{"menu_img/" + strings.Repeat("zz", 32) + ".jpg", false},
+		{"menu_img/" + hash[:62] + ".jpg", false},
+	}
+	for _, tt := range tests {
+		if got := IsContentAddressed(tt.key); got != tt.want {
+			t.Errorf("IsContentAddressed(%q) = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeKey(t *testing.T) {
+	tests := []struct {
+		stored string
+		want   string
+	}{
+		{"./image/menu_img/Cheese Cake.png", "menu_img/Cheese Cake.png"},
+		{"image/menu_img/a.jpg", "menu_img/a.jpg"},
+		{"menu_img/a.jpg", "menu_img/a.jpg"},
+		{"../../etc/passwd", "etc/passwd"},
+		{"menu_img/../../secret", "secret"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeKey(tt.stored); got != tt.want {
+			t.Errorf("NormalizeKey(%q) = %q, want %q", tt.stored, got, tt.want)
+		}
+	}
+}
+
+func TestResolveURL(t *testing.T) {
+	store := NewLocalStore(t.TempDir(), "http://localhost:8080/image/")
+	tests := []struct {
+		stored string
+		want   string
+	}{
+		{"", ""},
+		{"https://cdn.example.com/a.jpg", "https://cdn.example.com/a.jpg"},
+		{"./image/menu_img/Cheese Cake.png", "http://localhost:8080/image/menu_img/Cheese%20Cake.png"},
+		{"menu_img/a.jpg", "http://localhost:8080/image/menu_img/a.jpg"},
+	}
+	for _, tt := range tests {
+		if got := ResolveURL(context.Background(), store, tt.stored); got != tt.want {
+			t.Errorf("ResolveURL(%q) = %q, want %q", tt.stored, got, tt.want)
+		}
+	}
+}
@@ -1,7 +1,7 @@
 package utils
 
 import (
-	"os"
+	"coffee_shop/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,18 +19,16 @@ func (j *JwtCustomClaims) Valid() error {
 	panic("unimplemented")
 }
 
-var secretKey = []byte(os.Getenv("JWT_SECRET_KEY"))
-
-func GenerateJWT(id uint, email string, role string) (string, error) {
+func GenerateJWT(cfg config.JWTConfig, id uint, email string, role string) (string, error) {
 	// Set custom claims
 	customClaims := &JwtCustomClaims{
 		ID:    id,
 		Email: email,
 		Role:  role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			Issuer:    "coffee_shop_app",
+			Issuer:    cfg.Issuer,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.AccessTTL)),
 		},
 	}
 
@@ -38,14 +36,14 @@ func GenerateJWT(id uint, email string, role string) (string, error) {
 	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, customClaims)
 
 	// Generate encoded token and send it as response.
-	token, err := jwtToken.SignedString(secretKey)
+	token, err := jwtToken.SignedString([]byte(cfg.SecretKey))
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
-func GenerateRefresherJWT(id uint, email string, role string) (string, error) {
+func GenerateRefresherJWT(cfg config.JWTConfig, id uint, email string, role string) (string, error) {
 	// Set custom claims
 	customClaims := &JwtCustomClaims{
 		ID:    id,
@@ -53,8 +51,8 @@ func GenerateRefresherJWT(id uint, email string, role string) (string, error) {
 		Role:  role,
 		RegisteredClaims: jwt.RegisteredClaims{
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
-			Issuer:    "coffee_shop_app",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.RefreshTTL)),
+			Issuer:    cfg.Issuer,
 		},
 	}
 
@@ -62,15 +60,15 @@ func GenerateRefresherJWT(id uint, email string, role string) (string, error) {
 	refresherJwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, customClaims)
 
 	// Generate encoded token and send it as response.
-	token, err := refresherJwtToken.SignedString(secretKey)
+	token, err := refresherJwtToken.SignedString([]byte(cfg.SecretKey))
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
 
-func GetSecretKey() []byte {
-	return secretKey
+func GetSecretKey(cfg config.JWTConfig) []byte {
+	return []byte(cfg.SecretKey)
 }
 
 // func ValidateToken(signedToken string) (*JwtCustomClaims, error) {